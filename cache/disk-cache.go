@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/filelock"
+)
+
+const (
+	diskCacheVersion        = 1
+	diskCacheSuffix         = ".cache"
+	defaultDiskCacheSizeMB  = 256
+	diskCacheFilePermission = 0o600
+)
+
+// RevalidatingCache is implemented by caches that keep expired entries so they
+// can be revalidated with a conditional request instead of being refetched.
+type RevalidatingCache interface {
+	Cache
+	// GetStale returns the entry stored under key whether or not it has expired.
+	GetStale(key string) *http.Response
+}
+
+// diskEntry is the on-disk layout of a cached response.
+type diskEntry struct {
+	Version   int       `json:"version"`
+	Key       string    `json:"key"`
+	StoredAt  time.Time `json:"stored_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Response  []byte    `json:"response"`
+}
+
+// sealedEntry is the on-disk layout of an encrypted diskEntry.
+type sealedEntry struct {
+	Version int    `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type diskCache struct {
+	mu      sync.Mutex
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	key     []byte
+	aead    cipher.AEAD
+}
+
+// DiskCacheOption configures a disk cache.
+type DiskCacheOption func(*diskCache)
+
+// WithEncryptionKey encrypts the entries at rest with AES-256-GCM using a key
+// derived from key. Entries that cannot be decrypted with it are misses, so
+// processes using different keys never read each other's responses.
+func WithEncryptionKey(key []byte) DiskCacheOption {
+	return func(c *diskCache) {
+		c.key = key
+	}
+}
+
+// NewDiskCache returns a cache that persists responses as files in dir, so they
+// survive across processes. Entries are fresh for ttl and are kept afterwards
+// for revalidation until the total size exceeds maxCacheSizeMB (256 when zero),
+// at which point the least recently used entries are removed.
+func NewDiskCache(dir string, ttl time.Duration, maxCacheSizeMB int, opts ...DiskCacheOption) (RevalidatingCache, error) {
+	if dir == "" {
+		return nil, errors.New("cache directory is required")
+	}
+	if maxCacheSizeMB <= 0 {
+		maxCacheSizeMB = defaultDiskCacheSizeMB
+	}
+	c := &diskCache{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: int64(maxCacheSizeMB) << 20,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if len(c.key) > 0 {
+		sum := sha256.Sum256(c.key)
+		block, err := aes.NewCipher(sum[:])
+		if err != nil {
+			return nil, err
+		}
+		if c.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *diskCache) Get(key string) *http.Response {
+	entry := c.read(c.path(key))
+	if entry == nil || entry.Key != key || !time.Now().Before(entry.ExpiresAt) {
+		return nil
+	}
+	return c.response(key, entry)
+}
+
+func (c *diskCache) GetStale(key string) *http.Response {
+	entry := c.read(c.path(key))
+	if entry == nil || entry.Key != key {
+		return nil
+	}
+	return c.response(key, entry)
+}
+
+func (c *diskCache) Set(key string, value *http.Response) {
+	dump, err := httputil.DumpResponse(value, true)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	path := c.path(key)
+	data, err := c.encode(path, diskEntry{
+		Version:   diskCacheVersion,
+		Key:       key,
+		StoredAt:  now,
+		ExpiresAt: now.Add(c.ttl),
+		Response:  dump,
+	})
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := filelock.WriteFileAtomic(path, data, diskCacheFilePermission); err != nil {
+		return
+	}
+	c.evict()
+}
+
+func (c *diskCache) Delete(key string) {
+	_ = os.Remove(c.path(key))
+}
+
+func (c *diskCache) Clear() {
+	for _, path := range c.files() {
+		_ = os.Remove(path)
+	}
+}
+
+func (c *diskCache) ClearAllKeysWithPrefix(prefix string) {
+	for _, path := range c.files() {
+		if entry := c.read(path); entry != nil && strings.HasPrefix(entry.Key, prefix) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+func (c *diskCache) Close() {}
+
+// response decodes a stored response and marks the entry as recently used.
+func (c *diskCache) response(key string, entry *diskEntry) *http.Response {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.Response)), nil)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+	return resp
+}
+
+// encode serializes entry, sealing it when the cache is encrypted. The file
+// name is authenticated with it, so an entry cannot be moved to another key.
+func (c *diskCache) encode(path string, entry diskEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil || c.aead == nil {
+		return data, err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealedEntry{
+		Version: diskCacheVersion,
+		Nonce:   nonce,
+		Data:    c.aead.Seal(nil, nonce, data, []byte(filepath.Base(path))),
+	})
+}
+
+func (c *diskCache) read(path string) *diskEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	if c.aead != nil {
+		var sealed sealedEntry
+		if json.Unmarshal(data, &sealed) != nil || sealed.Version != diskCacheVersion || len(sealed.Nonce) != c.aead.NonceSize() {
+			return nil
+		}
+		if data, err = c.aead.Open(nil, sealed.Nonce, sealed.Data, []byte(filepath.Base(path))); err != nil {
+			return nil
+		}
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != diskCacheVersion {
+		return nil
+	}
+	return &entry
+}
+
+func (c *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+diskCacheSuffix)
+}
+
+func (c *diskCache) files() []string {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*"+diskCacheSuffix))
+	return paths
+}
+
+// evict removes the least recently used entries until the cache fits its size bound.
+func (c *diskCache) evict() {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, path := range c.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.maxSize {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= c.maxSize {
+			return
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
+
+// SetConditionalHeaders adds If-None-Match and If-Modified-Since headers built
+// from the validators of a stale cached response, and reports whether it had any.
+func SetConditionalHeaders(req *http.Request, stale *http.Response) bool {
+	if stale == nil {
+		return false
+	}
+	etag := stale.Header.Get("ETag")
+	lastModified := stale.Header.Get("Last-Modified")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return etag != "" || lastModified != ""
+}
+
+// Revalidated returns the stale response refreshed with the headers of a 304
+// Not Modified response, ready to be served and stored again.
+func Revalidated(stale, notModified *http.Response) *http.Response {
+	resp := *stale
+	resp.Header = stale.Header.Clone()
+	for k, v := range notModified.Header {
+		// Content-Length and friends describe the empty 304 body.
+		if k == "Content-Length" || k == "Transfer-Encoding" {
+			continue
+		}
+		resp.Header[k] = v
+	}
+	resp.Request = notModified.Request
+	return &resp
+}
@@ -0,0 +1,27 @@
+// Package pageiter turns the page readers of the product services into
+// iterators over the items of each page.
+package pageiter
+
+import "iter"
+
+// Items returns an iterator over the items of the pages readPages passes to
+// its yield function. Pages are read as the iteration reaches them, so
+// breaking out of the loop stops the requests. An error returned by
+// readPages, including a cancelled context, is yielded once and ends the
+// iteration.
+func Items[T any](readPages func(yield func([]T) bool) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := readPages(func(items []T) bool {
+			for _, item := range items {
+				if !yield(item, nil) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package prefetch fetches numbered items, such as the pages of a list, with
+// bounded concurrency while handing them to the caller in order.
+package prefetch
+
+import (
+	"context"
+	"sync"
+)
+
+// Ordered calls fetch for indexes first..last with up to concurrency calls in
+// flight and passes the results to yield in index order, stopping early when
+// yield returns false. At most concurrency results are fetched ahead of the
+// one being yielded. The first error cancels the context of the other calls
+// and is returned. Ordered does not return before every fetch has.
+func Ordered[T any](ctx context.Context, first, last, concurrency int, fetch func(ctx context.Context, i int) (T, error), yield func(T) bool) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	type result struct {
+		value T
+		err   error
+	}
+	results := make([]chan result, last-first+1)
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	var failOnce sync.Once
+	var failure error
+	fail := func(err error) {
+		failOnce.Do(func() {
+			failure = err
+			cancel()
+		})
+	}
+
+	slots := make(chan struct{}, concurrency)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := first; i <= last; i++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				value, err := fetch(ctx, i)
+				if err != nil {
+					fail(err)
+				}
+				results[i-first] <- result{value: value, err: err}
+			}(i)
+		}
+	}()
+
+	for i := first; i <= last; i++ {
+		var r result
+		select {
+		case r = <-results[i-first]:
+		case <-ctx.Done():
+			// Either a call failed or the caller's context ended first.
+			fail(ctx.Err())
+			return failure
+		}
+		<-slots
+		if r.err != nil {
+			fail(r.err)
+			return failure
+		}
+		if !yield(r.value) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"os"
@@ -25,6 +27,7 @@ func NewNopLogger() Logger {
 type defaultLogger struct {
 	logger  *log.Logger
 	Verbose bool
+	policy  *RedactionPolicy
 }
 
 func (l *defaultLogger) Printf(format string, v ...interface{}) {
@@ -33,19 +36,68 @@ func (l *defaultLogger) Printf(format string, v ...interface{}) {
 		return
 	}
 
-	l.logger.Printf(format, v...)
+	_ = l.logger.Output(2, l.policy.Redact(fmt.Sprintf(format, v...)))
 }
 
+// SetRedactionPolicy replaces the redaction policy. A nil policy disables redaction.
+func (l *defaultLogger) SetRedactionPolicy(policy *RedactionPolicy) {
+	l.policy = policy
+}
+
+// RedactionPolicy returns the policy applied by the logger.
+func (l *defaultLogger) RedactionPolicy() *RedactionPolicy {
+	return l.policy
+}
+
+// GetDefaultLogger returns the logger selected by the environment. Logging is off
+// unless ZSCALER_SDK_LOG is true; ZSCALER_SDK_VERBOSE enables debug output and
+// ZSCALER_SDK_LOG_FORMAT set to "json" or "text" switches to structured slog output.
 func GetDefaultLogger(loggerPrefix string) Logger {
 	loggingEnabled, _ := strconv.ParseBool(os.Getenv("ZSCALER_SDK_LOG"))
 	if !loggingEnabled {
 		return &nopLogger{}
 	}
 	verbose, _ := strconv.ParseBool(os.Getenv("ZSCALER_SDK_VERBOSE"))
+
+	level := slog.LevelInfo
+	if verbose {
+		level = LevelTrace
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(os.Getenv("ZSCALER_SDK_LOG_FORMAT")) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	if handler != nil {
+		name := strings.TrimSuffix(strings.TrimSpace(loggerPrefix), ":")
+		return NewSlogLogger(handler.WithAttrs([]slog.Attr{slog.String("logger", name)}), nil)
+	}
+
 	return &defaultLogger{
 		logger:  log.New(os.Stdout, loggerPrefix, log.LstdFlags|log.Lshortfile),
 		Verbose: verbose,
+		policy:  DefaultRedactionPolicy(),
+	}
+}
+
+// redactionPolicyFor returns the policy of a logger that carries one, or the default policy.
+func redactionPolicyFor(logger Logger) *RedactionPolicy {
+	if r, ok := logger.(interface{ RedactionPolicy() *RedactionPolicy }); ok {
+		return r.RedactionPolicy()
 	}
+	return sharedDefaultPolicy
+}
+
+// sharedDefaultPolicy redacts output written to loggers that do not carry a policy.
+var sharedDefaultPolicy = DefaultRedactionPolicy()
+
+// IsNop reports whether logger discards every message.
+func IsNop(logger Logger) bool {
+	_, ok := logger.(*nopLogger)
+	return logger == nil || ok
 }
 
 const (
@@ -67,19 +119,20 @@ func WriteLog(logger Logger, format string, args ...interface{}) {
 }
 
 func LogRequestSensitive(logger Logger, req *http.Request, reqID string, sensitiveContent []string) {
-	if logger != nil && req != nil {
+	if !IsNop(logger) && req != nil {
 		out, err := httputil.DumpRequestOut(req, true)
 		for _, s := range sensitiveContent {
-			out = []byte(strings.ReplaceAll(string(out), s, "********"))
+			out = []byte(strings.ReplaceAll(string(out), s, RedactedValue))
 		}
 		if err == nil {
-			WriteLog(logger, logReqMsg, req.Method, req.URL, reqID, string(out))
+			policy := redactionPolicyFor(logger)
+			WriteLog(logger, logReqMsg, req.Method, policy.Redact(req.URL.String()), reqID, policy.Redact(string(out)))
 		}
 	}
 }
 
 func LogRequest(logger Logger, req *http.Request, reqID string, otherHeaderParams map[string]string, body bool) {
-	if logger != nil && req != nil {
+	if !IsNop(logger) && req != nil {
 		l, ok := logger.(*defaultLogger)
 		if ok && l.Verbose {
 			for k, v := range otherHeaderParams {
@@ -88,19 +141,22 @@ func LogRequest(logger Logger, req *http.Request, reqID string, otherHeaderParam
 		}
 		out, err := httputil.DumpRequestOut(req, body)
 		if err == nil {
-			WriteLog(logger, logReqMsg, req.Method, req.URL, reqID, string(out))
+			policy := redactionPolicyFor(logger)
+			WriteLog(logger, logReqMsg, req.Method, policy.Redact(req.URL.String()), reqID, policy.Redact(string(out)))
 		}
 	}
 }
 
 func LogResponse(logger Logger, resp *http.Response, start time.Time, reqID string) {
-	if logger != nil && resp != nil {
+	if !IsNop(logger) && resp != nil {
 		// Dump the entire response
 		out, err := httputil.DumpResponse(resp, true)
+		policy := redactionPolicyFor(logger)
+		reqURL := policy.Redact(resp.Request.URL.String())
 		if err == nil {
-			WriteLog(logger, logRespMsg, resp.Request.Method, resp.Request.URL, reqID, time.Since(start).String(), string(out))
+			WriteLog(logger, logRespMsg, resp.Request.Method, reqURL, reqID, time.Since(start).String(), policy.Redact(string(out)))
 		} else {
-			WriteLog(logger, logRespMsg, resp.Request.Method, resp.Request.URL, reqID, time.Since(start).String(), "Got error:"+err.Error())
+			WriteLog(logger, logRespMsg, resp.Request.Method, reqURL, reqID, time.Since(start).String(), "Got error:"+err.Error())
 		}
 	}
 }
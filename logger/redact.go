@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces sensitive values in log output.
+const RedactedValue = "********"
+
+// RedactionPolicy describes which values are masked before a log line is written.
+// A policy must not be modified once it has been used.
+type RedactionPolicy struct {
+	// Headers are HTTP header names whose values are masked in request and response dumps.
+	Headers []string
+	// Fields are JSON keys and form or query parameter names whose values are masked.
+	Fields []string
+	// FieldSuffixes mask every JSON key or parameter whose last word is one of the
+	// suffixes. A word starts the name or follows an underscore, a hyphen or a
+	// lower to upper case change, so "Pass" covers "uninstallPass" and
+	// "exit_pass" but not "bypass" or "compass".
+	FieldSuffixes []string
+	// Values are literal secrets masked wherever they appear.
+	Values []string
+	// Replacement is written instead of a masked value. Defaults to RedactedValue.
+	Replacement string
+
+	once     sync.Once
+	patterns []redactPattern
+}
+
+type redactPattern struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// DefaultRedactionPolicy masks credentials, tokens, cookies and the ZCC
+// password and OTP fields. Tokens are listed by name, so that fields such as
+// "nextPageToken" stay readable.
+func DefaultRedactionPolicy() *RedactionPolicy {
+	return &RedactionPolicy{
+		Headers: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			"X-Api-Key",
+			"Auth-Token",
+		},
+		Fields: []string{
+			"client_secret",
+			"client_assertion",
+			"apiKey",
+			"api_key",
+			"privateKey",
+			"passphrase",
+			"token",
+			"access_token",
+			"refresh_token",
+			"id_token",
+			"api_token",
+			"accessToken",
+			"refreshToken",
+			"authToken",
+			"apiToken",
+			"scimToken",
+			"sandboxToken",
+			"machineToken",
+		},
+		FieldSuffixes: []string{"Secret", "Password", "Pass", "Otp"},
+	}
+}
+
+// Redact returns s with every sensitive value masked. A nil policy returns s unchanged.
+func (p *RedactionPolicy) Redact(s string) string {
+	if p == nil || s == "" {
+		return s
+	}
+	p.once.Do(p.compile)
+	for _, v := range p.Values {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, p.replacement())
+		}
+	}
+	for _, pat := range p.patterns {
+		s = pat.re.ReplaceAllString(s, pat.repl)
+	}
+	return s
+}
+
+// IsSensitiveField reports whether values stored under key are masked by the policy.
+func (p *RedactionPolicy) IsSensitiveField(key string) bool {
+	if p == nil {
+		return false
+	}
+	for _, f := range p.Fields {
+		if strings.EqualFold(f, key) {
+			return true
+		}
+	}
+	for _, suffix := range p.FieldSuffixes {
+		if endsWithWord(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsWithWord reports whether the last word of key is suffix, ignoring case.
+func endsWithWord(key, suffix string) bool {
+	if suffix == "" || len(key) < len(suffix) || !strings.EqualFold(key[len(key)-len(suffix):], suffix) {
+		return false
+	}
+	i := len(key) - len(suffix)
+	if i == 0 || key[i-1] == '_' || key[i-1] == '-' {
+		return true
+	}
+	prev, first := key[i-1], key[i]
+	return (prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9') && first >= 'A' && first <= 'Z'
+}
+
+func (p *RedactionPolicy) replacement() string {
+	if p.Replacement != "" {
+		return p.Replacement
+	}
+	return RedactedValue
+}
+
+func (p *RedactionPolicy) compile() {
+	repl := strings.ReplaceAll(p.replacement(), "$", "$$")
+
+	if headers := alternation(p.Headers); headers != "" {
+		p.patterns = append(p.patterns, redactPattern{
+			re:   regexp.MustCompile(`(?im)^(` + headers + `):[ \t]*[^\r\n]*`),
+			repl: "${1}: " + repl,
+		})
+	}
+
+	var keys []string
+	if fields := alternation(p.Fields); fields != "" {
+		keys = append(keys, fields)
+	}
+	if suffixes := wordSuffixes(p.FieldSuffixes); suffixes != "" {
+		keys = append(keys, suffixes)
+	}
+	if len(keys) > 0 {
+		key := `(?:` + strings.Join(keys, "|") + `)`
+		p.patterns = append(p.patterns,
+			// JSON string values: "key": "value"
+			redactPattern{
+				re:   regexp.MustCompile(`(?i)"(` + key + `)"(\s*:\s*)"(?:[^"\\]|\\.)*"`),
+				repl: `"${1}"${2}"` + repl + `"`,
+			},
+			// JSON numeric values: "key": 123456
+			redactPattern{
+				re:   regexp.MustCompile(`(?i)"(` + key + `)"(\s*:\s*)-?[0-9][0-9.eE+\-]*`),
+				repl: `"${1}"${2}"` + repl + `"`,
+			},
+			// Form and query parameters: key=value
+			redactPattern{
+				re:   regexp.MustCompile(`(?i)(^|[?&\s])(` + key + `)=[^&\s"]*`),
+				repl: "${1}${2}=" + repl,
+			},
+		)
+	}
+
+	// Bearer tokens outside of headers, e.g. in debug messages.
+	p.patterns = append(p.patterns, redactPattern{
+		re:   regexp.MustCompile(`(?i)\b(Bearer)\s+[A-Za-z0-9\-._~+/]+=*`),
+		repl: "${1} " + repl,
+	})
+}
+
+// wordSuffixes matches the names whose last word is one of suffixes, as
+// endsWithWord does.
+func wordSuffixes(suffixes []string) string {
+	var alts []string
+	for _, s := range suffixes {
+		if s == "" {
+			continue
+		}
+		quoted := regexp.QuoteMeta(s)
+		alts = append(alts, quoted, `[A-Za-z0-9_\-]*[_\-]`+quoted)
+		if first := s[:1]; strings.ToLower(first) != strings.ToUpper(first) {
+			alts = append(alts, `[A-Za-z0-9_\-]*(?-i:[a-z0-9]`+strings.ToUpper(first)+`)`+regexp.QuoteMeta(s[1:]))
+		}
+	}
+	return strings.Join(alts, "|")
+}
+
+func alternation(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != "" {
+			quoted = append(quoted, regexp.QuoteMeta(n))
+		}
+	}
+	return strings.Join(quoted, "|")
+}
+
+// redactingLogger masks sensitive values before handing messages to another logger.
+type redactingLogger struct {
+	next   Logger
+	policy *RedactionPolicy
+}
+
+// NewRedactingLogger wraps l so that every message is redacted with policy first.
+func NewRedactingLogger(l Logger, policy *RedactionPolicy) Logger {
+	if IsNop(l) {
+		return l
+	}
+	return &redactingLogger{next: l, policy: policy}
+}
+
+func (l *redactingLogger) Printf(format string, v ...interface{}) {
+	// Keep the message as the format so level prefixes stay visible to the wrapped logger.
+	msg := l.policy.Redact(fmt.Sprintf(format, v...))
+	l.next.Printf(strings.ReplaceAll(msg, "%", "%%"))
+}
+
+// LogAttrs forwards structured records when the wrapped logger supports them.
+func (l *redactingLogger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	sl, ok := l.next.(StructuredLogger)
+	if !ok {
+		return
+	}
+	for i := range attrs {
+		attrs[i] = redactAttr(l.policy, attrs[i])
+	}
+	sl.LogAttrs(ctx, level, l.policy.Redact(msg), attrs...)
+}
+
+// RedactionPolicy returns the policy applied by the logger.
+func (l *redactingLogger) RedactionPolicy() *RedactionPolicy {
+	return l.policy
+}
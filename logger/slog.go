@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LevelTrace is the slog level used for "[TRACE]" messages.
+const LevelTrace = slog.LevelDebug - 4
+
+// StructuredLogger is a Logger that can also emit leveled records with attributes.
+type StructuredLogger interface {
+	Logger
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// RedactionSetter is implemented by loggers that apply a RedactionPolicy themselves.
+type RedactionSetter interface {
+	SetRedactionPolicy(policy *RedactionPolicy)
+}
+
+// SlogLogger writes SDK log output to a slog.Handler. Printf messages are
+// leveled by their "[TRACE]", "[DEBUG]", "[INFO]", "[WARN]" or "[ERROR]"
+// prefix, and every message and attribute is redacted before it is handled.
+type SlogLogger struct {
+	logger *slog.Logger
+	policy *RedactionPolicy
+}
+
+// NewSlogLogger returns a logger backed by handler. A nil policy selects DefaultRedactionPolicy.
+func NewSlogLogger(handler slog.Handler, policy *RedactionPolicy) *SlogLogger {
+	if policy == nil {
+		policy = DefaultRedactionPolicy()
+	}
+	return &SlogLogger{logger: slog.New(handler), policy: policy}
+}
+
+// Printf logs a formatted message at the level given by its prefix.
+func (l *SlogLogger) Printf(format string, v ...interface{}) {
+	level, msg := parseLevel(fmt.Sprintf(format, v...))
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.LogAttrs(ctx, level, l.policy.Redact(msg))
+}
+
+// LogAttrs logs a structured record, masking sensitive attributes.
+func (l *SlogLogger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	for i := range attrs {
+		attrs[i] = redactAttr(l.policy, attrs[i])
+	}
+	l.logger.LogAttrs(ctx, level, l.policy.Redact(msg), attrs...)
+}
+
+// SetRedactionPolicy replaces the redaction policy. A nil policy disables redaction.
+func (l *SlogLogger) SetRedactionPolicy(policy *RedactionPolicy) {
+	l.policy = policy
+}
+
+// RedactionPolicy returns the policy applied by the logger.
+func (l *SlogLogger) RedactionPolicy() *RedactionPolicy {
+	return l.policy
+}
+
+func redactAttr(p *RedactionPolicy, a slog.Attr) slog.Attr {
+	if p == nil {
+		return a
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		out := make([]slog.Attr, len(group))
+		for i, g := range group {
+			out[i] = redactAttr(p, g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}
+	case slog.KindString:
+		if p.IsSensitiveField(a.Key) {
+			return slog.String(a.Key, p.replacement())
+		}
+		return slog.String(a.Key, p.Redact(a.Value.String()))
+	}
+	if p.IsSensitiveField(a.Key) {
+		return slog.String(a.Key, p.replacement())
+	}
+	return a
+}
+
+// parseLevel maps a "[LEVEL]" message prefix to a slog level and strips it.
+func parseLevel(msg string) (slog.Level, string) {
+	trimmed := strings.TrimSpace(msg)
+	prefixes := []struct {
+		prefix string
+		level  slog.Level
+	}{
+		{"[TRACE]", LevelTrace},
+		{"[DEBUG]", slog.LevelDebug},
+		{"[INFO]", slog.LevelInfo},
+		{"[WARN]", slog.LevelWarn},
+		{"[ERROR]", slog.LevelError},
+		{"[ERR]", slog.LevelError},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(trimmed, p.prefix) {
+			return p.level, strings.TrimSpace(strings.TrimPrefix(trimmed, p.prefix))
+		}
+	}
+	return slog.LevelInfo, trimmed
+}
+
+// Attempt describes a single HTTP round trip made by a client.
+type Attempt struct {
+	RequestID string
+	Product   string
+	Method    string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	Retry     int
+	Err       error
+}
+
+// LogAttempt emits a structured "http request" record when l is a StructuredLogger.
+// Failed and server error attempts are logged at error level, client errors at
+// warn level and everything else at debug level.
+func LogAttempt(ctx context.Context, l Logger, a Attempt) {
+	sl, ok := l.(StructuredLogger)
+	if !ok {
+		return
+	}
+	level := slog.LevelDebug
+	switch {
+	case a.Err != nil || a.Status >= 500:
+		level = slog.LevelError
+	case a.Status >= 400:
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("request_id", a.RequestID),
+		slog.String("product", a.Product),
+		slog.String("method", a.Method),
+		slog.String("path", a.Path),
+		slog.Int("status", a.Status),
+		slog.Duration("duration", a.Duration),
+		slog.Int("retry", a.Retry),
+	}
+	if a.Err != nil {
+		attrs = append(attrs, slog.String("error", a.Err.Error()))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sl.LogAttrs(ctx, level, "http request", attrs...)
+}
@@ -0,0 +1,250 @@
+package ratelimiter
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter is a client-side rate limiting strategy used by RateLimitTransport.
+type Limiter interface {
+	// Wait reports whether a request with the given method has to wait, and for how long.
+	Wait(method string) (bool, time.Duration)
+	// Observe updates the limiter from the response to a request with the given method.
+	Observe(method string, resp *http.Response)
+}
+
+// Observe implements Limiter. The fixed-window limiter does not learn from responses.
+func (rl *RateLimiter) Observe(method string, resp *http.Response) {}
+
+// MethodClass groups the HTTP methods that share a server-side budget.
+type MethodClass string
+
+const (
+	MethodClassRead   MethodClass = "read"   // GET and HEAD
+	MethodClassWrite  MethodClass = "write"  // POST, PUT and PATCH
+	MethodClassDelete MethodClass = "delete" // DELETE
+)
+
+// ClassOf returns the method class of an HTTP method.
+func ClassOf(method string) MethodClass {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, "":
+		return MethodClassRead
+	case http.MethodDelete:
+		return MethodClassDelete
+	default:
+		return MethodClassWrite
+	}
+}
+
+// State is a snapshot of the budget an AdaptiveRateLimiter holds for a method class.
+type State struct {
+	Class MethodClass
+	// Adaptive is true while the budget comes from server headers; otherwise the
+	// fallback limiter is in charge and the remaining fields are zero.
+	Adaptive bool
+	// Limit is the last X-RateLimit-Limit reported by the server, or 0 if unknown.
+	Limit int
+	// Remaining is the number of requests left until ResetAt, counting requests
+	// sent since the last response.
+	Remaining int
+	// ResetAt is when the server restores the budget.
+	ResetAt time.Time
+	// BlockedUntil is set after a Retry-After response; no request is sent before it.
+	BlockedUntil time.Time
+}
+
+// StateReporter is implemented by limiters that expose their current budget.
+type StateReporter interface {
+	State() []State
+}
+
+// adaptiveBucket is the server-reported budget of one method class.
+type adaptiveBucket struct {
+	observed     bool
+	limit        int
+	remaining    int
+	resetAt      time.Time
+	blockedUntil time.Time
+}
+
+// AdaptiveRateLimiter follows the budget the API reports in X-RateLimit-Limit,
+// X-RateLimit-Remaining, X-RateLimit-Reset (or their RateLimit-* equivalents)
+// and Retry-After, tracked per method class. Until a class has seen those
+// headers, and whenever its reported budget is stale, requests are paced by
+// the fallback limiter.
+type AdaptiveRateLimiter struct {
+	mu       sync.Mutex
+	fallback Limiter
+	reserve  int
+	buckets  map[MethodClass]*adaptiveBucket
+	now      func() time.Time
+}
+
+// AdaptiveOption configures an AdaptiveRateLimiter.
+type AdaptiveOption func(*AdaptiveRateLimiter)
+
+// WithReserve keeps n requests of every server budget unused, leaving headroom
+// for other clients of the same tenant. The default is 1.
+func WithReserve(n int) AdaptiveOption {
+	return func(a *AdaptiveRateLimiter) {
+		if n >= 0 {
+			a.reserve = n
+		}
+	}
+}
+
+// NewAdaptiveRateLimiter returns an adaptive limiter that falls back to fallback
+// while the server budget is unknown. A nil fallback never waits.
+func NewAdaptiveRateLimiter(fallback Limiter, opts ...AdaptiveOption) *AdaptiveRateLimiter {
+	a := &AdaptiveRateLimiter{
+		fallback: fallback,
+		reserve:  1,
+		buckets:  make(map[MethodClass]*adaptiveBucket),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Wait implements Limiter.
+func (a *AdaptiveRateLimiter) Wait(method string) (bool, time.Duration) {
+	a.mu.Lock()
+	now := a.now()
+	b := a.bucket(ClassOf(method))
+
+	if b.blockedUntil.After(now) {
+		a.mu.Unlock()
+		return true, b.blockedUntil.Sub(now)
+	}
+	if !b.observed || !b.resetAt.After(now) {
+		// No budget reported yet, or the reported window is over and the next
+		// response will tell us the new one.
+		b.observed = false
+		a.mu.Unlock()
+		if a.fallback == nil {
+			return false, 0
+		}
+		return a.fallback.Wait(method)
+	}
+	if b.remaining <= a.reserve {
+		d := b.resetAt.Sub(now)
+		a.mu.Unlock()
+		return true, d
+	}
+	b.remaining--
+	a.mu.Unlock()
+	return false, 0
+}
+
+// Observe implements Limiter.
+func (a *AdaptiveRateLimiter) Observe(method string, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	b := a.bucket(ClassOf(method))
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok &&
+		(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		b.blockedUntil = now.Add(retryAfter)
+	}
+
+	remaining, okRemaining := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	reset, okReset := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset")
+	if !okRemaining || !okReset {
+		if resp.StatusCode == http.StatusTooManyRequests && b.observed {
+			// The server disagrees with our count; spend nothing until the reset.
+			b.remaining = 0
+		}
+		return
+	}
+	if limit, ok := headerInt(resp.Header, "X-RateLimit-Limit", "RateLimit-Limit"); ok {
+		b.limit = limit
+	}
+	b.observed = true
+	b.remaining = remaining
+	b.resetAt = resetTime(reset, now)
+}
+
+// State implements StateReporter. States are sorted by method class.
+func (a *AdaptiveRateLimiter) State() []State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	states := make([]State, 0, len(a.buckets))
+	for class, b := range a.buckets {
+		s := State{Class: class}
+		if b.observed && b.resetAt.After(now) {
+			s.Adaptive = true
+			s.Limit = b.limit
+			s.Remaining = b.remaining
+			s.ResetAt = b.resetAt
+		}
+		if b.blockedUntil.After(now) {
+			s.BlockedUntil = b.blockedUntil
+		}
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Class < states[j].Class })
+	return states
+}
+
+func (a *AdaptiveRateLimiter) bucket(class MethodClass) *adaptiveBucket {
+	b, ok := a.buckets[class]
+	if !ok {
+		b = &adaptiveBucket{}
+		a.buckets[class] = b
+	}
+	return b
+}
+
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		v := strings.TrimSpace(h.Get(name))
+		if v == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// resetTime interprets a reset header as seconds from now, or as a Unix
+// timestamp when it is too large to be a delay.
+func resetTime(reset int, now time.Time) time.Time {
+	if reset > 1_000_000_000 {
+		return time.Unix(int64(reset), 0)
+	}
+	return now.Add(time.Duration(reset) * time.Second)
+}
+
+// parseRetryAfter accepts delay-seconds, an HTTP date or a Go duration string.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now), true
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return d, true
+	}
+	return 0, false
+}
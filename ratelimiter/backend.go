@@ -0,0 +1,196 @@
+package ratelimiter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/filelock"
+)
+
+// fileBackend keeps each budget in its own file under a directory and
+// serializes processes on the same host with an advisory lock per budget.
+type fileBackend struct {
+	dir string
+}
+
+// NewFileBackend returns a Backend that stores budgets in dir. Every process
+// on the host that points at the same directory shares the budgets.
+func NewFileBackend(dir string) (Backend, error) {
+	if dir == "" {
+		return nil, errors.New("rate limit directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileBackend{dir: dir}, nil
+}
+
+func (b *fileBackend) Earliest(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	// Budget files are replaced atomically, so they can be read without the lock.
+	data, err := os.ReadFile(b.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return time.Time{}, err
+	}
+	_, slot := decodeWindowLog(data).next(notBefore, limit, period)
+	return time.Unix(0, slot), nil
+}
+
+func (b *fileBackend) Reserve(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error) {
+	var slot time.Time
+	err := b.update(ctx, key, func(log windowLog) windowLog {
+		log, slot = log.reserve(notBefore, limit, period)
+		return log
+	})
+	return slot, err
+}
+
+func (b *fileBackend) Release(ctx context.Context, key string, slot time.Time) error {
+	return b.update(ctx, key, func(log windowLog) windowLog {
+		return log.release(slot)
+	})
+}
+
+// update applies fn to the window log of key while holding its lock.
+func (b *fileBackend) update(ctx context.Context, key string, fn func(windowLog) windowLog) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := b.path(key)
+	lock, err := filelock.Acquire(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock rate limit budget: %w", err)
+	}
+	defer lock.Release()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	out, err := json.Marshal(fn(decodeWindowLog(data)))
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFileAtomic(path, out, 0o600)
+}
+
+// path maps a budget key to a file name that is safe on every platform.
+func (b *fileBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(b.dir, "ratelimit-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// KV is a minimal key-value store with compare-and-swap, enough to share
+// budgets through an external store such as Redis, etcd or a database.
+type KV interface {
+	// Get returns the value stored under key, or nil if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// CompareAndSwap stores value under key if the current value equals old
+	// (nil meaning absent) and reports whether it did.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error)
+}
+
+// maxCASAttempts bounds the optimistic retries of a KV reservation under contention.
+const maxCASAttempts = 50
+
+// kvBackend implements Backend on top of a KV store with optimistic concurrency.
+type kvBackend struct {
+	kv KV
+}
+
+// NewKVBackend returns a Backend that stores budgets in kv.
+func NewKVBackend(kv KV) Backend {
+	return &kvBackend{kv: kv}
+}
+
+func (b *kvBackend) Earliest(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error) {
+	data, err := b.kv.Get(ctx, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, slot := decodeWindowLog(data).next(notBefore, limit, period)
+	return time.Unix(0, slot), nil
+}
+
+func (b *kvBackend) Reserve(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error) {
+	var slot time.Time
+	err := b.update(ctx, key, func(log windowLog) windowLog {
+		log, slot = log.reserve(notBefore, limit, period)
+		return log
+	})
+	return slot, err
+}
+
+func (b *kvBackend) Release(ctx context.Context, key string, slot time.Time) error {
+	return b.update(ctx, key, func(log windowLog) windowLog {
+		return log.release(slot)
+	})
+}
+
+// update applies fn to the window log of key, retrying when another writer
+// changed it in between.
+func (b *kvBackend) update(ctx context.Context, key string, fn func(windowLog) windowLog) error {
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		old, err := b.kv.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(fn(decodeWindowLog(old)))
+		if err != nil {
+			return err
+		}
+		ok, err := b.kv.CompareAndSwap(ctx, key, old, value)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("rate limit budget %q is too contended", key)
+}
+
+// MemoryKV is an in-process KV, useful in tests and for sharing budgets
+// between clients of a single process.
+type MemoryKV struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// NewMemoryKV returns an empty MemoryKV.
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{values: make(map[string][]byte)}
+}
+
+// Get implements KV.
+func (m *MemoryKV) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// CompareAndSwap implements KV.
+func (m *MemoryKV) CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.values[key]
+	if (old == nil) != !ok || (ok && string(cur) != string(old)) {
+		return false, nil
+	}
+	m.values[key] = append([]byte(nil), value...)
+	return true, nil
+}
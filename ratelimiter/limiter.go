@@ -236,11 +236,12 @@ func (rl *GlobalRateLimiter) Wait() (bool, time.Duration) {
 // RateLimitTransport wraps the HTTP transport to apply rate limiting.
 type RateLimitTransport struct {
 	Base            http.RoundTripper
-	Limiter         *RateLimiter                 // Standard rate limiter
+	Limiter         Limiter                      // Standard or adaptive rate limiter
 	GlobalLimiter   *GlobalRateLimiter           // For ZDX global limiting
 	WaitFunc        func() (bool, time.Duration) // Wait function reference (optional, overrides Limiter)
 	Logger          logger.Logger
-	AdditionalDelay time.Duration // Optional constant delay
+	AdditionalDelay time.Duration                            // Optional constant delay
+	OnWait          func(req *http.Request, d time.Duration) // Optional hook called before sleeping
 }
 
 // RoundTrip implements the http.RoundTripper interface for rate limiting.
@@ -262,6 +263,9 @@ func (rlt *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, err
 
 	if shouldWait {
 		rlt.Logger.Printf("[INFO] Rate limit exceeded for %s request. Waiting for %v before proceeding.", req.Method, delay)
+		if rlt.OnWait != nil {
+			rlt.OnWait(req, delay+rlt.AdditionalDelay)
+		}
 		time.Sleep(delay + rlt.AdditionalDelay)
 	}
 
@@ -269,5 +273,9 @@ func (rlt *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, err
 	if rlt.Base == nil {
 		rlt.Base = http.DefaultTransport
 	}
-	return rlt.Base.RoundTrip(req)
+	resp, err := rlt.Base.RoundTrip(req)
+	if rlt.WaitFunc == nil && rlt.Limiter != nil && resp != nil {
+		rlt.Limiter.Observe(req.Method, resp)
+	}
+	return resp, err
 }
@@ -0,0 +1,232 @@
+package ratelimiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
+)
+
+// Backend stores rate-limit budgets shared by every process that uses it.
+// Implementations must be safe for concurrent use by multiple goroutines and,
+// to be useful, by multiple processes.
+type Backend interface {
+	// Earliest returns the first slot at or after notBefore that the sliding
+	// window stored under key has room for, allowing at most limit requests per
+	// period. Nothing is booked.
+	Earliest(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error)
+	// Reserve books the first slot at or after notBefore for one request, like
+	// Earliest. It returns the time of the slot, which the caller must wait for
+	// before sending the request; the slot is booked, so the caller must not
+	// ask again.
+	Reserve(ctx context.Context, key string, limit int, period time.Duration, notBefore time.Time) (time.Time, error)
+	// Release frees a slot booked by Reserve under key for a request that will
+	// not be sent. Releasing a slot that is no longer booked does nothing.
+	Release(ctx context.Context, key string, slot time.Time) error
+}
+
+// Rule is one sliding-window budget shared by a set of method classes.
+type Rule struct {
+	Classes []MethodClass
+	Limit   int
+	Period  time.Duration
+}
+
+// SharedRateLimiter draws from budgets held in a Backend, so that every
+// process using the same backend and key prefix shares one budget. Budgets are
+// keyed by the prefix, the method classes of the rule and its period, e.g.
+// "acme/zia/read/1h0m0s".
+type SharedRateLimiter struct {
+	backend   Backend
+	keyPrefix func() string
+	rules     []Rule
+
+	// Fallback is used when the backend fails. A nil Fallback lets the request through.
+	Fallback Limiter
+	// Logger receives backend errors. It may be nil.
+	Logger logger.Logger
+}
+
+// NewSharedRateLimiter returns a limiter enforcing rules through backend.
+// keyPrefix is evaluated on every request and usually identifies the tenant and
+// product, e.g. "acme/zia".
+func NewSharedRateLimiter(backend Backend, keyPrefix func() string, rules ...Rule) *SharedRateLimiter {
+	return &SharedRateLimiter{
+		backend:   backend,
+		keyPrefix: keyPrefix,
+		rules:     rules,
+	}
+}
+
+// Wait implements Limiter. It finds the first instant every rule that covers
+// the method class has room for and books that same slot in all of them, so no
+// budget holds a slot the request will not use. When a reservation fails, the
+// slots already booked for the request are released before falling back.
+func (s *SharedRateLimiter) Wait(method string) (bool, time.Duration) {
+	class := ClassOf(method)
+	prefix := ""
+	if s.keyPrefix != nil {
+		prefix = s.keyPrefix()
+	}
+	var covering []booking
+	for _, rule := range s.rules {
+		if rule.covers(class) && rule.Limit > 0 && rule.Period > 0 {
+			covering = append(covering, booking{key: rule.key(prefix), rule: rule})
+		}
+	}
+
+	ctx := context.Background()
+	at := time.Now()
+	for {
+		for _, b := range covering {
+			slot, err := s.backend.Earliest(ctx, b.key, b.rule.Limit, b.rule.Period, at)
+			if err != nil {
+				return s.fallback(method, err)
+			}
+			if slot.After(at) {
+				at = slot
+			}
+		}
+		booked, latest, err := s.reserve(ctx, covering, at)
+		if err != nil {
+			s.release(ctx, booked)
+			return s.fallback(method, err)
+		}
+		if !latest.After(at) {
+			break
+		}
+		// Another process took the slot in between; book them all again later.
+		s.release(ctx, booked)
+		at = latest
+	}
+	wait := time.Until(at)
+	if wait <= 0 {
+		return false, 0
+	}
+	return true, wait
+}
+
+// reserve books a slot at or after at in every budget and returns the
+// bookings made and the latest slot among them.
+func (s *SharedRateLimiter) reserve(ctx context.Context, covering []booking, at time.Time) ([]booking, time.Time, error) {
+	booked := make([]booking, 0, len(covering))
+	latest := at
+	for _, b := range covering {
+		slot, err := s.backend.Reserve(ctx, b.key, b.rule.Limit, b.rule.Period, at)
+		if err != nil {
+			return booked, latest, err
+		}
+		b.slot = slot
+		booked = append(booked, b)
+		if slot.After(latest) {
+			latest = slot
+		}
+	}
+	return booked, latest, nil
+}
+
+func (s *SharedRateLimiter) fallback(method string, err error) (bool, time.Duration) {
+	if s.Logger != nil {
+		s.Logger.Printf("[ERROR] Shared rate limit backend failed, using local limits: %v", err)
+	}
+	if s.Fallback == nil {
+		return false, 0
+	}
+	return s.Fallback.Wait(method)
+}
+
+// booking is a slot reserved under the budget key of a rule.
+type booking struct {
+	key  string
+	rule Rule
+	slot time.Time
+}
+
+func (s *SharedRateLimiter) release(ctx context.Context, booked []booking) {
+	for _, b := range booked {
+		if err := s.backend.Release(ctx, b.key, b.slot); err != nil && s.Logger != nil {
+			s.Logger.Printf("[ERROR] Failed to release shared rate limit slot of %s: %v", b.key, err)
+		}
+	}
+}
+
+// Observe implements Limiter. Shared budgets do not learn from responses; wrap
+// the limiter in an AdaptiveRateLimiter for that.
+func (s *SharedRateLimiter) Observe(method string, resp *http.Response) {}
+
+func (r Rule) covers(class MethodClass) bool {
+	for _, c := range r.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) key(prefix string) string {
+	classes := make([]string, len(r.Classes))
+	for i, c := range r.Classes {
+		classes[i] = string(c)
+	}
+	sort.Strings(classes)
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(prefix, "/"), strings.Join(classes, "+"), r.Period)
+}
+
+// windowLog is the persisted state of one sliding window: the times, in Unix
+// nanoseconds, at which slots were booked.
+type windowLog []int64
+
+func decodeWindowLog(data []byte) windowLog {
+	var log windowLog
+	if len(data) == 0 || json.Unmarshal(data, &log) != nil {
+		// A missing or unreadable log starts an empty window.
+		return nil
+	}
+	return log
+}
+
+// next returns the bookings still in the window at at and the first slot, in
+// Unix nanoseconds, at or after at with room for one more.
+func (log windowLog) next(at time.Time, limit int, period time.Duration) (windowLog, int64) {
+	cutoff := at.Add(-period).UnixNano()
+	kept := log[:0]
+	for _, t := range log {
+		if t > cutoff {
+			kept = append(kept, t)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i] < kept[j] })
+
+	slot := at.UnixNano()
+	if len(kept) >= limit {
+		// The slot frees up when the limit-th most recent booking leaves the window.
+		if free := kept[len(kept)-limit] + int64(period); free > slot {
+			slot = free
+		}
+	}
+	return kept, slot
+}
+
+// reserve books the first slot at or after at and returns the updated log and the slot.
+func (log windowLog) reserve(at time.Time, limit int, period time.Duration) (windowLog, time.Time) {
+	kept, slot := log.next(at, limit, period)
+	kept = append(kept, slot)
+	sort.Slice(kept, func(i, j int) bool { return kept[i] < kept[j] })
+	return kept, time.Unix(0, slot)
+}
+
+// release removes one booking of slot from the log.
+func (log windowLog) release(slot time.Time) windowLog {
+	at := slot.UnixNano()
+	for i, t := range log {
+		if t == at {
+			return append(log[:i], log[i+1:]...)
+		}
+	}
+	return log
+}
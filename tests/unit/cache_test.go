@@ -7,6 +7,8 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -140,3 +142,89 @@ func Test_cache_can_be_cleared(t *testing.T) {
 		t.Errorf("Cache was not cleared")
 	}
 }
+
+func newCachedResponse(body string, etag string) *http.Response {
+	resp := &http.Response{
+		StatusCode:    http.StatusOK,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if etag != "" {
+		resp.Header.Set("ETag", etag)
+	}
+	return resp
+}
+
+func Test_disk_cache_persists_and_expires(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewDiskCache(dir, 50*time.Millisecond, 1)
+	assert.NoError(t, err)
+
+	c.Set("https://api/zia/ruleLabels/1", newCachedResponse(`{"id":1}`, `"v1"`))
+
+	reopened, err := cache.NewDiskCache(dir, 50*time.Millisecond, 1)
+	assert.NoError(t, err)
+	resp := reopened.Get("https://api/zia/ruleLabels/1")
+	if assert.NotNil(t, resp) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, `{"id":1}`, string(body))
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Nil(t, reopened.Get("https://api/zia/ruleLabels/1"))
+	stale := reopened.GetStale("https://api/zia/ruleLabels/1")
+	if assert.NotNil(t, stale) {
+		req := httptest.NewRequest(http.MethodGet, "https://api/zia/ruleLabels/1", nil)
+		assert.True(t, cache.SetConditionalHeaders(req, stale))
+		assert.Equal(t, `"v1"`, req.Header.Get("If-None-Match"))
+	}
+
+	reopened.ClearAllKeysWithPrefix("https://api/zia/ruleLabels")
+	assert.Nil(t, reopened.GetStale("https://api/zia/ruleLabels/1"))
+}
+
+func Test_disk_cache_is_bounded(t *testing.T) {
+	c, err := cache.NewDiskCache(t.TempDir(), time.Hour, 1)
+	assert.NoError(t, err)
+
+	big := strings.Repeat("x", 400<<10)
+	c.Set("a", newCachedResponse(big, ""))
+	time.Sleep(10 * time.Millisecond)
+	c.Set("b", newCachedResponse(big, ""))
+	time.Sleep(10 * time.Millisecond)
+	c.Set("c", newCachedResponse(big, ""))
+
+	assert.Nil(t, c.Get("a"), "the least recently used entry is evicted")
+	assert.NotNil(t, c.Get("c"))
+}
+
+func Test_disk_cache_encrypts_entries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.NewDiskCache(dir, time.Hour, 1, cache.WithEncryptionKey([]byte("key-a")))
+	assert.NoError(t, err)
+
+	c.Set("https://api/zcc/getOtp", newCachedResponse(`{"otp":"123456"}`, ""))
+	files, _ := filepath.Glob(filepath.Join(dir, "*.cache"))
+	if assert.Len(t, files, 1) {
+		data, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "123456")
+		assert.NotContains(t, string(data), "getOtp")
+	}
+
+	resp := c.Get("https://api/zcc/getOtp")
+	if assert.NotNil(t, resp) {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, `{"otp":"123456"}`, string(body))
+	}
+
+	other, err := cache.NewDiskCache(dir, time.Hour, 1, cache.WithEncryptionKey([]byte("key-b")))
+	assert.NoError(t, err)
+	assert.Nil(t, other.GetStale("https://api/zcc/getOtp"), "another key cannot read the entry")
+	plain, err := cache.NewDiskCache(dir, time.Hour, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, plain.GetStale("https://api/zcc/getOtp"))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_default_redaction_policy_masks_secrets(t *testing.T) {
+	policy := logger.DefaultRedactionPolicy()
+
+	dump := "POST /oauth2/v1/token HTTP/1.1\r\n" +
+		"Authorization: Bearer abc.def.ghi\r\n" +
+		"Cookie: JSESSIONID=1234\r\n" +
+		"Content-Type: application/x-www-form-urlencoded\r\n\r\n" +
+		"grant_type=client_credentials&client_id=my-client&client_secret=s3cr3t&client_assertion=eyJhbGciOi"
+	out := policy.Redact(dump)
+	assert.NotContains(t, out, "abc.def.ghi")
+	assert.NotContains(t, out, "JSESSIONID=1234")
+	assert.NotContains(t, out, "s3cr3t")
+	assert.NotContains(t, out, "eyJhbGciOi")
+	assert.Contains(t, out, "client_id=my-client")
+	assert.Contains(t, out, "Content-Type: application/x-www-form-urlencoded")
+
+	body := `{"exitOtp":"111111","uninstallPass":"hunter2","access_token":"tok","otp":123456,"name":"keep"}`
+	out = policy.Redact(body)
+	assert.NotContains(t, out, "111111")
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, `"tok"`)
+	assert.NotContains(t, out, "123456")
+	assert.Contains(t, out, `"name":"keep"`)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &doc))
+
+	assert.Equal(t, "using Bearer "+logger.RedactedValue+" now", policy.Redact("using Bearer eyJraWQiOiJ4.abc-_ now"))
+}
+
+func Test_default_redaction_policy_matches_whole_words(t *testing.T) {
+	policy := logger.DefaultRedactionPolicy()
+
+	body := `{"bypass":"keep-1","compass":"keep-2","nextPageToken":"keep-3","appBypass":"keep-4","uninstall_password":"hide-1","zpaDisablePass":"hide-2","PASS":"hide-3","clientSecret":"hide-4","accessToken":"hide-5"}`
+	out := policy.Redact(body)
+	for _, kept := range []string{"keep-1", "keep-2", "keep-3", "keep-4"} {
+		assert.Contains(t, out, kept)
+	}
+	for _, hidden := range []string{"hide-1", "hide-2", "hide-3", "hide-4", "hide-5"} {
+		assert.NotContains(t, out, hidden)
+	}
+	assert.Equal(t, "bypass=on&exitPass="+logger.RedactedValue, policy.Redact("bypass=on&exitPass=hunter2"))
+
+	assert.True(t, policy.IsSensitiveField("exitOtp"))
+	assert.True(t, policy.IsSensitiveField("key_secret"))
+	assert.False(t, policy.IsSensitiveField("bypass"))
+	assert.False(t, policy.IsSensitiveField("compass"))
+	assert.False(t, policy.IsSensitiveField("nextPageToken"))
+}
+
+func Test_redaction_policy_is_configurable(t *testing.T) {
+	policy := &logger.RedactionPolicy{
+		Fields:      []string{"scimToken"},
+		Values:      []string{"literal-secret"},
+		Replacement: "[hidden]",
+	}
+	out := policy.Redact(`{"scimToken":"abc","other":"literal-secret","accessToken":"visible"}`)
+	assert.Equal(t, `{"scimToken":"[hidden]","other":"[hidden]","accessToken":"visible"}`, out)
+
+	var nilPolicy *logger.RedactionPolicy
+	assert.Equal(t, "client_secret=x", nilPolicy.Redact("client_secret=x"))
+}
+
+func Test_slog_logger_levels_and_redacts(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewSlogLogger(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), nil)
+
+	l.Printf("[TRACE] dropped below debug")
+	l.Printf("[WARN] token refresh with client_secret=%s", "s3cr3t")
+	l.LogAttrs(context.Background(), slog.LevelInfo, "auth", slog.String("client_secret", "s3cr3t"), slog.Int("attempt", 2))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, buf.String(), "s3cr3t")
+
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "WARN", first["level"])
+	assert.Equal(t, "token refresh with client_secret="+logger.RedactedValue, first["msg"])
+
+	var second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, logger.RedactedValue, second["client_secret"])
+	assert.EqualValues(t, 2, second["attempt"])
+}
+
+func Test_log_attempt_emits_structured_record(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewSlogLogger(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), nil)
+
+	logger.LogAttempt(context.Background(), l, logger.Attempt{
+		RequestID: "req-1",
+		Product:   "zia",
+		Method:    http.MethodGet,
+		Path:      "/zia/api/v1/ruleLabels",
+		Status:    http.StatusTooManyRequests,
+		Duration:  150 * time.Millisecond,
+		Retry:     1,
+	})
+	logger.LogAttempt(context.Background(), l, logger.Attempt{RequestID: "req-2", Err: errors.New("dial tcp: refused")})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, "WARN", rec["level"])
+	assert.Equal(t, "http request", rec["msg"])
+	assert.Equal(t, "req-1", rec["request_id"])
+	assert.Equal(t, "zia", rec["product"])
+	assert.Equal(t, "/zia/api/v1/ruleLabels", rec["path"])
+	assert.EqualValues(t, 429, rec["status"])
+	assert.EqualValues(t, 1, rec["retry"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	assert.Equal(t, "ERROR", rec["level"])
+	assert.Equal(t, "dial tcp: refused", rec["error"])
+
+	// Printf-only loggers are left alone.
+	logger.LogAttempt(context.Background(), logger.NewNopLogger(), logger.Attempt{RequestID: "req-3"})
+}
+
+type printfRecorder struct {
+	lines []string
+}
+
+func (p *printfRecorder) Printf(format string, v ...interface{}) {
+	p.lines = append(p.lines, format)
+}
+
+func Test_redacting_logger_wraps_plain_loggers(t *testing.T) {
+	rec := &printfRecorder{}
+	l := logger.NewRedactingLogger(rec, logger.DefaultRedactionPolicy())
+	l.Printf("[DEBUG] 100%% done, password=%s", "hunter2")
+
+	require.Len(t, rec.lines, 1)
+	assert.True(t, strings.HasPrefix(rec.lines[0], "[DEBUG]"))
+	assert.NotContains(t, rec.lines[0], "hunter2")
+}
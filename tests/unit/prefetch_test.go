@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/prefetch"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_prefetch_ordered_yields_in_order_with_bounded_concurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	var got []int
+	err := prefetch.Ordered(context.Background(), 1, 20, 4,
+		func(ctx context.Context, i int) (int, error) {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			// Later pages finish first.
+			time.Sleep(time.Duration(21-i) * time.Millisecond)
+			inFlight.Add(-1)
+			return i, nil
+		},
+		func(i int) bool {
+			got = append(got, i)
+			return true
+		})
+	assert.NoError(t, err)
+	assert.Len(t, got, 20)
+	for i, v := range got {
+		assert.Equal(t, i+1, v)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+}
+
+func Test_prefetch_ordered_aborts_on_first_error(t *testing.T) {
+	boom := errors.New("page 3 failed")
+	var cancelled atomic.Int32
+	err := prefetch.Ordered(context.Background(), 1, 10, 3,
+		func(ctx context.Context, i int) (int, error) {
+			if i == 3 {
+				return 0, boom
+			}
+			select {
+			case <-ctx.Done():
+				cancelled.Add(1)
+				return 0, ctx.Err()
+			case <-time.After(time.Second):
+				return i, nil
+			}
+		},
+		func(int) bool { return true })
+	assert.ErrorIs(t, err, boom)
+	assert.Greater(t, cancelled.Load(), int32(0), "requests in flight are cancelled")
+}
+
+func Test_prefetch_ordered_stops_when_yield_returns_false(t *testing.T) {
+	var fetched atomic.Int32
+	var got []int
+	err := prefetch.Ordered(context.Background(), 1, 100, 2,
+		func(ctx context.Context, i int) (int, error) {
+			fetched.Add(1)
+			return i, nil
+		},
+		func(i int) bool {
+			got = append(got, i)
+			return i < 3
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, got)
+	assert.Less(t, fetched.Load(), int32(10))
+}
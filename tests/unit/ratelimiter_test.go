@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/ratelimiter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func Test_adaptive_limiter_uses_fallback_until_headers_are_seen(t *testing.T) {
+	limiter := ratelimiter.NewAdaptiveRateLimiter(ratelimiter.NewRateLimiter(1, 1, 10, 10))
+
+	wait, _ := limiter.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, d := limiter.Wait(http.MethodGet)
+	assert.True(t, wait, "the fixed window allows one GET per 10s")
+	assert.Greater(t, d, time.Duration(0))
+
+	for _, s := range limiter.State() {
+		assert.False(t, s.Adaptive)
+	}
+}
+
+func Test_adaptive_limiter_follows_server_budget(t *testing.T) {
+	limiter := ratelimiter.NewAdaptiveRateLimiter(ratelimiter.NewRateLimiter(1, 1, 10, 10))
+
+	limiter.Observe(http.MethodGet, rateLimitResponse(http.StatusOK, map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "3",
+		"X-RateLimit-Reset":     "30",
+	}))
+
+	// The server allows more than the fixed window, and one request stays in reserve.
+	for i := 0; i < 2; i++ {
+		wait, _ := limiter.Wait(http.MethodGet)
+		assert.False(t, wait)
+	}
+	wait, d := limiter.Wait(http.MethodGet)
+	assert.True(t, wait)
+	assert.InDelta(t, 30*time.Second, d, float64(time.Second))
+
+	// Writes have their own budget and are still paced by the fallback.
+	wait, _ = limiter.Wait(http.MethodPost)
+	assert.False(t, wait)
+
+	states := limiter.State()
+	require.Len(t, states, 2)
+	assert.Equal(t, ratelimiter.MethodClassRead, states[0].Class)
+	assert.True(t, states[0].Adaptive)
+	assert.Equal(t, 100, states[0].Limit)
+	assert.Equal(t, 1, states[0].Remaining)
+	assert.Equal(t, ratelimiter.MethodClassWrite, states[1].Class)
+	assert.False(t, states[1].Adaptive)
+
+	// A larger budget reported later grows the allowance again.
+	limiter.Observe(http.MethodGet, rateLimitResponse(http.StatusOK, map[string]string{
+		"RateLimit-Remaining": "50",
+		"RateLimit-Reset":     "30",
+	}))
+	wait, _ = limiter.Wait(http.MethodGet)
+	assert.False(t, wait)
+}
+
+func Test_adaptive_limiter_honors_retry_after(t *testing.T) {
+	limiter := ratelimiter.NewAdaptiveRateLimiter(nil)
+
+	limiter.Observe(http.MethodDelete, rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}))
+
+	wait, d := limiter.Wait(http.MethodDelete)
+	assert.True(t, wait)
+	assert.InDelta(t, 5*time.Second, d, float64(time.Second))
+
+	wait, _ = limiter.Wait(http.MethodGet)
+	assert.False(t, wait)
+
+	states := limiter.State()
+	require.NotEmpty(t, states)
+	assert.Equal(t, ratelimiter.MethodClassDelete, states[0].Class)
+	assert.False(t, states[0].BlockedUntil.IsZero())
+}
+
+func Test_method_classes(t *testing.T) {
+	assert.Equal(t, ratelimiter.MethodClassRead, ratelimiter.ClassOf(http.MethodGet))
+	assert.Equal(t, ratelimiter.MethodClassWrite, ratelimiter.ClassOf(http.MethodPatch))
+	assert.Equal(t, ratelimiter.MethodClassWrite, ratelimiter.ClassOf(http.MethodPut))
+	assert.Equal(t, ratelimiter.MethodClassDelete, ratelimiter.ClassOf(http.MethodDelete))
+}
+
+func Test_shared_limiter_draws_from_one_budget(t *testing.T) {
+	dir := t.TempDir()
+	rules := []ratelimiter.Rule{
+		{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead}, Limit: 2, Period: time.Minute},
+		{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassWrite, ratelimiter.MethodClassDelete}, Limit: 1, Period: time.Minute},
+	}
+	newLimiter := func(prefix string) *ratelimiter.SharedRateLimiter {
+		// Each limiter opens its own backend, as separate processes would.
+		backend, err := ratelimiter.NewFileBackend(dir)
+		require.NoError(t, err)
+		return ratelimiter.NewSharedRateLimiter(backend, func() string { return prefix }, rules...)
+	}
+	first, second := newLimiter("acme/zia"), newLimiter("acme/zia")
+
+	wait, _ := first.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, _ = second.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, d := first.Wait(http.MethodGet)
+	assert.True(t, wait, "the read budget is shared by both limiters")
+	assert.InDelta(t, time.Minute, d, float64(time.Second))
+
+	// Writes and deletes share their own budget.
+	wait, _ = second.Wait(http.MethodPost)
+	assert.False(t, wait)
+	wait, _ = first.Wait(http.MethodDelete)
+	assert.True(t, wait)
+
+	// Another tenant has its own budget.
+	wait, _ = newLimiter("other/zia").Wait(http.MethodGet)
+	assert.False(t, wait)
+}
+
+func Test_shared_limiter_with_kv_backend(t *testing.T) {
+	kv := ratelimiter.NewMemoryKV()
+	rule := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead}, Limit: 1, Period: time.Hour}
+	a := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(kv), func() string { return "acme/zpa" }, rule)
+	b := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(kv), func() string { return "acme/zpa" }, rule)
+
+	wait, _ := a.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, d := b.Wait(http.MethodGet)
+	assert.True(t, wait)
+	assert.InDelta(t, time.Hour, d, float64(time.Second))
+
+	// The second request booked the next slot, so a third waits a further hour.
+	_, d = a.Wait(http.MethodGet)
+	assert.InDelta(t, 2*time.Hour, d, float64(time.Second))
+
+	value, err := kv.Get(context.Background(), "acme/zpa/read/1h0m0s")
+	require.NoError(t, err)
+	assert.NotEmpty(t, value)
+}
+
+type failingKV struct{}
+
+func (failingKV) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingKV) CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func Test_shared_limiter_falls_back_on_backend_errors(t *testing.T) {
+	rule := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead}, Limit: 100, Period: time.Hour}
+	limiter := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(failingKV{}), func() string { return "acme/zia" }, rule)
+	limiter.Fallback = ratelimiter.NewRateLimiter(1, 1, 10, 10)
+
+	wait, _ := limiter.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, _ = limiter.Wait(http.MethodGet)
+	assert.True(t, wait, "the fallback window applies while the backend fails")
+}
+
+// partlyFailingKV fails every write to the key of one budget.
+type partlyFailingKV struct {
+	*ratelimiter.MemoryKV
+	failing string
+}
+
+func (kv partlyFailingKV) CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error) {
+	if key == kv.failing {
+		return false, errors.New("store unavailable")
+	}
+	return kv.MemoryKV.CompareAndSwap(ctx, key, old, value)
+}
+
+func Test_shared_limiter_releases_slots_when_a_rule_fails(t *testing.T) {
+	kv := ratelimiter.NewMemoryKV()
+	all := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead, ratelimiter.MethodClassWrite}, Limit: 1, Period: time.Hour}
+	writes := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassWrite}, Limit: 1, Period: time.Minute}
+	failing := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(partlyFailingKV{MemoryKV: kv, failing: "acme/zia/write/1m0s"}), func() string { return "acme/zia" }, all, writes)
+	healthy := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(kv), func() string { return "acme/zia" }, all, writes)
+
+	wait, _ := failing.Wait(http.MethodPost)
+	assert.False(t, wait, "the request goes through without a fallback")
+
+	wait, _ = healthy.Wait(http.MethodGet)
+	assert.False(t, wait, "the slot booked before the failure was released")
+	wait, d := healthy.Wait(http.MethodGet)
+	assert.True(t, wait)
+	assert.InDelta(t, time.Hour, d, float64(time.Second))
+}
+
+func Test_shared_limiter_books_every_rule_at_the_same_slot(t *testing.T) {
+	kv := ratelimiter.NewMemoryKV()
+	hourly := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead}, Limit: 1, Period: time.Hour}
+	perMinute := ratelimiter.Rule{Classes: []ratelimiter.MethodClass{ratelimiter.MethodClassRead}, Limit: 1, Period: time.Minute}
+	limiter := ratelimiter.NewSharedRateLimiter(ratelimiter.NewKVBackend(kv), func() string { return "acme/zia" }, perMinute, hourly)
+
+	wait, _ := limiter.Wait(http.MethodGet)
+	assert.False(t, wait)
+	wait, d := limiter.Wait(http.MethodGet)
+	assert.True(t, wait)
+	assert.InDelta(t, time.Hour, d, float64(time.Second))
+
+	slots := func(key string) []int64 {
+		value, err := kv.Get(context.Background(), key)
+		require.NoError(t, err)
+		var log []int64
+		require.NoError(t, json.Unmarshal(value, &log))
+		return log
+	}
+	assert.Equal(t, slots("acme/zia/read/1h0m0s"), slots("acme/zia/read/1m0s"), "the per-minute budget is not booked an hour early")
+}
@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -229,3 +231,87 @@ device-002,user2@example.com,"host with spaces"`
 		assert.Len(t, lines, 3)
 	})
 }
+
+func TestDownloadDevices_IterDevices_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	csvData := "User,Device type,Device model,UDID,Mac Address,Zscaler Client Connector Version,Policy Name,Device State,Registration State,Registration Time,Last Deregistration Time,Last Seen Connected to ZIA,Hostname,Tunnel Version\n" +
+		"user1@example.com,Windows,\"Latitude 7440, 14\"\"\",device-001,00:11:22:33:44:55,4.4.0.300,Default,Registered,Registered,2024-01-15 10:00:00 GMT,N/A,\"Jan 16, 2024 8:30:00 AM\",host1,Z-Tunnel 2.0\n" +
+		"user2@example.com,macOS,MacBookPro18,device-002,,4.3.1.10,Engineering,Unregistered,Removed,2023-11-02 09:15:00,2024-01-01 00:00:00,--,host2,Z-Tunnel 1.0\n"
+	server.On("GET", "/zcc/papi/public/v1/downloadDevices", common.CSVResponse(csvData))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var devices []downloaddevices.Device
+	for device, err := range downloaddevices.IterDevices(context.Background(), service, "3,4", "1") {
+		require.NoError(t, err)
+		devices = append(devices, device)
+	}
+
+	require.Len(t, devices, 2)
+	first := devices[0]
+	assert.Equal(t, "user1@example.com", first.User)
+	assert.Equal(t, "Windows", first.DeviceType)
+	assert.Equal(t, `Latitude 7440, 14"`, first.DeviceModel)
+	assert.Equal(t, "device-001", first.UDID)
+	assert.Equal(t, "4.4.0.300", first.AgentVersion)
+	assert.Equal(t, "Registered", first.RegistrationState)
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), first.RegistrationTime.UTC())
+	assert.True(t, first.DeregistrationTime.IsZero())
+	assert.Equal(t, time.Date(2024, 1, 16, 8, 30, 0, 0, time.UTC), first.LastSeen)
+	assert.Equal(t, map[string]string{"Tunnel Version": "Z-Tunnel 2.0"}, first.Extra)
+	assert.True(t, devices[1].LastSeen.IsZero())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), devices[1].DeregistrationTime)
+
+	assert.Equal(t, "osTypes=3%2C4&registrationTypes=1", server.LastRequest().Query)
+}
+
+func TestDownloadDevices_IterServiceStatus_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	csvData := "Report generated for company 123\n\n" +
+		"user_name,os_type,machine_hostname,udid,internet_security_status,private_access_status,digital_experience_status,last_seen\n" +
+		"user1@example.com,Windows,host1,device-001,ON,OFF,Disabled,2024-01-15T10:00:00Z\n"
+	server.On("GET", "/zcc/papi/public/v1/downloadServiceStatus", common.CSVResponse(csvData))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var statuses []downloaddevices.ServiceStatus
+	for status, err := range downloaddevices.IterServiceStatus(context.Background(), service, "", "") {
+		require.NoError(t, err)
+		statuses = append(statuses, status)
+	}
+
+	require.Len(t, statuses, 1)
+	assert.Equal(t, downloaddevices.ServiceStatus{
+		User:       "user1@example.com",
+		DeviceType: "Windows",
+		Hostname:   "host1",
+		UDID:       "device-001",
+		ZIAStatus:  "ON",
+		ZPAStatus:  "OFF",
+		ZDXStatus:  "Disabled",
+		LastSeen:   time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}, statuses[0])
+}
+
+func TestDownloadDevices_NewDeviceDecoder(t *testing.T) {
+	csvData := `udid,username,hostname,os_type
+device-001,"user, with comma@example.com",host1,1
+device-002,user2@example.com,"host with spaces",2`
+
+	var devices []downloaddevices.Device
+	for device, err := range downloaddevices.NewDeviceDecoder(strings.NewReader(csvData)).All() {
+		require.NoError(t, err)
+		devices = append(devices, device)
+	}
+
+	require.Len(t, devices, 2)
+	assert.Equal(t, "user, with comma@example.com", devices[0].User)
+	assert.Equal(t, "host with spaces", devices[1].Hostname)
+	assert.Equal(t, "2", devices[1].DeviceType)
+}
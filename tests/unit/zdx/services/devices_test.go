@@ -4,11 +4,13 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	zdxcommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx/services/common"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx/services/reports/devices"
 )
 
@@ -72,6 +74,68 @@ func TestDevices_GetAllDevices_SDK(t *testing.T) {
 	assert.Len(t, result, 2)
 }
 
+func TestDevices_IterAllDevices_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zdx/v1/devices", common.SuccessResponse(map[string]interface{}{
+		"devices": []devices.DeviceDetail{
+			{ID: 1, Name: "Device 1"},
+			{ID: 2, Name: "Device 2"},
+		},
+		"next_offset": nil,
+	}))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var ids []int
+	for device, err := range devices.IterAllDevices(context.Background(), service, devices.GetDevicesFilters{Offset: "start"}) {
+		require.NoError(t, err)
+		ids = append(ids, device.ID)
+	}
+
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.Equal(t, 1, server.GetCallCount("GET", "/zdx/v1/devices"), "a null next_offset ends the list")
+	assert.Contains(t, server.LastRequest().Query, "offset=start")
+}
+
+func TestZDXCommon_IterOffsetPages(t *testing.T) {
+	pages := map[string][]int{"": {1, 2}, "2": {3, 4}, "4": {5}}
+	next := map[string]string{"": "2", "2": "4", "4": ""}
+	var offsets []string
+	readPage := func(ctx context.Context, offset string) ([]int, string, error) {
+		offsets = append(offsets, offset)
+		return pages[offset], next[offset], nil
+	}
+
+	var items []int
+	for item, err := range zdxcommon.IterOffsetPages(context.Background(), "", readPage) {
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+	assert.Equal(t, []string{"", "2", "4"}, offsets)
+
+	offsets = nil
+	for item, err := range zdxcommon.IterOffsetPages(context.Background(), "", readPage) {
+		require.NoError(t, err)
+		if item == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{""}, offsets, "breaking out stops the requests")
+
+	var errs []error
+	for _, err := range zdxcommon.IterOffsetPages(context.Background(), "", func(ctx context.Context, offset string) ([]int, string, error) {
+		return nil, "", errors.New("boom")
+	}) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "boom")
+}
+
 // =====================================================
 // Structure Tests - JSON marshaling/unmarshaling
 // =====================================================
@@ -4,11 +4,15 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/adminauditlogs"
 )
 
@@ -76,7 +80,72 @@ func TestAdminAuditLogs_Delete_SDK(t *testing.T) {
 	require.NoError(t, err)
 }
 
-// Note: GetAdminAuditLogsDownload test omitted due to raw byte response handling
+const auditLogCSV = `"Audit Logs Report"
+"Report Created:","Oct 7, 2026 10:30:00 AM"
+
+No.,Time,Action,Category,Sub-Category,Resource,Admin Login Name,Client IP,Interface,Result,Trace ID,Pre-Action,Post-Action,Session
+1,2026-10-07 10:01:02,UPDATE,Firewall,"Firewall Filtering, Rules",Block all,admin@example.com,10.0.0.1,UI,SUCCESS,abc123,"{""order"":2}","{""order"":1}",s-1
+2,"Oct 7, 2026 9:00:00 AM",SIGN_IN,Login,N/A,N/A,api@example.com,10.0.0.2,API,FAILURE,abc124,,,s-2
+`
+
+func TestAdminAuditLogs_GetAdminAuditLogsDownload_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zia/api/v1/auditlogEntryReport/download", common.CSVResponse(auditLogCSV))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	data, err := adminauditlogs.GetAdminAuditLogsDownload(context.Background(), service)
+
+	require.NoError(t, err)
+	assert.Equal(t, auditLogCSV, string(data))
+}
+
+func TestAdminAuditLogs_IterAdminAuditLogEntries_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zia/api/v1/auditlogEntryReport/download", common.CSVResponse(auditLogCSV))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var entries []adminauditlogs.AuditLogEntry
+	for entry, err := range adminauditlogs.IterAdminAuditLogEntries(context.Background(), service) {
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 2)
+	first := entries[0]
+	assert.Equal(t, time.Date(2026, 10, 7, 10, 1, 2, 0, time.UTC), first.Time)
+	assert.Equal(t, "UPDATE", first.Action)
+	assert.Equal(t, "Firewall Filtering, Rules", first.SubCategory)
+	assert.Equal(t, "admin@example.com", first.Admin)
+	assert.Equal(t, "UI", first.ActionInterface)
+	assert.Equal(t, "SUCCESS", first.ActionResult)
+	assert.Equal(t, `{"order":2}`, first.PreAction)
+	assert.Equal(t, map[string]string{"No.": "1", "Session": "s-1"}, first.Extra)
+	assert.Equal(t, time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC), entries[1].Time)
+	assert.Equal(t, "N/A", entries[1].Resource, "text columns are kept as written")
+}
+
+func TestAdminAuditLogs_NewAuditLogDecoder_HeaderVariations(t *testing.T) {
+	report := "admin_name;action_type;timestamp;action_result\n" +
+		"ops@example.com;CREATE;1791367200000;SUCCESS\n"
+
+	dec := adminauditlogs.NewAuditLogDecoder(strings.NewReader(report), csvx.WithComma(';'))
+	entry, err := dec.Decode()
+
+	require.NoError(t, err)
+	assert.Equal(t, "ops@example.com", entry.Admin)
+	assert.Equal(t, "CREATE", entry.Action)
+	assert.Equal(t, time.UnixMilli(1791367200000).UTC(), entry.Time)
+	_, err = dec.Decode()
+	assert.ErrorIs(t, err, io.EOF)
+}
 
 // =====================================================
 // Structure Tests
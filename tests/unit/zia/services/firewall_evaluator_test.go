@@ -0,0 +1,154 @@
+// Package services provides unit tests for ZIA services
+package services
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	ziacommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/evaluator"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipdestinationgroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipsourcegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservicegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservices"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/timewindow"
+)
+
+func refs(ids ...int) []ziacommon.IDNameExtensions {
+	out := make([]ziacommon.IDNameExtensions, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, ziacommon.IDNameExtensions{ID: id})
+	}
+	return out
+}
+
+func evaluatorRules() []filteringrules.FirewallFilteringRules {
+	return []filteringrules.FirewallFilteringRules{
+		{ID: 99, Name: "Default Firewall Filtering Rule", Order: -1, DefaultRule: true, State: "ENABLED", Action: "ALLOW"},
+		{ID: 1, Name: "Disabled block", Order: 1, State: "DISABLED", Action: "BLOCK_DROP"},
+		{ID: 2, Name: "Block SSH for guests", Order: 2, State: "ENABLED", Action: "BLOCK_DROP", Groups: refs(20), NwServices: refs(300)},
+		{ID: 3, Name: "Finance to payroll", Order: 3, State: "ENABLED", Action: "ALLOW",
+			Departments: refs(30), DestIpGroups: refs(200), NwServiceGroups: refs(400), TimeWindows: refs(500)},
+		{ID: 4, Name: "Block embargoed countries", Order: 4, State: "ENABLED", Action: "BLOCK_DROP", SourceCountries: []string{"COUNTRY_KP"}},
+		{ID: 5, Name: "Branch office", Order: 5, State: "ENABLED", Action: "ALLOW", SrcIpGroups: refs(100), Locations: refs(40)},
+		{ID: 6, Name: "Managed devices", Order: 6, State: "ENABLED", Action: "ALLOW", Devices: refs(7)},
+	}
+}
+
+func evaluatorModel() *evaluator.Model {
+	return evaluator.NewModel(
+		evaluatorRules(),
+		[]ipsourcegroups.IPSourceGroups{{ID: 100, Name: "Branch", IPAddresses: []string{"10.1.0.1-10.1.0.254"}}},
+		[]ipdestinationgroups.IPDestinationGroups{{ID: 200, Name: "Payroll", Type: "DSTN_IP", Addresses: []string{"203.0.113.0/24"}}},
+		[]networkservices.NetworkServices{{ID: 300, Name: "SSH", DestTCPPorts: []networkservices.NetworkPorts{{Start: 22}}}},
+		[]networkservicegroups.NetworkServiceGroups{{ID: 400, Name: "Web", Services: []networkservicegroups.Services{
+			{ID: 401, Name: "HTTPS", DestTCPPorts: []networkservices.NetworkPorts{{Start: 443}}},
+			{ID: 402, Name: "Alt HTTP", DestTCPPorts: []networkservices.NetworkPorts{{Start: 8000, End: 8090}}},
+		}}},
+		[]timewindow.TimeWindow{{ID: 500, Name: "Work hours", StartTime: 8 * 60, EndTime: 18 * 60, DayOfWeek: []string{"MON", "TUE", "WED", "THU", "FRI"}}},
+	)
+}
+
+// monday10am is a Monday.
+var monday10am = time.Date(2026, time.October, 12, 10, 0, 0, 0, time.UTC)
+
+func TestFirewallEvaluator_MatchesInOrder(t *testing.T) {
+	model := evaluatorModel()
+	assert.True(t, model.Rules[len(model.Rules)-1].DefaultRule, "the default rule is evaluated last")
+
+	flow := evaluator.Flow{
+		UserID:       10,
+		GroupIDs:     []int{21},
+		DepartmentID: 30,
+		SrcIP:        netip.MustParseAddr("192.0.2.10"),
+		DestIP:       netip.MustParseAddr("203.0.113.5"),
+		Protocol:     evaluator.TCP,
+		DestPort:     443,
+		Time:         monday10am,
+	}
+	result := model.Evaluate(flow)
+	require.NotNil(t, result.Rule)
+	assert.Equal(t, "Finance to payroll", result.Rule.Name)
+	assert.Equal(t, "ALLOW", result.Action())
+	require.Len(t, result.Misses, 2)
+	assert.Equal(t, []string{"rule is disabled"}, result.Misses[0].Reasons)
+	assert.Equal(t, 2, result.Misses[1].Rule.ID)
+	assert.Len(t, result.Misses[1].Reasons, 2, "both the group and the service miss")
+	assert.Contains(t, result.Misses[1].Reasons[1], "TCP port 443")
+
+	flow.DestPort = 8080
+	assert.Equal(t, 3, model.Evaluate(flow).Rule.ID, "port ranges of grouped services match")
+
+	flow.Time = monday10am.AddDate(0, 0, 5)
+	result = model.Evaluate(flow)
+	assert.Equal(t, 99, result.Rule.ID, "outside the time window the flow falls through to the default rule")
+	require.Len(t, result.Misses, 6)
+	assert.Contains(t, result.Misses[2].Reasons[0], "outside the rule time windows")
+	assert.Equal(t, []string{"the flow has no source country"}, result.Misses[3].Reasons)
+	assert.Contains(t, result.Misses[5].Reasons[0], "not evaluated: devices")
+}
+
+func TestFirewallEvaluator_SourceCriteria(t *testing.T) {
+	model := evaluatorModel()
+
+	result := model.Evaluate(evaluator.Flow{SrcIP: netip.MustParseAddr("198.51.100.1"), SrcCountry: "KP", Time: monday10am})
+	assert.Equal(t, 4, result.Rule.ID, "countries match with or without the COUNTRY_ prefix")
+
+	result = model.Evaluate(evaluator.Flow{SrcIP: netip.MustParseAddr("10.1.0.10"), LocationID: 40, Time: monday10am})
+	assert.Equal(t, 5, result.Rule.ID, "source IP ranges in groups match")
+
+	result = model.Evaluate(evaluator.Flow{SrcIP: netip.MustParseAddr("10.1.0.255"), LocationID: 40, Time: monday10am})
+	assert.Equal(t, 99, result.Rule.ID)
+	assert.Contains(t, result.Misses[4].Reasons[0], "source IP 10.1.0.255")
+
+	rule := filteringrules.FirewallFilteringRules{SourceCountries: []string{"COUNTRY_US"}, ExcludeSrcCountries: true}
+	assert.Empty(t, model.Explain(&rule, evaluator.Flow{SrcCountry: "CA"}))
+	assert.Equal(t, []string{"source country US is excluded"}, model.Explain(&rule, evaluator.Flow{SrcCountry: "US"}))
+}
+
+func TestFirewallEvaluator_Destinations(t *testing.T) {
+	model := evaluatorModel()
+	rule := filteringrules.FirewallFilteringRules{
+		DestAddresses:    []string{".example.com", "192.0.2.0/28"},
+		DestIpCategories: []string{"PROFESSIONAL_SERVICES"},
+		DestCountries:    []string{"COUNTRY_FR"},
+		DestIpGroups:     refs(201),
+	}
+	assert.Empty(t, model.Explain(&rule, evaluator.Flow{DestHost: "api.example.com"}))
+	assert.Empty(t, model.Explain(&rule, evaluator.Flow{DestIP: netip.MustParseAddr("192.0.2.9")}))
+	assert.Empty(t, model.Explain(&rule, evaluator.Flow{DestCountry: "FR"}))
+	assert.Empty(t, model.Explain(&rule, evaluator.Flow{DestIPCategories: []string{"professional_services"}}))
+
+	reasons := model.Explain(&rule, evaluator.Flow{DestHost: "example.org"})
+	require.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "destination IP groups [201] are not loaded")
+}
+
+func TestFirewallEvaluator_Load(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zia/api/v1/firewallFilteringRules", common.SuccessResponse(evaluatorRules()))
+	server.On("GET", "/zia/api/v1/ipSourceGroups", common.SuccessResponse([]ipsourcegroups.IPSourceGroups{{ID: 100, IPAddresses: []string{"10.1.0.0/24"}}}))
+	server.On("GET", "/zia/api/v1/ipDestinationGroups", common.SuccessResponse([]ipdestinationgroups.IPDestinationGroups{}))
+	server.On("GET", "/zia/api/v1/networkServices", common.SuccessResponse([]networkservices.NetworkServices{}))
+	server.On("GET", "/zia/api/v1/networkServiceGroups", common.SuccessResponse([]networkservicegroups.NetworkServiceGroups{}))
+	server.On("GET", "/zia/api/v1/timeWindows", common.SuccessResponse([]timewindow.TimeWindow{}))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	model, err := evaluator.Load(context.Background(), service)
+	require.NoError(t, err)
+	assert.Len(t, model.Rules, 7)
+	assert.Contains(t, model.SourceGroups, 100)
+
+	result := model.Evaluate(evaluator.Flow{SrcIP: netip.MustParseAddr("10.1.0.10"), LocationID: 40, Time: monday10am})
+	assert.Equal(t, "Branch office", result.Rule.Name)
+}
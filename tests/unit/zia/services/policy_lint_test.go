@@ -0,0 +1,166 @@
+// Package services provides unit tests for ZIA services
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipdestinationgroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipsourcegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservicegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservices"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/timewindow"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/policylint"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/urlfilteringpolicies"
+)
+
+var lintNow = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+func findings(report *policylint.Report, kind string) []policylint.Finding {
+	var out []policylint.Finding
+	for _, f := range report.Findings {
+		if f.Kind == kind {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestPolicyLint_ShadowedAndDuplicates(t *testing.T) {
+	rules := policylint.FromFirewallRules([]filteringrules.FirewallFilteringRules{
+		{ID: 1, Name: "Block guests", Order: 1, State: "ENABLED", Action: "BLOCK_DROP", Groups: refs(20, 21)},
+		{ID: 2, Name: "Allow guests web", Order: 2, State: "ENABLED", Action: "ALLOW", Groups: refs(20), NwServices: refs(300)},
+		{ID: 3, Name: "Block guests again", Order: 3, State: "ENABLED", Action: "BLOCK_DROP", Groups: refs(21, 20)},
+		{ID: 4, Name: "Block finance SSH", Order: 4, State: "ENABLED", Action: "BLOCK_DROP", Departments: refs(30), NwServices: refs(300)},
+		{ID: 5, Name: "Block finance", Order: 5, State: "ENABLED", Action: "BLOCK_DROP", Departments: refs(30)},
+		{ID: 6, Name: "Not US", Order: 6, State: "ENABLED", Action: "BLOCK_DROP", SourceCountries: []string{"COUNTRY_US"}, ExcludeSrcCountries: true},
+		{ID: 7, Name: "Not US or CA", Order: 7, State: "ENABLED", Action: "BLOCK_DROP", Departments: refs(31),
+			SourceCountries: []string{"COUNTRY_US", "COUNTRY_CA"}, ExcludeSrcCountries: true},
+		{ID: 8, Name: "Sales", Order: 8, State: "ENABLED", Action: "BLOCK_DROP", Departments: refs(32)},
+		{ID: 9, Name: "Sales and contractor", Order: 9, State: "ENABLED", Action: "BLOCK_DROP", Users: refs(40), Departments: refs(32)},
+		{ID: 99, Name: "Default", Order: -1, DefaultRule: true, State: "ENABLED", Action: "BLOCK_DROP"},
+	})
+	report := policylint.Lint(&policylint.Snapshot{Rules: rules}, policylint.WithNow(lintNow))
+
+	shadowed := findings(report, policylint.KindShadowed)
+	require.Len(t, shadowed, 2)
+	assert.Equal(t, 2, shadowed[0].Rule.ID)
+	assert.Equal(t, 1, shadowed[0].Related.ID)
+	assert.Equal(t, policylint.SeverityError, shadowed[0].Severity, "a shadowed rule with another action is an error")
+	assert.Equal(t, 7, shadowed[1].Rule.ID, "excluding more countries narrows the rule")
+	assert.Equal(t, policylint.SeverityWarning, shadowed[1].Severity)
+
+	duplicates := findings(report, policylint.KindDuplicate)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, 3, duplicates[0].Rule.ID)
+	assert.Equal(t, 1, duplicates[0].Related.ID)
+
+	for _, f := range report.Findings {
+		assert.NotEqual(t, 5, f.Rule.ID, "a broader rule after a narrower one is not shadowed")
+		assert.NotEqual(t, 9, f.Rule.ID, "users and departments are ORed, so rule 9 also matches the contractor")
+		assert.NotEqual(t, 99, f.Rule.ID, "default rules are not checked")
+	}
+}
+
+func TestPolicyLint_ReferencesStaleAndBroad(t *testing.T) {
+	old := int(lintNow.AddDate(0, 0, -200).Unix())
+	recent := int(lintNow.AddDate(0, 0, -10).Unix())
+	rules := policylint.FromFirewallRules([]filteringrules.FirewallFilteringRules{
+		{ID: 1, Name: "Old", Order: 1, State: "DISABLED", Action: "ALLOW", LastModifiedTime: old},
+		{ID: 2, Name: "Recent", Order: 2, State: "DISABLED", Action: "ALLOW", LastModifiedTime: recent},
+		{ID: 3, Name: "Refs", Order: 3, State: "ENABLED", Action: "BLOCK_DROP", SrcIpGroups: refs(100, 101), DestIpGroups: refs(200), Labels: refs(600)},
+	})
+	rules = append(rules, policylint.FromURLFilteringRules([]urlfilteringpolicies.URLFilteringRule{
+		{ID: 10, Name: "Allow everything", Order: 1, State: "ENABLED", Action: "ALLOW", Protocols: []string{"ANY_RULE"}},
+		{ID: 11, Name: "Allow news", Order: 2, State: "ENABLED", Action: "ALLOW", URLCategories: []string{"NEWS_AND_MEDIA"}},
+	})...)
+	snapshot := &policylint.Snapshot{
+		Rules: rules,
+		Inventory: policylint.Inventory{
+			policylint.KindSourceIPGroups:      {100: 2, 101: 0},
+			policylint.KindDestinationIPGroups: {},
+		},
+	}
+
+	report := policylint.Lint(snapshot, policylint.WithNow(lintNow))
+	assert.Equal(t, 5, report.Rules)
+
+	stale := findings(report, policylint.KindStale)
+	require.Len(t, stale, 1)
+	assert.Equal(t, 1, stale[0].Rule.ID)
+	assert.Equal(t, policylint.SeverityInfo, stale[0].Severity)
+	assert.Empty(t, findings(policylint.Lint(snapshot, policylint.WithNow(lintNow), policylint.WithDisabledAge(0)), policylint.KindStale))
+
+	deleted := findings(report, policylint.KindDeletedRef)
+	require.Len(t, deleted, 1, "labels are not in the inventory and are not checked")
+	assert.Contains(t, deleted[0].Message, "ipDestinationGroups 200")
+	empty := findings(report, policylint.KindEmptyRef)
+	require.Len(t, empty, 1)
+	assert.Contains(t, empty[0].Message, "ipSourceGroups 101")
+
+	broad := findings(report, policylint.KindBroadAllow)
+	require.Len(t, broad, 1)
+	assert.Equal(t, 10, broad[0].Rule.ID)
+	assert.Equal(t, policylint.PolicyURLFiltering, broad[0].Policy)
+
+	assert.True(t, report.HasFindings(policylint.SeverityError))
+	assert.Equal(t, policylint.PolicyFirewall, report.Findings[0].Policy, "findings are sorted by policy")
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"kind":"deleted_reference"`)
+	assert.NotContains(t, string(data), `"related":null`)
+}
+
+func TestPolicyLint_HasFindings(t *testing.T) {
+	report := policylint.Lint(&policylint.Snapshot{}, policylint.WithNow(lintNow))
+	assert.False(t, report.HasFindings(policylint.SeverityInfo))
+	assert.NotNil(t, report.Findings)
+
+	report.Findings = []policylint.Finding{{Kind: policylint.KindStale, Severity: policylint.SeverityInfo}}
+	assert.True(t, report.HasFindings(policylint.SeverityInfo))
+	assert.False(t, report.HasFindings(policylint.SeverityWarning))
+}
+
+func TestPolicyLint_Load(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zia/api/v1/urlFilteringRules", common.SuccessResponse([]urlfilteringpolicies.URLFilteringRule{
+		{ID: 10, Name: "Block gambling", Order: 1, State: "ENABLED", Action: "BLOCK", URLCategories: []string{"GAMBLING"}},
+		{ID: 11, Name: "Block gambling again", Order: 2, State: "ENABLED", Action: "BLOCK", URLCategories: []string{"GAMBLING"}},
+	}))
+	server.On("GET", "/zia/api/v1/firewallFilteringRules", common.SuccessResponse([]filteringrules.FirewallFilteringRules{
+		{ID: 1, Name: "Missing group", Order: 1, State: "ENABLED", Action: "BLOCK_DROP", NwServiceGroups: refs(400)},
+	}))
+	server.On("GET", "/zia/api/v1/ipSourceGroups", common.SuccessResponse([]ipsourcegroups.IPSourceGroups{}))
+	server.On("GET", "/zia/api/v1/ipDestinationGroups", common.SuccessResponse([]ipdestinationgroups.IPDestinationGroups{}))
+	server.On("GET", "/zia/api/v1/networkServices", common.SuccessResponse([]networkservices.NetworkServices{}))
+	server.On("GET", "/zia/api/v1/networkServiceGroups", common.SuccessResponse([]networkservicegroups.NetworkServiceGroups{}))
+	server.On("GET", "/zia/api/v1/timeWindows", common.SuccessResponse([]timewindow.TimeWindow{}))
+	server.On("GET", "/zia/api/v1/ruleLabels", common.SuccessResponse([]rule_labels.RuleLabels{}))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	snapshot, err := policylint.Load(context.Background(), service, policylint.PolicyURLFiltering, policylint.PolicyFirewall)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Rules, 3)
+	assert.Contains(t, snapshot.Inventory, policylint.KindNetworkServiceGroups)
+
+	report := policylint.Lint(snapshot, policylint.WithNow(lintNow))
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, policylint.KindDeletedRef, report.Findings[0].Kind)
+	assert.Equal(t, policylint.KindDuplicate, report.Findings[1].Kind)
+	assert.Equal(t, 11, report.Findings[1].Rule.ID)
+
+	_, err = policylint.Load(context.Background(), service, "unknown")
+	assert.Error(t, err)
+}
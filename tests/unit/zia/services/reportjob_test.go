@@ -0,0 +1,219 @@
+// Package services provides unit tests for ZIA services
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/adminauditlogs"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/reportjob"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/shadowitreport"
+)
+
+// fakeReportTask reports the statuses in order, repeating the last one.
+type fakeReportTask struct {
+	statuses  []reportjob.Status
+	report    string
+	deleteErr error
+
+	starts  []reportjob.Window
+	reads   int
+	deletes int
+}
+
+func (t *fakeReportTask) Start(ctx context.Context, window reportjob.Window) error {
+	t.starts = append(t.starts, window)
+	t.reads = 0
+	return nil
+}
+
+func (t *fakeReportTask) Status(ctx context.Context) (*reportjob.Status, error) {
+	status := t.statuses[min(t.reads, len(t.statuses)-1)]
+	t.reads++
+	return &status, nil
+}
+
+func (t *fakeReportTask) Download(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(t.report)), nil
+}
+
+func (t *fakeReportTask) Delete(ctx context.Context) error {
+	t.deletes++
+	return t.deleteErr
+}
+
+type auditRecord struct {
+	Time   time.Time `csv:"Timestamp|Time"`
+	Admin  string    `csv:"Admin|Admin Login Name"`
+	Action string    `csv:"Action"`
+	Items  int       `csv:"Items"`
+	Note   *string   `csv:"Note"`
+}
+
+const auditReport = "Report,Audit Logs\nGenerated,2026-10-01\n\n" +
+	"\uFEFFTime,Admin Login Name,action,Items,Note,Unknown\n" +
+	"2026-10-01 10:00:00,alice@example.com,\"CREATE, bulk\",\"1,200\",,x\n" +
+	"1790000000000,bob@example.com,DELETE,3,\"said \"\"hi\"\"\",y\n"
+
+var fastPoll = reportjob.WithPollInterval(time.Millisecond, 2*time.Millisecond)
+
+func TestReportJob_PollsDownloadsAndDeletes(t *testing.T) {
+	task := &fakeReportTask{
+		statuses: []reportjob.Status{{State: "EXECUTING"}, {State: "EXECUTING", ItemsComplete: 10}, {State: "COMPLETE", ItemsComplete: 20}},
+		report:   auditReport,
+	}
+	var progress []reportjob.Progress
+	var records []auditRecord
+
+	err := reportjob.Run(context.Background(), task, reportjob.Decode(func(r auditRecord) error {
+		records = append(records, r)
+		return nil
+	}), fastPoll, reportjob.WithProgress(func(p reportjob.Progress) { progress = append(progress, p) }))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, task.deletes)
+	require.Len(t, progress, 3)
+	assert.Equal(t, 20, progress[2].Status.ItemsComplete)
+	assert.Equal(t, 1, progress[2].Parts)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), records[0].Time)
+	assert.Equal(t, "alice@example.com", records[0].Admin)
+	assert.Equal(t, "CREATE, bulk", records[0].Action)
+	assert.Equal(t, 1200, records[0].Items)
+	assert.Nil(t, records[0].Note)
+	assert.Equal(t, time.UnixMilli(1790000000000).UTC(), records[1].Time)
+	require.NotNil(t, records[1].Note)
+	assert.Equal(t, `said "hi"`, *records[1].Note)
+}
+
+func TestReportJob_SplitsLongRanges(t *testing.T) {
+	task := &fakeReportTask{statuses: []reportjob.Status{{State: "COMPLETE"}}}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(70 * 24 * time.Hour)
+	var handled []reportjob.Window
+
+	err := reportjob.Run(context.Background(), task, func(w reportjob.Window, _ io.Reader) error {
+		handled = append(handled, w)
+		return nil
+	}, fastPoll, reportjob.WithRange(start, end))
+	require.NoError(t, err)
+
+	require.Len(t, task.starts, 3)
+	assert.Equal(t, task.starts, handled)
+	assert.Equal(t, start, task.starts[0].Start)
+	assert.Equal(t, start.Add(30*24*time.Hour-time.Millisecond), task.starts[0].End)
+	assert.Equal(t, start.Add(30*24*time.Hour), task.starts[1].Start)
+	assert.Equal(t, end, task.starts[2].End)
+	assert.Equal(t, 3, task.deletes)
+}
+
+func TestReportJob_Failures(t *testing.T) {
+	task := &fakeReportTask{statuses: []reportjob.Status{{State: "EXECUTING"}, {State: "EXECUTING", ErrorCode: "INVALID_INPUT"}}}
+	err := reportjob.Run(context.Background(), task, reportjob.Decode(func(auditRecord) error { return nil }), fastPoll)
+	assert.ErrorIs(t, err, reportjob.ErrTaskFailed)
+	assert.ErrorContains(t, err, "INVALID_INPUT")
+	assert.Equal(t, 1, task.deletes, "failed tasks are deleted")
+
+	task = &fakeReportTask{statuses: []reportjob.Status{{State: "CANCELLED"}}}
+	err = reportjob.Run(context.Background(), task, nil, fastPoll)
+	assert.ErrorIs(t, err, reportjob.ErrTaskCancelled)
+
+	handlerErr := errors.New("warehouse down")
+	task = &fakeReportTask{statuses: []reportjob.Status{{State: "COMPLETE"}}, report: auditReport, deleteErr: errors.New("409")}
+	err = reportjob.Run(context.Background(), task, reportjob.Decode(func(auditRecord) error { return handlerErr }), fastPoll)
+	assert.ErrorIs(t, err, handlerErr, "the handler error wins over the cleanup error")
+	assert.NotErrorIs(t, err, reportjob.ErrCleanup)
+
+	task = &fakeReportTask{statuses: []reportjob.Status{{State: "COMPLETE"}}, deleteErr: errors.New("409")}
+	err = reportjob.Run(context.Background(), task, func(reportjob.Window, io.Reader) error { return nil }, fastPoll)
+	assert.ErrorIs(t, err, reportjob.ErrCleanup)
+}
+
+func TestReportJob_CancelDeletesTask(t *testing.T) {
+	task := &fakeReportTask{statuses: []reportjob.Status{{State: "EXECUTING"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := reportjob.Run(ctx, task, nil, reportjob.WithPollInterval(time.Hour, time.Hour),
+		reportjob.WithProgress(func(reportjob.Progress) { cancel() }))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, task.deletes)
+}
+
+func TestReportJob_DecodeErrors(t *testing.T) {
+	handle := reportjob.Decode(func(auditRecord) error { return nil })
+
+	assert.NoError(t, handle(reportjob.Window{}, strings.NewReader("")), "an empty report has no records")
+	assert.ErrorIs(t, handle(reportjob.Window{}, strings.NewReader("a,b\n1,2\n")), csvx.ErrNoHeader)
+
+	err := handle(reportjob.Window{}, strings.NewReader("Time,Admin,Items\nyesterday,alice,1\n"))
+	var parseErr *csvx.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Equal(t, "Time", parseErr.Column)
+}
+
+func TestReportJob_AdminAuditLogsTask(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("POST", "/zia/api/v1/auditlogEntryReport", common.NoContentResponse())
+	server.On("GET", "/zia/api/v1/auditlogEntryReport", common.SuccessResponse(adminauditlogs.AuditLogEntryReportTaskInfo{Status: "COMPLETE", ProgressItemsComplete: 2}))
+	server.On("GET", "/zia/api/v1/auditlogEntryReport/download", common.CSVResponse(auditReport))
+	server.On("DELETE", "/zia/api/v1/auditlogEntryReport", common.NoContentResponse())
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	var admins []string
+	task := reportjob.AdminAuditLogs(service, adminauditlogs.AuditLogEntryRequest{ActionResult: "SUCCESS"})
+
+	err = reportjob.Run(context.Background(), task, reportjob.Decode(func(r auditRecord) error {
+		admins = append(admins, r.Admin)
+		return nil
+	}), fastPoll, reportjob.WithRange(start, end))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, admins)
+
+	var created adminauditlogs.AuditLogEntryRequest
+	for _, req := range server.Handler.Requests {
+		if req.Method == "POST" {
+			require.NoError(t, json.Unmarshal(req.Body, &created))
+		}
+	}
+	assert.Equal(t, int(start.UnixMilli()), created.StartTime)
+	assert.Equal(t, int(end.UnixMilli()), created.EndTime)
+	assert.Equal(t, "SUCCESS", created.ActionResult)
+	assert.Equal(t, 1, server.GetCallCount("DELETE", "/zia/api/v1/auditlogEntryReport"))
+}
+
+func TestReportJob_ShadowITApplicationsTask(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("POST", "/zia/api/v1/shadowIT/applications/export", common.CSVResponse("Application,Risk Index\nDropbox,3\n"))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var report string
+	err = reportjob.Run(context.Background(), reportjob.ShadowITApplications(service, shadowitreport.CloudApplicationsExport{Duration: "LAST_1_DAYS"}),
+		func(_ reportjob.Window, r io.Reader) error {
+			data, err := io.ReadAll(r)
+			report = string(data)
+			return err
+		}, fastPoll)
+	require.NoError(t, err)
+	assert.Equal(t, "Application,Risk Index\nDropbox,3\n", report)
+}
@@ -0,0 +1,203 @@
+// Package services provides unit tests for ZIA services
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/ruleorder"
+)
+
+// fakeOrderedPolicy keeps rules in position order and moves them the way ZIA
+// does: a rule updated to order N is inserted at position N.
+type fakeOrderedPolicy struct {
+	rules    []ruleorder.Rule
+	failOn   map[int]error
+	stuck    map[int]bool
+	moveCall int
+}
+
+func newFakeOrderedPolicy(rules ...ruleorder.Rule) *fakeOrderedPolicy {
+	p := &fakeOrderedPolicy{rules: rules, failOn: map[int]error{}, stuck: map[int]bool{}}
+	p.renumber()
+	return p
+}
+
+func (p *fakeOrderedPolicy) renumber() {
+	for i := range p.rules {
+		if p.rules[i].DefaultRule {
+			p.rules[i].Order = -1
+		} else {
+			p.rules[i].Order = i + 1
+		}
+	}
+}
+
+func (p *fakeOrderedPolicy) names() []string {
+	var out []string
+	for _, r := range p.rules {
+		out = append(out, r.Name)
+	}
+	return out
+}
+
+func (p *fakeOrderedPolicy) adapter() *ruleorder.Adapter[ruleorder.Rule] {
+	return &ruleorder.Adapter[ruleorder.Rule]{
+		Policy: "fake",
+		List: func(ctx context.Context) ([]ruleorder.Rule, error) {
+			return slices.Clone(p.rules), nil
+		},
+		Get: func(ctx context.Context, id int) (*ruleorder.Rule, error) {
+			for _, r := range p.rules {
+				if r.ID == id {
+					return &r, nil
+				}
+			}
+			return nil, errors.New("not found")
+		},
+		Update: func(ctx context.Context, rule *ruleorder.Rule, order int) error {
+			p.moveCall++
+			if err := p.failOn[p.moveCall]; err != nil {
+				return err
+			}
+			if p.stuck[rule.ID] {
+				return nil
+			}
+			i := slices.IndexFunc(p.rules, func(r ruleorder.Rule) bool { return r.ID == rule.ID })
+			moved := p.rules[i]
+			p.rules = slices.Insert(slices.Delete(p.rules, i, i+1), order-1, moved)
+			p.renumber()
+			return nil
+		},
+		Describe: func(r *ruleorder.Rule) ruleorder.Rule {
+			return *r
+		},
+	}
+}
+
+func orderedRules() []ruleorder.Rule {
+	return []ruleorder.Rule{
+		{ID: 100, Name: "Predefined", Predefined: true},
+		{ID: 1, Name: "A", Rank: 7},
+		{ID: 2, Name: "B", Rank: 7},
+		{ID: 3, Name: "C", Rank: 7},
+		{ID: 4, Name: "D", Rank: 7},
+		{ID: 5, Name: "E", Rank: 7},
+		{ID: 999, Name: "Default", DefaultRule: true},
+	}
+}
+
+func TestRuleOrder_PlanUsesFewestMoves(t *testing.T) {
+	rules := newFakeOrderedPolicy(orderedRules()...).rules
+
+	plan, err := ruleorder.NewPlan("fake", rules, ruleorder.ByName("B", "C", "D", "E", "A"))
+	require.NoError(t, err)
+	require.Len(t, plan.Moves, 1, "only A has to move")
+	assert.Equal(t, ruleorder.Move{ID: 1, Name: "A", From: 2, To: 6}, plan.Moves[0])
+
+	plan, err = ruleorder.NewPlan("fake", rules, ruleorder.ByName("E", "D", "C", "B", "A"))
+	require.NoError(t, err)
+	assert.Len(t, plan.Moves, 4)
+	assert.Equal(t, 2, plan.Moves[0].To, "moves never take the slot of the predefined rule")
+
+	plan, err = ruleorder.NewPlan("fake", rules, ruleorder.ByID(3))
+	require.NoError(t, err)
+	assert.Equal(t, []ruleorder.Move{{ID: 3, Name: "C", From: 4, To: 2}}, plan.Moves, "unlisted rules follow in their current order")
+
+	plan, err = ruleorder.NewPlan("fake", rules, ruleorder.ByName("A", "B"))
+	require.NoError(t, err)
+	assert.Empty(t, plan.Moves)
+}
+
+func TestRuleOrder_PlanErrors(t *testing.T) {
+	rules := newFakeOrderedPolicy(orderedRules()...).rules
+
+	_, err := ruleorder.NewPlan("fake", rules, ruleorder.ByName("Z"))
+	assert.ErrorIs(t, err, ruleorder.ErrUnknownRule)
+	_, err = ruleorder.NewPlan("fake", rules, ruleorder.ByName("Default", "A"))
+	assert.ErrorIs(t, err, ruleorder.ErrFixedRule)
+	_, err = ruleorder.NewPlan("fake", rules, ruleorder.ByID(100))
+	assert.ErrorIs(t, err, ruleorder.ErrFixedRule)
+	_, err = ruleorder.NewPlan("fake", rules, []ruleorder.Ref{{ID: 1}, {Name: "A"}})
+	assert.ErrorContains(t, err, "listed twice")
+
+	rules[2].Rank = 0 // B belongs to a rank 0 admin and must stay above rank 7 rules
+	_, err = ruleorder.NewPlan("fake", rules, ruleorder.ByName("A", "B"))
+	assert.ErrorIs(t, err, ruleorder.ErrRankConflict)
+	_, err = ruleorder.NewPlan("fake", rules, ruleorder.ByName("A", "B"), ruleorder.WithRankCheck(false))
+	assert.NoError(t, err)
+}
+
+func TestRuleOrder_Apply(t *testing.T) {
+	policy := newFakeOrderedPolicy(orderedRules()...)
+
+	result, err := ruleorder.Apply(context.Background(), policy.adapter(), ruleorder.ByName("E", "A", "C", "B", "D"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Predefined", "E", "A", "C", "B", "D", "Default"}, policy.names())
+	assert.Equal(t, 1, result.Attempts)
+	assert.Len(t, result.Moves, 2)
+
+	result, err = ruleorder.Apply(context.Background(), policy.adapter(), ruleorder.ByName("E", "A"))
+	require.NoError(t, err)
+	assert.Zero(t, result.Attempts, "nothing to do")
+}
+
+func TestRuleOrder_ApplyRetriesThenSucceeds(t *testing.T) {
+	policy := newFakeOrderedPolicy(orderedRules()...)
+	policy.failOn[1] = errors.New("transient")
+
+	result, err := ruleorder.Apply(context.Background(), policy.adapter(), ruleorder.ByName("B", "C", "D", "E", "A"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, []string{"Predefined", "B", "C", "D", "E", "A", "Default"}, policy.names())
+}
+
+func TestRuleOrder_ApplyRestoresOnFailure(t *testing.T) {
+	policy := newFakeOrderedPolicy(orderedRules()...)
+	moveErr := errors.New("boom")
+	policy.failOn[2] = moveErr
+
+	_, err := ruleorder.Apply(context.Background(), policy.adapter(), ruleorder.ByName("E", "D", "C", "B", "A"), ruleorder.WithAttempts(1))
+	require.ErrorIs(t, err, moveErr)
+	assert.Equal(t, []string{"Predefined", "A", "B", "C", "D", "E", "Default"}, policy.names(), "the first move is undone")
+
+	policy = newFakeOrderedPolicy(orderedRules()...)
+	policy.stuck[1] = true
+	_, err = ruleorder.Apply(context.Background(), policy.adapter(), ruleorder.ByName("B", "C", "D", "E", "A"))
+	require.ErrorIs(t, err, ruleorder.ErrNotConverged)
+	assert.Equal(t, []string{"Predefined", "A", "B", "C", "D", "E", "Default"}, policy.names())
+}
+
+func TestRuleOrder_FirewallFilteringAdapter(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", "/zia/api/v1/firewallFilteringRules", common.SuccessResponse([]filteringrules.FirewallFilteringRules{
+		{ID: 1, Name: "Allow web", Order: 1, Rank: 7},
+		{ID: 2, Name: "Block all", Order: 2, Rank: 7},
+		{ID: 9, Name: "Default", Order: -1, DefaultRule: true},
+	}))
+	server.On("GET", "/zia/api/v1/firewallFilteringRules/2", common.SuccessResponse(filteringrules.FirewallFilteringRules{ID: 2, Name: "Block all", Order: 2, Rank: 7}))
+	server.On("PUT", "/zia/api/v1/firewallFilteringRules/2", common.SuccessResponse(filteringrules.FirewallFilteringRules{ID: 2, Name: "Block all", Order: 1, Rank: 7}))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+	policy := ruleorder.FirewallFiltering(service)
+
+	plan, err := ruleorder.PlanFor(context.Background(), policy, ruleorder.ByName("Block all"))
+	require.NoError(t, err)
+	require.Equal(t, []ruleorder.Move{{ID: 2, Name: "Block all", From: 2, To: 1}}, plan.Moves)
+
+	require.NoError(t, policy.Move(context.Background(), 2, 1))
+	var body filteringrules.FirewallFilteringRules
+	require.NoError(t, json.Unmarshal(server.LastRequest().Body, &body))
+	assert.Equal(t, 1, body.Order)
+	assert.Equal(t, "Block all", body.Name, "the update sends the whole rule")
+}
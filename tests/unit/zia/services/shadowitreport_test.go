@@ -0,0 +1,71 @@
+// Package services provides unit tests for ZIA services
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/shadowitreport"
+)
+
+func TestShadowITReport_IterCloudApplicationsExport_SDK(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	csvData := "\uFEFFApplication Name,Category,Sanctioned State,Risk Score,Total Bytes,Upload Bytes,Download Bytes,Number of Users,Transactions,Custom Tags,Compliance\r\n" +
+		"Dropbox,File Sharing,Sanctioned,3,\"1,048,576\",1024,1047552,12,340,\"Storage, Approved\",SOC2\r\n" +
+		"\"Acme \"\"Notes\"\"\",Productivity,Unsanctioned,5,200,N/A,200,1,--,,\r\n"
+	server.On("POST", "/zia/api/v1/shadowIT/applications/export", common.CSVResponse(csvData))
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	var records []shadowitreport.CloudApplicationRecord
+	for record, err := range shadowitreport.IterCloudApplicationsExport(context.Background(), service, shadowitreport.CloudApplicationsExport{Duration: "LAST_7_DAYS"}) {
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, shadowitreport.CloudApplicationRecord{
+		Application:         "Dropbox",
+		ApplicationCategory: "File Sharing",
+		SanctionedState:     "Sanctioned",
+		RiskIndex:           3,
+		DataConsumed:        1048576,
+		UploadBytes:         1024,
+		DownloadBytes:       1047552,
+		Users:               12,
+		Transactions:        340,
+		CustomTags:          "Storage, Approved",
+		Extra:               map[string]string{"Compliance": "SOC2"},
+	}, records[0])
+	assert.Equal(t, `Acme "Notes"`, records[1].Application)
+	assert.Zero(t, records[1].UploadBytes)
+	assert.Zero(t, records[1].Transactions)
+
+	var request shadowitreport.CloudApplicationsExport
+	require.NoError(t, json.Unmarshal(server.LastRequest().Body, &request))
+	assert.Equal(t, "LAST_7_DAYS", request.Duration)
+}
+
+func TestShadowITReport_IterCloudApplicationsExport_Error(t *testing.T) {
+	server := common.NewTestServer()
+	defer server.Close()
+
+	server.On("POST", "/zia/api/v1/shadowIT/applications/export", common.MockResponse{StatusCode: 400, Body: `{"code":"INVALID_INPUT_ARGUMENT","message":"bad duration"}`})
+
+	service, err := common.CreateTestService(context.Background(), server, "123456")
+	require.NoError(t, err)
+
+	calls := 0
+	for _, err := range shadowitreport.IterCloudApplicationsExport(context.Background(), service, shadowitreport.CloudApplicationsExport{}) {
+		calls++
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, calls)
+}
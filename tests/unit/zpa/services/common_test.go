@@ -2,9 +2,12 @@
 package unit
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testcommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/tests/unit/common"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/common"
 )
 
@@ -179,3 +182,80 @@ func TestCommon_Pagination_Structure(t *testing.T) {
 	})
 }
 
+func TestCommon_GetAllPagesGenericWithCustomFilters_MicroTenantName(t *testing.T) {
+	basePath := "/zpa/mgmtconfig/v1/admin/customers/" + testCustomerID
+	microTenantName := "Finance"
+
+	t.Run("resolves the name to the micro-tenant ID", func(t *testing.T) {
+		server := testcommon.NewTestServer()
+		defer server.Close()
+
+		server.On("GET", basePath+"/microtenants", testcommon.SuccessResponse(map[string]interface{}{
+			"totalPages": "1",
+			"list":       []map[string]interface{}{{"id": "mt-9", "name": "Finance"}},
+		}))
+		server.On("GET", basePath+"/segmentGroup", testcommon.SuccessResponse(map[string]interface{}{
+			"totalPages": "1",
+			"list":       []map[string]interface{}{{"id": "sg-1", "name": "Group 1"}},
+		}))
+
+		service, err := testcommon.CreateTestService(context.Background(), server, testCustomerID)
+		require.NoError(t, err)
+
+		result, _, err := common.GetAllPagesGenericWithCustomFilters[map[string]interface{}](context.Background(), service.Client, basePath+"/segmentGroup", common.Filter{MicroTenantName: &microTenantName})
+
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, 1, server.GetCallCount("GET", basePath+"/segmentGroup"))
+		assert.Contains(t, server.LastRequest().Query, "microtenantId=mt-9")
+	})
+
+	t.Run("returns the lookup error", func(t *testing.T) {
+		server := testcommon.NewTestServer()
+		defer server.Close()
+
+		server.On("GET", basePath+"/microtenants", testcommon.SuccessResponse(map[string]interface{}{
+			"totalPages": "1",
+			"list":       []map[string]interface{}{},
+		}))
+		server.On("GET", basePath+"/segmentGroup", testcommon.SuccessResponse(map[string]interface{}{
+			"totalPages": "1",
+			"list":       []map[string]interface{}{{"id": "sg-1", "name": "Group 1"}},
+		}))
+
+		service, err := testcommon.CreateTestService(context.Background(), server, testCustomerID)
+		require.NoError(t, err)
+
+		_, _, err = common.GetAllPagesGenericWithCustomFilters[map[string]interface{}](context.Background(), service.Client, basePath+"/segmentGroup", common.Filter{MicroTenantName: &microTenantName})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no microtenant named 'Finance' was found")
+		assert.Equal(t, 0, server.GetCallCount("GET", basePath+"/segmentGroup"), "the list is not read without the micro-tenant")
+	})
+}
+
+func TestCommon_IterAllPagesGenericWithCustomFilters_LazyMicroTenant(t *testing.T) {
+	basePath := "/zpa/mgmtconfig/v1/admin/customers/" + testCustomerID
+	microTenantName := "Finance"
+	server := testcommon.NewTestServer()
+	defer server.Close()
+
+	server.On("GET", basePath+"/microtenants", testcommon.SuccessResponse(map[string]interface{}{
+		"totalPages": "1",
+		"list":       []map[string]interface{}{},
+	}))
+
+	service, err := testcommon.CreateTestService(context.Background(), server, testCustomerID)
+	require.NoError(t, err)
+
+	items := common.IterAllPagesGenericWithCustomFilters[map[string]interface{}](context.Background(), service.Client, basePath+"/segmentGroup", common.Filter{MicroTenantName: &microTenantName})
+	assert.Equal(t, 0, server.GetCallCount("GET", basePath+"/microtenants"), "nothing is fetched before iterating")
+
+	var errs []error
+	for _, err := range items {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "no microtenant named 'Finance' was found")
+	assert.Equal(t, 1, server.GetCallCount("GET", basePath+"/microtenants"))
+}
@@ -0,0 +1,318 @@
+// Package zscaler provides unit tests for the ZIA and ZTW activation manager
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/activation"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	ztwactivation "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/ztw/services/activation"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/ztw/services/policyresources/ipgroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+const ziaActivatePath = "/zia/api/v1/status/activate"
+
+// eventLog collects activation events.
+type eventLog struct {
+	mu     sync.Mutex
+	events []zscaler.ActivationEvent
+}
+
+func (l *eventLog) add(e zscaler.ActivationEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) types() []zscaler.ActivationEventType {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]zscaler.ActivationEventType, 0, len(l.events))
+	for _, e := range l.events {
+		out = append(out, e.Type)
+	}
+	return out
+}
+
+func createLabel(t *testing.T, service *zscaler.Service, name string) {
+	t.Helper()
+	_, _, err := rule_labels.Create(context.Background(), service, &rule_labels.RuleLabels{Name: name})
+	require.NoError(t, err)
+}
+
+func TestActivationManager_Commit(t *testing.T) {
+	srv := zscalertest.NewServer(zscalertest.WithActivationDelay(2))
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	events := &eventLog{}
+	manager := activation.NewManager(service,
+		zscaler.WithActivationPollInterval(time.Millisecond),
+		zscaler.WithActivationEventHandler(events.add))
+	defer manager.Close(context.Background())
+	ctx := context.Background()
+
+	assert.Equal(t, zscaler.ActivationIdle, manager.State())
+	require.NoError(t, manager.Commit(ctx))
+	assert.Zero(t, srv.Activations("zia"), "nothing to activate")
+
+	createLabel(t, service, "a")
+	createLabel(t, service, "b")
+	_, err := rule_labels.GetAll(ctx, service)
+	require.NoError(t, err)
+	assert.Equal(t, 2, manager.Pending(), "reads are not tracked")
+	assert.Equal(t, zscaler.ActivationPending, manager.State())
+	assert.True(t, srv.ActivationPending("zia"))
+
+	require.NoError(t, manager.Commit(ctx))
+	assert.Equal(t, 1, srv.Activations("zia"), "both changes are activated at once")
+	assert.False(t, srv.ActivationPending("zia"))
+	assert.Equal(t, 0, manager.Pending())
+	assert.Equal(t, zscaler.ActivationIdle, manager.State())
+	assert.Equal(t, 3, countRequests(srv, http.MethodGet, "/zia/api/v1/status"), "one read before activating, then polled until it finished")
+	assert.Equal(t, []zscaler.ActivationEventType{
+		zscaler.ActivationEventTracked, zscaler.ActivationEventTracked,
+		zscaler.ActivationEventStarted, zscaler.ActivationEventSucceeded,
+	}, events.types())
+	assert.Equal(t, "ACTIVE", events.events[3].Status)
+	assert.Equal(t, 2, events.events[3].Pending)
+}
+
+func TestActivationManager_StatusBypassesCache(t *testing.T) {
+	srv := zscalertest.NewServer(zscalertest.WithActivationDelay(2))
+	defer srv.Close()
+	service := newFakeService(t, srv, zscaler.WithCache(true))
+	manager := activation.NewManager(service, zscaler.WithActivationPollInterval(time.Millisecond))
+	defer manager.Close(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createLabel(t, service, "a")
+	require.NoError(t, manager.Commit(ctx), "polling sees the activation finish")
+	assert.Equal(t, 1, srv.Activations("zia"))
+	assert.Equal(t, 3, countRequests(srv, http.MethodGet, "/zia/api/v1/status"))
+}
+
+func TestActivationManager_JournaledChanges(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service)
+	defer manager.Close(context.Background())
+	journal, err := zscaler.NewJournal("")
+	require.NoError(t, err)
+
+	ctx := zscaler.WithJournal(context.Background(), journal)
+	_, _, err = rule_labels.Create(ctx, service, &rule_labels.RuleLabels{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, manager.Pending(), "a journaled change is tracked once")
+	assert.Len(t, journal.Entries(), 1)
+}
+
+func TestActivationManager_Debounce(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	done := make(chan zscaler.ActivationEvent, 4)
+	manager := activation.NewManager(service,
+		zscaler.WithActivationDebounce(50*time.Millisecond),
+		zscaler.WithActivationEventHandler(func(e zscaler.ActivationEvent) {
+			if e.Type == zscaler.ActivationEventSucceeded {
+				done <- e
+			}
+		}))
+	defer manager.Close(context.Background())
+
+	for _, name := range []string{"a", "b", "c"} {
+		createLabel(t, service, name)
+	}
+	select {
+	case e := <-done:
+		assert.Equal(t, 3, e.Pending, "the changes are batched")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the debounced activation did not run")
+	}
+	assert.Equal(t, 1, srv.Activations("zia"))
+	assert.NoError(t, manager.Err())
+}
+
+func TestActivationManager_MaxDelay(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service,
+		zscaler.WithActivationDebounce(time.Hour),
+		zscaler.WithActivationMaxDelay(20*time.Millisecond))
+	defer manager.Close(context.Background())
+
+	createLabel(t, service, "a")
+	assert.Eventually(t, func() bool { return srv.Activations("zia") == 1 }, 5*time.Second, 5*time.Millisecond,
+		"the maximum delay caps the debounce")
+}
+
+func TestActivationManager_ActivateOnClose(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service, zscaler.WithActivateOnClose(true))
+
+	createLabel(t, service, "a")
+	require.NoError(t, manager.Close(context.Background()))
+	assert.Equal(t, 1, srv.Activations("zia"))
+	assert.Equal(t, zscaler.ActivationClosed, manager.State())
+
+	createLabel(t, service, "b")
+	assert.Zero(t, manager.Pending(), "a closed manager tracks nothing")
+}
+
+func TestActivationManager_WaitsForOtherAdmin(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	events := &eventLog{}
+	manager := activation.NewManager(service,
+		zscaler.WithActivationPollInterval(time.Millisecond),
+		zscaler.WithActivationEventHandler(events.add))
+	defer manager.Close(context.Background())
+
+	createLabel(t, service, "a")
+	srv.StartAdminActivation("zia", 3)
+	srv.SetOtherAdminEdits("zia", true)
+	require.NoError(t, manager.Commit(context.Background()))
+
+	var statusReads, activateBeforeSettled int
+	for _, r := range srv.Requests() {
+		switch {
+		case r.Method == http.MethodGet && r.Path == "/zia/api/v1/status":
+			statusReads++
+		case r.Method == http.MethodPost && r.Path == ziaActivatePath && statusReads < 4:
+			activateBeforeSettled++
+		}
+	}
+	assert.Zero(t, activateBeforeSettled, "the activation waits for the running one")
+	succeeded := events.events[len(events.events)-1]
+	assert.Equal(t, zscaler.ActivationEventSucceeded, succeeded.Type)
+	assert.Equal(t, "PENDING", succeeded.Status)
+	assert.True(t, succeeded.OthersPending, "edits of the other admin are reported")
+}
+
+func TestActivationManager_Eusa(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service)
+	defer manager.Close(context.Background())
+	ctx := context.Background()
+
+	srv.SetEusaAccepted(false)
+	createLabel(t, service, "a")
+	err := manager.Commit(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, activation.ErrEusaNotAccepted))
+	assert.Equal(t, 1, manager.Pending(), "the changes stay pending")
+	assert.Equal(t, err, manager.Err())
+	assert.Zero(t, countRequests(srv, http.MethodPost, ziaActivatePath))
+
+	accepting := zscaler.NewActivationManager(service, &activation.Backend{Service: service, AcceptEusa: true})
+	defer accepting.Close(ctx)
+	require.NoError(t, accepting.Activate(ctx))
+	assert.Equal(t, 1, countRequests(srv, http.MethodPut, "/zia/api/v1/eusaStatus/1"))
+	assert.Equal(t, 1, srv.Activations("zia"))
+}
+
+func TestActivationManager_FailedActivationStaysPending(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service)
+	defer manager.Close(context.Background())
+
+	createLabel(t, service, "a")
+	srv.InjectFault(zscalertest.Fault{Method: http.MethodPost, PathPrefix: ziaActivatePath, Times: 1, StatusCode: http.StatusBadRequest, Body: `{"code":"INVALID_INPUT_ARGUMENT","message":"activation rejected"}`})
+	require.Error(t, manager.Commit(context.Background()))
+	assert.Equal(t, 1, manager.Pending())
+	assert.Equal(t, zscaler.ActivationPending, manager.State())
+
+	require.NoError(t, manager.Commit(context.Background()))
+	assert.Zero(t, manager.Pending())
+	assert.NoError(t, manager.Err())
+}
+
+func TestActivationManager_DebouncedRetryAfterFailure(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	events := &eventLog{}
+	manager := activation.NewManager(service,
+		zscaler.WithActivationDebounce(20*time.Millisecond),
+		zscaler.WithActivationEventHandler(events.add))
+	defer manager.Close(context.Background())
+
+	srv.InjectFault(zscalertest.Fault{Method: http.MethodPost, PathPrefix: ziaActivatePath, Times: 1, StatusCode: http.StatusBadRequest, Body: `{"code":"INVALID_INPUT_ARGUMENT","message":"activation rejected"}`})
+	createLabel(t, service, "a")
+
+	assert.Eventually(t, func() bool { return srv.Activations("zia") == 1 }, 5*time.Second, 5*time.Millisecond,
+		"the failed activation is retried without a new change")
+	assert.Eventually(t, func() bool { return manager.State() == zscaler.ActivationIdle }, 5*time.Second, 5*time.Millisecond)
+	assert.NoError(t, manager.Err())
+	assert.Equal(t, 2, countRequests(srv, http.MethodPost, ziaActivatePath))
+	assert.Equal(t, []zscaler.ActivationEventType{
+		zscaler.ActivationEventTracked,
+		zscaler.ActivationEventStarted, zscaler.ActivationEventFailed,
+		zscaler.ActivationEventStarted, zscaler.ActivationEventSucceeded,
+	}, events.types())
+}
+
+func TestActivationManager_ConcurrentCommits(t *testing.T) {
+	srv := zscalertest.NewServer(zscalertest.WithActivationDelay(5))
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	manager := activation.NewManager(service, zscaler.WithActivationPollInterval(time.Millisecond))
+	defer manager.Close(context.Background())
+
+	createLabel(t, service, "a")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, manager.Commit(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, srv.Activations("zia"), "concurrent commits share one activation")
+}
+
+func TestActivationManager_ZTW(t *testing.T) {
+	srv := zscalertest.NewServer(zscalertest.WithActivationDelay(1))
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	events := &eventLog{}
+	manager := ztwactivation.NewManager(service,
+		zscaler.WithActivationPollInterval(time.Millisecond),
+		zscaler.WithActivationEventHandler(events.add))
+	defer manager.Close(context.Background())
+	ziaManager := activation.NewManager(service)
+	defer ziaManager.Close(context.Background())
+	ctx := context.Background()
+
+	_, _, err := ipgroups.Create(ctx, service, &ipgroups.IPGroups{Name: "group", IPAddresses: []string{"10.0.0.1"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, manager.Pending())
+	assert.Zero(t, ziaManager.Pending(), "a manager only tracks its own product")
+
+	srv.SetOtherAdminEdits("ztw", true)
+	require.NoError(t, manager.Commit(ctx))
+	assert.Equal(t, 1, srv.Activations("ztw"))
+	succeeded := events.events[len(events.events)-1]
+	assert.Equal(t, ztwactivation.StatusActivateDone, succeeded.Status)
+	assert.True(t, succeeded.OthersPending)
+}
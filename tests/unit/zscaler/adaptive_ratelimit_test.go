@@ -0,0 +1,53 @@
+// Package zscaler provides unit tests for the OneAPI adaptive rate limiter
+package zscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/ratelimiter"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func TestAdaptiveRateLimit_State(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	fixed := newFakeService(t, srv)
+	assert.Empty(t, fixed.Client.RateLimitState())
+
+	service := newFakeService(t, srv, zscaler.WithAdaptiveRateLimit(true))
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+
+	states := service.Client.RateLimitState()
+	require.Contains(t, states, "zia")
+	require.Len(t, states["zia"], 1)
+	assert.Equal(t, ratelimiter.MethodClassRead, states["zia"][0].Class)
+	// The fake server sends no rate-limit headers, so the fixed window stays in charge.
+	assert.False(t, states["zia"][0].Adaptive)
+}
+
+func TestSharedRateLimit_KeyedByTenantProductAndClass(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	kv := ratelimiter.NewMemoryKV()
+	service := newFakeService(t, srv, zscaler.WithRateLimitBackend(ratelimiter.NewKVBackend(kv)))
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+
+	for _, key := range []string{"zscalertest/zia/read/10s", "zscalertest/zia/read/1h0m0s"} {
+		value, err := kv.Get(context.Background(), key)
+		require.NoError(t, err)
+		assert.NotEmpty(t, value, key)
+	}
+}
@@ -0,0 +1,184 @@
+// Package zscaler provides unit tests for JWT client assertions signed by external keys
+package zscaler
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+)
+
+// assertionEndpoint stands in for the token endpoint. It verifies the client
+// assertion against pub and keeps the parsed token for inspection.
+type assertionEndpoint struct {
+	*httptest.Server
+	pub   crypto.PublicKey
+	token *jwt.Token
+	err   error
+}
+
+func newAssertionEndpoint(t *testing.T, pub crypto.PublicKey) *assertionEndpoint {
+	t.Helper()
+	e := &assertionEndpoint{pub: pub}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.PostForm.Get("client_assertion_type"))
+		e.token, e.err = jwt.Parse(r.PostForm.Get("client_assertion"), func(*jwt.Token) (interface{}, error) {
+			return e.pub, nil
+		}, jwt.WithAudience("https://api.zscaler.com"), jwt.WithIssuer("client-id"))
+		if e.err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":"invalid_client"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"token_type": "Bearer", "access_token": "token", "expires_in": 3600})
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *assertionEndpoint) authenticate(t *testing.T, setters ...zscaler.ConfigSetter) error {
+	t.Helper()
+	cfg, err := zscaler.NewConfiguration(append([]zscaler.ConfigSetter{
+		zscaler.WithClientID("client-id"),
+		zscaler.WithVanityDomain("acme"),
+		zscaler.WithBaseURL(e.URL),
+	}, setters...)...)
+	require.NoError(t, err)
+	_, err = zscaler.Authenticate(context.Background(), cfg, cfg.Logger)
+	return err
+}
+
+// kmsSigner exposes only crypto.Signer, like a key held by a KMS or HSM.
+type kmsSigner struct {
+	key   crypto.Signer
+	calls atomic.Int32
+}
+
+func (s *kmsSigner) Public() crypto.PublicKey { return s.key.Public() }
+
+func (s *kmsSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls.Add(1)
+	return s.key.Sign(r, digest, opts)
+}
+
+func pemEncode(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestClientAssertion_ExternalSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer := &kmsSigner{key: key}
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "client-id"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	endpoint := newAssertionEndpoint(t, key.Public())
+	require.NoError(t, endpoint.authenticate(t,
+		zscaler.WithClientAssertionSigner(signer),
+		zscaler.WithClientAssertionKeyID("kms-key-1"),
+		zscaler.WithClientAssertionLifetime(2*time.Minute),
+		zscaler.WithClientAssertionCertificate(cert),
+		zscaler.WithClientAssertionID(func() string { return "fixed-jti" }),
+	))
+	require.NoError(t, endpoint.err)
+	assert.Equal(t, int32(1), signer.calls.Load())
+
+	header := endpoint.token.Header
+	assert.Equal(t, "ES256", header["alg"])
+	assert.Equal(t, "kms-key-1", header["kid"])
+	sha1Sum := sha1.Sum(der)
+	sha256Sum := sha256.Sum256(der)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sha1Sum[:]), header["x5t"])
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sha256Sum[:]), header["x5t#S256"])
+
+	claims := endpoint.token.Claims.(jwt.MapClaims)
+	assert.Equal(t, "fixed-jti", claims["jti"])
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), exp.Time, 5*time.Second)
+}
+
+func TestClientAssertion_KeyTypes(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+
+	for name, tc := range map[string]struct {
+		pub     crypto.PublicKey
+		setters []zscaler.ConfigSetter
+		alg     string
+	}{
+		"PKCS#8 P-384 PEM": {p384.Public(), []zscaler.ConfigSetter{zscaler.WithPrivateKey(pemEncode(t, p384))}, "ES384"},
+		"PKCS#1 RSA PEM":   {rsaKey.Public(), []zscaler.ConfigSetter{zscaler.WithPrivateKey(pkcs1)}, "RS256"},
+		"RSA-PSS signer": {rsaKey.Public(), []zscaler.ConfigSetter{
+			zscaler.WithClientAssertionSigner(&kmsSigner{key: rsaKey}),
+			zscaler.WithClientAssertionAlgorithm("PS256"),
+		}, "PS256"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			endpoint := newAssertionEndpoint(t, tc.pub)
+			require.NoError(t, endpoint.authenticate(t, tc.setters...))
+			require.NoError(t, endpoint.err)
+			assert.Equal(t, tc.alg, endpoint.token.Header["alg"])
+			assert.NotEmpty(t, endpoint.token.Claims.(jwt.MapClaims)["jti"])
+		})
+	}
+}
+
+func TestClientAssertion_JoseSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "jose-key"))
+	require.NoError(t, err)
+
+	endpoint := newAssertionEndpoint(t, key.Public())
+	require.NoError(t, endpoint.authenticate(t, zscaler.WithPrivateKeySigner(signer)))
+	require.NoError(t, endpoint.err)
+	assert.Equal(t, "jose-key", endpoint.token.Header["kid"])
+}
+
+func TestClientAssertion_AlgorithmMismatch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	endpoint := newAssertionEndpoint(t, key.Public())
+	err = endpoint.authenticate(t,
+		zscaler.WithClientAssertionSigner(key),
+		zscaler.WithClientAssertionAlgorithm("PS256"),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PS256")
+	assert.Nil(t, endpoint.token, "nothing is sent to the token endpoint")
+}
@@ -0,0 +1,192 @@
+// Package zscaler provides unit tests for configuration profiles and validation
+package zscaler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+)
+
+const profilesYAML = `
+zscaler:
+  client:
+    clientId: legacy-id
+    vanityDomain: legacy
+profiles:
+  production:
+    client:
+      clientId: prod-id
+      clientSecret: ${TEST_ZSCALER_SECRET}
+      vanityDomain: acme
+      rateLimit:
+        maxRetries: 7
+  beta:
+    inherits: production
+    client:
+      cloud: beta
+      privateKey: ${file:KEY_PATH}
+  loop-a:
+    inherits: loop-b
+  loop-b:
+    inherits: loop-a
+  typo:
+    client:
+      clientSecrte: oops
+`
+
+// isolateConfig points the configuration file lookup at an empty home directory
+// and returns the path of a profiles file in it.
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ZSCALER_PROFILE", "")
+	t.Setenv("ZSCALER_CONFIG_FILE", "")
+	for _, name := range []string{"ZSCALER_CLIENT_ID", "ZSCALER_CLIENT_SECRET", "ZSCALER_VANITY_DOMAIN", "ZSCALER_CLOUD", "ZSCALER_PRIVATE_KEY"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	keyPath := filepath.Join(home, "beta.pem")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	path := filepath.Join(home, "profiles.yaml")
+	content := []byte(strings.ReplaceAll(profilesYAML, "KEY_PATH", keyPath))
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	t.Setenv("TEST_ZSCALER_SECRET", "s3cr3t")
+	return path
+}
+
+func TestConfigProfiles_InheritanceAndReferences(t *testing.T) {
+	path := isolateConfig(t)
+	t.Setenv("ZSCALER_CONFIG_FILE", path)
+	t.Setenv("ZSCALER_PROFILE", "beta")
+
+	cfg, err := zscaler.NewConfiguration()
+	require.NoError(t, err)
+	client := cfg.Zscaler.Client
+	assert.Equal(t, "prod-id", client.ClientID)
+	assert.Equal(t, "s3cr3t", client.ClientSecret)
+	assert.Equal(t, "acme", client.VanityDomain)
+	assert.Equal(t, "beta", client.Cloud)
+	assert.Equal(t, int32(7), client.RateLimit.MaxRetries)
+	assert.Contains(t, string(client.PrivateKey), "BEGIN PRIVATE KEY")
+	assert.NotContains(t, string(client.PrivateKey), "\n-----END PRIVATE KEY-----\n")
+}
+
+func TestConfigProfiles_Precedence(t *testing.T) {
+	path := isolateConfig(t)
+	t.Setenv("ZSCALER_CLOUD", "zscalertwo")
+
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithConfigFile(path),
+		zscaler.WithClientID("setter-id"),
+		zscaler.WithProfile("beta"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "setter-id", cfg.Zscaler.Client.ClientID, "setters win over the file")
+	assert.Equal(t, "zscalertwo", cfg.Zscaler.Client.Cloud, "the environment wins over the file")
+	assert.Equal(t, "acme", cfg.Zscaler.Client.VanityDomain)
+
+	report, err := zscaler.ValidateConfiguration(cfg)
+	require.NoError(t, err)
+	for path, want := range map[string]zscaler.ConfigSource{
+		"zscaler.client.clientId":     zscaler.ConfigSourceSetter,
+		"zscaler.client.cloud":        zscaler.ConfigSourceEnvironment,
+		"zscaler.client.vanityDomain": zscaler.ConfigSourceFile,
+		"zscaler.client.proxy.port":   zscaler.ConfigSourceDefault,
+	} {
+		v, ok := report.Lookup(path)
+		require.True(t, ok, path)
+		assert.Equal(t, want, v.Source, path)
+	}
+	secret, _ := report.Lookup("zscaler.client.clientSecret")
+	assert.Equal(t, "REDACTED", secret.Value)
+	cloud, _ := report.Lookup("zscaler.client.cloud")
+	assert.Equal(t, "ZSCALER_CLOUD", cloud.Origin)
+	domain, _ := report.Lookup("zscaler.client.vanityDomain")
+	assert.Contains(t, domain.Origin, "profile beta")
+	assert.NotContains(t, report.String(), "s3cr3t")
+}
+
+func TestConfigProfiles_DefaultFile(t *testing.T) {
+	isolateConfig(t)
+	home, _ := os.UserHomeDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".zscaler"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zscaler", "zscaler.yaml"), []byte(profilesYAML), 0o600))
+
+	cfg, err := zscaler.NewConfiguration()
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-id", cfg.Zscaler.Client.ClientID, "the top-level block is the default profile")
+
+	cfg, err = zscaler.NewConfiguration(zscaler.WithProfile("production"))
+	require.NoError(t, err)
+	assert.Equal(t, "prod-id", cfg.Zscaler.Client.ClientID)
+}
+
+func TestConfigProfiles_Errors(t *testing.T) {
+	path := isolateConfig(t)
+
+	for name, tc := range map[string]struct {
+		setters []zscaler.ConfigSetter
+		want    string
+	}{
+		"unknown profile":  {[]zscaler.ConfigSetter{zscaler.WithConfigFile(path), zscaler.WithProfile("staging")}, `profile "staging" is not defined`},
+		"cycle":            {[]zscaler.ConfigSetter{zscaler.WithConfigFile(path), zscaler.WithProfile("loop-a")}, "inheritance cycle"},
+		"unknown setting":  {[]zscaler.ConfigSetter{zscaler.WithConfigFile(path), zscaler.WithProfile("typo")}, "zscaler.client.clientSecrte: unknown setting"},
+		"missing file":     {[]zscaler.ConfigSetter{zscaler.WithConfigFile(filepath.Join(t.TempDir(), "absent.yaml"))}, "no such file"},
+		"unset env in ref": {[]zscaler.ConfigSetter{zscaler.WithConfigFile(path), zscaler.WithProfile("production")}, "TEST_ZSCALER_SECRET"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if name == "unset env in ref" {
+				os.Unsetenv("TEST_ZSCALER_SECRET")
+			}
+			_, err := zscaler.NewConfiguration(tc.setters...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("ZSCALER_CLIENT_RATE_LIMIT_MIN_WAIT", "1m")
+
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithClientID("id"),
+		zscaler.WithPrivateKey("not a key"),
+		zscaler.WithRateLimitMaxWait(10*time.Second),
+	)
+	require.NoError(t, err)
+
+	_, err = zscaler.ValidateConfiguration(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errorx.ErrValidation))
+
+	var verr *errorx.ValidationError
+	require.True(t, errors.As(err, &verr))
+	fields := map[string]string{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = f.Message
+	}
+	assert.Contains(t, fields, "zscaler.client.vanityDomain")
+	assert.Contains(t, fields["zscaler.client.privateKey"], "PEM")
+	assert.Contains(t, fields["zscaler.client.rateLimit.minWait"], "env ZSCALER_CLIENT_RATE_LIMIT_MIN_WAIT")
+	assert.NotContains(t, fields, "zscaler.client.clientSecret")
+}
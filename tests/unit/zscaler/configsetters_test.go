@@ -282,3 +282,19 @@ func TestConfiguration_AddDefaultHeader(t *testing.T) {
 	})
 }
 
+
+func TestConfigSetters_HTTPClientsBuiltAfterSetters(t *testing.T) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithHttpClientPtr(httpClient),
+		zscaler.WithRequestTimeout(2*time.Minute),
+		zscaler.WithAdaptiveRateLimit(true),
+		zscaler.WithSharedRateLimitDir(t.TempDir()),
+		zscaler.WithRecorder(zscaler.RecorderModePassthrough, t.TempDir()),
+		zscaler.WithRecorderCassette("setters"),
+	)
+	require.NoError(t, err)
+	assert.Same(t, httpClient, cfg.HTTPClient, "later setters keep a user supplied client")
+	assert.NotNil(t, cfg.ZIAHTTPClient)
+	assert.NotNil(t, cfg.ZPAHTTPClient)
+}
@@ -0,0 +1,67 @@
+// Package zscaler provides unit tests for the CSV report decoder
+package zscaler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+)
+
+type csvxRow struct {
+	Name  string            `csv:"Name|Full Name"`
+	Count *int              `csv:"Count"`
+	Seen  time.Time         `csv:"Seen"`
+	On    bool              `csv:"Enabled"`
+	Extra map[string]string `csv:"*"`
+}
+
+func TestCSVX_NullValuesAndExtraColumns(t *testing.T) {
+	report := "full_name,COUNT,Seen,Enabled,Region,\n" +
+		"alpha,N/A,--,yes,EU,ignored\n" +
+		"\n" +
+		"beta,\"1,024\",2026-10-01,disabled,,\n"
+
+	var rows []csvxRow
+	for row, err := range csvx.NewDecoder[csvxRow](strings.NewReader(report)).All() {
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	require.Len(t, rows, 2)
+	assert.Nil(t, rows[0].Count)
+	assert.True(t, rows[0].Seen.IsZero())
+	assert.True(t, rows[0].On)
+	assert.Equal(t, map[string]string{"Region": "EU"}, rows[0].Extra, "columns without a header are dropped")
+	require.NotNil(t, rows[1].Count)
+	assert.Equal(t, 1024, *rows[1].Count)
+	assert.False(t, rows[1].On)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), rows[1].Seen)
+}
+
+func TestCSVX_WithNullValues(t *testing.T) {
+	dec := csvx.NewDecoder[csvxRow](strings.NewReader("Name,Count\nalpha,N/A\n"), csvx.WithNullValues("unknown"))
+
+	_, err := dec.Decode()
+	var parseErr *csvx.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "Count", parseErr.Column)
+
+	dec = csvx.NewDecoder[csvxRow](strings.NewReader("Name,Count\nalpha,unknown\n"), csvx.WithNullValues("unknown"))
+	row, err := dec.Decode()
+	require.NoError(t, err)
+	assert.Nil(t, row.Count)
+	assert.Equal(t, []string{"Name", "Count"}, dec.Header())
+}
+
+func TestCSVX_InvalidExtraField(t *testing.T) {
+	type badRow struct {
+		Name  string `csv:"Name"`
+		Extra string `csv:"*"`
+	}
+	_, err := csvx.NewDecoder[badRow](strings.NewReader("Name\na\n")).Decode()
+	assert.ErrorContains(t, err, "not a map[string]string")
+}
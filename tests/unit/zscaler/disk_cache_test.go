@@ -0,0 +1,153 @@
+// Package zscaler provides unit tests for the persistent OneAPI response cache
+package zscaler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/ruleorder"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func TestDiskCache_SurvivesAcrossClients(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	first := newFakeService(t, srv, zscaler.WithCacheDir(dir))
+	_, err = rule_labels.Get(context.Background(), first, 3)
+	require.NoError(t, err)
+
+	// A second client, as in a later CLI invocation, is served from disk.
+	second := newFakeService(t, srv, zscaler.WithCacheDir(dir))
+	label, err := rule_labels.Get(context.Background(), second, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "seeded", label.Name)
+	assert.Equal(t, 1, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/3"))
+}
+
+func TestDiskCache_IsolatesClients(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	first := newFakeService(t, srv, zscaler.WithCacheDir(dir))
+	_, err = rule_labels.Get(context.Background(), first, 3)
+	require.NoError(t, err)
+
+	// A client of another tenant sharing the directory is not served the cached response.
+	other := newFakeService(t, srv, zscaler.WithCacheDir(dir), zscaler.WithVanityDomain("other-tenant"))
+	_, err = rule_labels.Get(context.Background(), other, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/3"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "each tenant has its own subdirectory")
+}
+
+func TestDiskCache_RevalidatesExpiredEntries(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	service := newFakeService(t, srv, zscaler.WithCacheTtl(time.Millisecond), zscaler.WithCacheDir(dir))
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	label, err := rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "seeded", label.Name)
+
+	var gets []zscalertest.Request
+	for _, r := range srv.Requests() {
+		if r.Method == http.MethodGet && r.Path == "/zia/api/v1/ruleLabels/3" {
+			gets = append(gets, r)
+		}
+	}
+	require.Len(t, gets, 2)
+	assert.Empty(t, gets[0].Header.Get("If-None-Match"))
+	assert.NotEmpty(t, gets[1].Header.Get("If-None-Match"), "the expired entry is revalidated")
+}
+
+func TestCache_WithoutCacheBypassesReads(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv, zscaler.WithCache(true), zscaler.WithCacheTtl(time.Minute))
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/3"))
+
+	ctx := zscaler.WithoutCache(context.Background())
+	_, err = rule_labels.Get(ctx, service, 3)
+	require.NoError(t, err)
+	_, err = rule_labels.Get(ctx, service, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/3"))
+}
+
+func TestCache_ExecuteStreamRequestIsNotCached(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv, zscaler.WithCache(true), zscaler.WithCacheTtl(time.Minute))
+	for range 2 {
+		resp, err := service.Client.ExecuteStreamRequest(context.Background(), http.MethodGet, "/zia/api/v1/ruleLabels/3", nil, nil, "")
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Contains(t, string(body), `"name":"seeded"`)
+	}
+	assert.Equal(t, 2, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/3"))
+
+	_, err = service.Client.ExecuteStreamRequest(context.Background(), http.MethodGet, "/zia/api/v1/ruleLabels/4", nil, nil, "")
+	assert.ErrorIs(t, err, errorx.ErrNotFound, "error responses are returned as errors")
+}
+
+func TestCache_RuleOrderReadsBypassCache(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	const path = "/zia/api/v1/firewallFilteringRules"
+	_, err := srv.Seed(path, filteringrules.FirewallFilteringRules{Name: "first", Order: 1})
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv, zscaler.WithCache(true), zscaler.WithCacheTtl(time.Minute))
+	policy := ruleorder.FirewallFiltering(service)
+	ctx := context.Background()
+
+	rules, err := policy.Rules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	// Another admin adds a rule; the next readback must see it.
+	_, err = srv.Seed(path, filteringrules.FirewallFilteringRules{Name: "second", Order: 2})
+	require.NoError(t, err)
+	rules, err = policy.Rules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+}
@@ -0,0 +1,136 @@
+// Package zscaler provides unit tests for dry-run planning of mutating calls
+package zscaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/urlcategories"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func TestDryRun_PlansMutationsAndReadsState(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed(segmentGroupPath, segmentgroup.SegmentGroup{Name: "web", Description: "old"})
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv, zscaler.WithDryRun(true))
+	ctx := context.Background()
+	plan := service.Plan()
+	require.NotNil(t, plan)
+
+	group, _, err := segmentgroup.Get(ctx, service, ids[0])
+	require.NoError(t, err, "reads still reach the API")
+	group.Description = "new"
+	_, err = segmentgroup.Update(ctx, service, ids[0], group)
+	require.NoError(t, err)
+
+	label, _, err := rule_labels.Create(ctx, service, &rule_labels.RuleLabels{Name: "planned"})
+	require.NoError(t, err)
+	assert.Equal(t, "planned", label.Name, "creates are answered with the request body")
+
+	_, err = segmentgroup.Delete(ctx, service, ids[0])
+	require.NoError(t, err)
+
+	_, err = service.Client.NewRequestDo(ctx, http.MethodPost, segmentGroupPath, nil, map[string]string{"name": "x", "sharedSecret": "hunter2"}, nil)
+	require.NoError(t, err)
+
+	assert.Zero(t, countRequests(srv, http.MethodPut, segmentGroupPath+"/"+ids[0]))
+	assert.Zero(t, countRequests(srv, http.MethodDelete, segmentGroupPath+"/"+ids[0]))
+	assert.Empty(t, srv.Objects("/zia/api/v1/ruleLabels"))
+	assert.Len(t, srv.Objects(segmentGroupPath), 1)
+
+	calls := plan.Calls()
+	require.Len(t, calls, 4)
+	assert.Equal(t, http.MethodPut, calls[0].Method)
+	assert.Equal(t, "zpa", calls[0].Product)
+	assert.Contains(t, string(calls[0].Current), `"description":"old"`)
+	assert.Contains(t, string(calls[0].Body), `"description":"new"`)
+	assert.Equal(t, "zia", calls[1].Product)
+	assert.Empty(t, calls[1].Current, "nothing is read before a create")
+	assert.Equal(t, http.MethodDelete, calls[2].Method)
+	assert.NotContains(t, string(calls[3].Body), "hunter2")
+
+	diff := plan.Diff()
+	assert.Contains(t, diff, `~ description: "old" => "new"`)
+	assert.Contains(t, diff, `+ name: "planned"`)
+	assert.Contains(t, diff, `- name: "web"`)
+	assert.NotContains(t, diff, "hunter2")
+
+	data, err := json.Marshal(plan)
+	require.NoError(t, err)
+	var exported struct {
+		Calls []zscaler.PlannedCall `json:"calls"`
+	}
+	require.NoError(t, json.Unmarshal(data, &exported))
+	assert.Len(t, exported.Calls, 4)
+
+	plan.Reset()
+	assert.Zero(t, plan.Len())
+}
+
+func TestDryRun_CurrentStateBypassesCache(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed(segmentGroupPath, segmentgroup.SegmentGroup{Name: "web", Description: "old"})
+	require.NoError(t, err)
+	service := newFakeService(t, srv, zscaler.WithDryRun(true), zscaler.WithCache(true))
+	other := newFakeService(t, srv)
+	ctx := context.Background()
+
+	group, _, err := segmentgroup.Get(ctx, service, ids[0])
+	require.NoError(t, err)
+	_, err = segmentgroup.Update(ctx, other, ids[0], &segmentgroup.SegmentGroup{Name: "web", Description: "changed elsewhere"})
+	require.NoError(t, err)
+
+	group.Description = "new"
+	_, err = segmentgroup.Update(ctx, service, ids[0], group)
+	require.NoError(t, err)
+	calls := service.Plan().Calls()
+	require.Len(t, calls, 1)
+	assert.Contains(t, string(calls[0].Current), `"description":"changed elsewhere"`, "the plan shows the live state, not a cached copy")
+}
+
+func TestDryRun_DisabledByDefault(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	assert.Nil(t, service.Plan())
+
+	_, _, err := rule_labels.Create(context.Background(), service, &rule_labels.RuleLabels{Name: "sent"})
+	require.NoError(t, err)
+	assert.Len(t, srv.Objects("/zia/api/v1/ruleLabels"), 1)
+}
+
+func TestDryRun_ReadOnlyPostsReachTheAPI(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	lookups := 0
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zia/api/v1/urlLookup" {
+			srv.ServeHTTP(w, r)
+			return
+		}
+		lookups++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]urlcategories.URLClassification{{URL: "example.com", URLClassifications: []string{"NEWS_AND_MEDIA"}}})
+	}))
+	defer front.Close()
+
+	service := newFakeService(t, srv, zscaler.WithBaseURL(front.URL), zscaler.WithDryRun(true))
+	results, err := urlcategories.GetURLLookup(context.Background(), service, []string{"example.com"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"NEWS_AND_MEDIA"}, results[0].URLClassifications)
+	assert.Equal(t, 1, lookups)
+	assert.Zero(t, service.Plan().Len(), "lookups are not planned")
+}
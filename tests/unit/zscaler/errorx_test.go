@@ -0,0 +1,108 @@
+// Package zscaler provides unit tests for the typed error kinds in errorx
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func apiErrorResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "api.example.com", Path: "/zpa/mgmtconfig/v1/admin/customers/1/server"}},
+	}
+}
+
+func TestErrorx_KindsFromResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"not found", http.StatusNotFound, `{"id": "resource.not.found"}`, errorx.ErrNotFound},
+		{"not found by id", http.StatusBadRequest, `{"id": "resource.not.found", "reason": "gone"}`, errorx.ErrNotFound},
+		{"conflict", http.StatusConflict, `{"code": "EDIT_LOCK_NOT_AVAILABLE"}`, errorx.ErrConflict},
+		{"rate limited", http.StatusTooManyRequests, `{"message": "Rate Limit (1/SECOND) exceeded", "Retry-After": "3 seconds"}`, errorx.ErrRateLimited},
+		{"unauthorized", http.StatusUnauthorized, `{"message": "bad token"}`, errorx.ErrUnauthorized},
+		{"forbidden", http.StatusForbidden, `{"message": "not allowed"}`, errorx.ErrUnauthorized},
+		{"session invalid", http.StatusUnauthorized, `{"code": "SESSION_NOT_VALID"}`, errorx.ErrSessionInvalid},
+		{"validation", http.StatusBadRequest, `{"code": "INVALID_INPUT_ARGUMENT", "message": "bad"}`, errorx.ErrValidation},
+		{"timeout", http.StatusGatewayTimeout, `{"message": "upstream timed out"}`, errorx.ErrTimeout},
+	}
+	kinds := []error{errorx.ErrNotFound, errorx.ErrConflict, errorx.ErrRateLimited, errorx.ErrUnauthorized, errorx.ErrSessionInvalid, errorx.ErrValidation, errorx.ErrTimeout}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errorx.CheckErrorInResponse(apiErrorResponse(tt.status, nil, tt.body), errors.New("client error"))
+			require.Error(t, err)
+			for _, kind := range kinds {
+				want := kind == tt.kind || (kind == errorx.ErrUnauthorized && tt.kind == errorx.ErrSessionInvalid)
+				assert.Equal(t, want, errors.Is(err, kind), "errors.Is(err, %v)", kind)
+			}
+			var errResp *errorx.ErrorResponse
+			assert.True(t, errors.As(err, &errResp), "the response stays available")
+		})
+	}
+}
+
+func TestErrorx_RetryAfterAndFieldDetails(t *testing.T) {
+	err := errorx.CheckErrorInResponse(apiErrorResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"7"}}, `{}`), nil)
+	d, ok := errorx.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, d)
+
+	err = errorx.CheckErrorInResponse(apiErrorResponse(http.StatusBadRequest, nil,
+		`{"id": "invalid.input", "reason": "must not be empty", "params": ["name", "domainNames"]}`), nil)
+	var verr *errorx.ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, []errorx.FieldError{
+		{Field: "name", Message: "must not be empty"},
+		{Field: "domainNames", Message: "must not be empty"},
+	}, verr.Fields)
+
+	err = errorx.CheckErrorInResponse(apiErrorResponse(http.StatusUnprocessableEntity, nil,
+		`{"message": "invalid user", "errors": [{"field": "email", "message": "is required"}]}`), nil)
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, []errorx.FieldError{{Field: "email", Message: "is required"}}, verr.Fields)
+}
+
+func TestErrorx_ExecuteRequestErrors(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+
+	_, err := rule_labels.Get(context.Background(), service, 404)
+	assert.ErrorIs(t, err, errorx.ErrNotFound)
+
+	_, err = service.Client.Create(context.Background(), "/zia/api/v1/ruleLabels", nil)
+	assert.ErrorIs(t, err, errorx.ErrValidation)
+
+}
+
+func TestErrorx_KindWrapping(t *testing.T) {
+	cause := errors.New("dial tcp: i/o timeout")
+	err := errorx.Errorf(errorx.ErrTimeout, "request failed: %w", cause)
+	assert.ErrorIs(t, err, errorx.ErrTimeout)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "request failed: dial tcp: i/o timeout", err.Error())
+
+	assert.NotErrorIs(t, errorx.New(nil, "plain"), errorx.ErrTimeout)
+}
@@ -0,0 +1,153 @@
+// Package zscaler provides unit tests for the OneAPI request interceptor chain
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func TestInterceptors_RequestChainOrder(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	var order []string
+	service := newFakeService(t, srv,
+		zscaler.WithRequestInterceptor(func(ctx context.Context, req *http.Request, info zscaler.RequestInfo) error {
+			order = append(order, "first")
+			req.Header.Set("X-Tenant", "acme")
+			return nil
+		}),
+		zscaler.WithRequestInterceptor(func(ctx context.Context, req *http.Request, info zscaler.RequestInfo) error {
+			order = append(order, "second")
+			assert.Equal(t, "acme", req.Header.Get("X-Tenant"))
+			assert.Equal(t, "zia", info.Product)
+			assert.Equal(t, http.MethodGet, info.Method)
+			assert.Equal(t, "/zia/api/v1/ruleLabels/3", info.Endpoint)
+			assert.Equal(t, 1, info.Attempt)
+			return nil
+		}),
+	)
+
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+
+	var seen bool
+	for _, r := range srv.Requests() {
+		if r.Path == "/zia/api/v1/ruleLabels/3" {
+			seen = true
+			assert.Equal(t, "acme", r.Header.Get("X-Tenant"))
+		}
+	}
+	assert.True(t, seen)
+}
+
+func TestInterceptors_ResponseSeesEachAttempt(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	type observed struct {
+		attempt int
+		status  int
+		err     error
+		body    string
+	}
+	var attempts []observed
+	service := newFakeService(t, srv,
+		zscaler.WithRateLimitMinWait(10*time.Millisecond),
+		zscaler.WithRateLimitMaxWait(20*time.Millisecond),
+		zscaler.WithResponseInterceptor(func(ctx context.Context, resp *http.Response, info zscaler.RequestInfo, err error) error {
+			body, readErr := io.ReadAll(resp.Body)
+			require.NoError(t, readErr)
+			attempts = append(attempts, observed{attempt: info.Attempt, status: resp.StatusCode, err: err, body: string(body)})
+			return nil
+		}),
+	)
+
+	srv.InjectFault(zscalertest.EditLockFault("/zia/api/v1/ruleLabels/3", 1))
+	label, err := rule_labels.Get(context.Background(), service, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "seeded", label.Name)
+
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].attempt)
+	assert.Equal(t, http.StatusConflict, attempts[0].status)
+	require.Error(t, attempts[0].err)
+	assert.Contains(t, attempts[0].err.Error(), "EDIT_LOCK_NOT_AVAILABLE")
+	assert.Equal(t, 2, attempts[1].attempt)
+	assert.Equal(t, http.StatusOK, attempts[1].status)
+	assert.NoError(t, attempts[1].err)
+	assert.Contains(t, attempts[1].body, "seeded")
+}
+
+func TestInterceptors_ErrorsAbortTheRequest(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	errDenied := errors.New("denied by policy")
+	service := newFakeService(t, srv,
+		zscaler.WithRequestInterceptor(func(ctx context.Context, req *http.Request, info zscaler.RequestInfo) error {
+			if req.Method == http.MethodDelete {
+				return errDenied
+			}
+			return nil
+		}),
+	)
+
+	_, err = rule_labels.Delete(context.Background(), service, 3)
+	require.ErrorIs(t, err, errDenied)
+	assert.Equal(t, 0, countRequests(srv, http.MethodDelete, "/zia/api/v1/ruleLabels/3"))
+	assert.Len(t, srv.Objects("/zia/api/v1/ruleLabels"), 1)
+
+	errAudit := errors.New("audit sink unavailable")
+	service = newFakeService(t, srv,
+		zscaler.WithResponseInterceptor(func(ctx context.Context, resp *http.Response, info zscaler.RequestInfo, err error) error {
+			return errAudit
+		}),
+	)
+	_, err = rule_labels.Get(context.Background(), service, 3)
+	require.ErrorIs(t, err, errAudit)
+}
+
+func TestInterceptors_StreamedBodyIsNotBuffered(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 3, Name: "seeded"})
+	require.NoError(t, err)
+
+	var status int
+	var seen []byte
+	service := newFakeService(t, srv,
+		zscaler.WithResponseInterceptor(func(ctx context.Context, resp *http.Response, info zscaler.RequestInfo, err error) error {
+			status = resp.StatusCode
+			seen, _ = io.ReadAll(resp.Body)
+			return nil
+		}),
+	)
+
+	resp, err := service.Client.ExecuteStreamRequest(context.Background(), http.MethodGet, "/zia/api/v1/ruleLabels/3", nil, nil, "")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, seen, "interceptors do not read the download")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"name":"seeded"`)
+}
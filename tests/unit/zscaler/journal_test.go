@@ -0,0 +1,192 @@
+// Package zscaler provides unit tests for the mutation journal and rollback
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/policysetcontrollerv2"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func segmentGroupNames(srv *zscalertest.Server) map[string]string {
+	out := map[string]string{}
+	for _, obj := range srv.Objects(segmentGroupPath) {
+		name, _ := obj["name"].(string)
+		desc, _ := obj["description"].(string)
+		out[name] = desc
+	}
+	return out
+}
+
+func TestJournal_TransactionRollsBackOnFailure(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed(segmentGroupPath,
+		segmentgroup.SegmentGroup{Name: "keep", Description: "original"},
+		segmentgroup.SegmentGroup{Name: "doomed", Description: "to be deleted"},
+	)
+	require.NoError(t, err)
+	service := newFakeService(t, srv)
+	before := segmentGroupNames(srv)
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	failure := errors.New("access policy rule failed")
+	err = zscaler.Transaction(context.Background(), service, path, func(ctx context.Context) error {
+		if _, _, err := segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "new"}); err != nil {
+			return err
+		}
+		if _, err := segmentgroup.Update(ctx, service, ids[0], &segmentgroup.SegmentGroup{Name: "keep", Description: "changed"}); err != nil {
+			return err
+		}
+		if _, err := segmentgroup.Delete(ctx, service, ids[1]); err != nil {
+			return err
+		}
+		assert.Equal(t, map[string]string{"keep": "changed", "new": ""}, segmentGroupNames(srv))
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	assert.Equal(t, before, segmentGroupNames(srv), "every change is undone")
+	assert.NoFileExists(t, path, "a completed rollback removes the journal")
+}
+
+func TestJournal_CommitAndRecoveryAfterCrash(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "committed.json")
+	require.NoError(t, zscaler.Transaction(context.Background(), service, path, func(ctx context.Context) error {
+		_, _, err := segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "committed"})
+		return err
+	}))
+	assert.NoFileExists(t, path)
+	assert.Contains(t, segmentGroupNames(srv), "committed")
+
+	// A run that crashes leaves its journal behind.
+	path = filepath.Join(dir, "crashed.json")
+	journal, err := zscaler.NewJournal(path)
+	require.NoError(t, err)
+	ctx := zscaler.WithJournal(context.Background(), journal)
+	created, _, err := segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "orphan-a"})
+	require.NoError(t, err)
+	_, _, err = segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "orphan-b"})
+	require.NoError(t, err)
+	_, err = segmentgroup.Update(ctx, service, "999999", &segmentgroup.SegmentGroup{Name: "missing"})
+	require.Error(t, err)
+	assert.Equal(t, 1, countRequests(srv, http.MethodPut, segmentGroupPath+"/999999"), "a change without a pre-image is still sent")
+
+	_, err = zscaler.NewJournal(path)
+	assert.Error(t, err, "an existing journal is never overwritten")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	recovered, err := zscaler.OpenJournal(path)
+	require.NoError(t, err)
+	entries := recovered.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, zscaler.JournalFailed, entries[2].State)
+	assert.Equal(t, zscaler.JournalApplied, entries[0].State)
+	assert.Equal(t, created.ID, entries[0].ResourceID)
+	assert.Equal(t, http.MethodDelete, entries[0].Inverse.Method)
+
+	require.NoError(t, recovered.Rollback(context.Background(), service))
+	names := make([]string, 0)
+	for name := range segmentGroupNames(srv) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"committed"}, names)
+	assert.NoFileExists(t, path)
+}
+
+func TestJournal_FailedRequests(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed(segmentGroupPath, segmentgroup.SegmentGroup{Name: "taken"})
+	require.NoError(t, err)
+	service := newFakeService(t, srv)
+
+	journal, err := zscaler.NewJournal("")
+	require.NoError(t, err)
+	ctx := zscaler.WithJournal(context.Background(), journal)
+	_, _, err = segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "taken"})
+	require.Error(t, err)
+
+	entries := journal.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zscaler.JournalFailed, entries[0].State)
+	assert.Contains(t, entries[0].Error, "already exists")
+	assert.Nil(t, entries[0].Inverse)
+	assert.NoError(t, journal.Rollback(context.Background(), service), "failed requests need no undo")
+}
+
+func TestJournal_TransactionWithReorder(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	policyPath := "/zpa/mgmtconfig/v1/admin/customers/" + zscalertest.CustomerID + "/policySet/72058/rule/"
+	reorders := 0
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, policyPath) {
+			reorders++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer front.Close()
+	service := newFakeService(t, srv, zscaler.WithBaseURL(front.URL))
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	failure := errors.New("later step failed")
+	err := zscaler.Transaction(context.Background(), service, path, func(ctx context.Context) error {
+		if _, _, err := segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "new"}); err != nil {
+			return err
+		}
+		if _, err := policysetcontrollerv2.Reorder(ctx, service, "72058", "216199618143374337", 2); err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	assert.ErrorContains(t, err, "cannot be undone", "the reorder is reported, not undone")
+	assert.Equal(t, 1, reorders, "the reorder was sent although it cannot be read back")
+	assert.Empty(t, segmentGroupNames(srv), "the rest of the transaction is still rolled back")
+}
+
+func TestJournal_PreImageBypassesCache(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed(segmentGroupPath, segmentgroup.SegmentGroup{Name: "group", Description: "before"})
+	require.NoError(t, err)
+	service := newFakeService(t, srv, zscaler.WithCache(true))
+	other := newFakeService(t, srv)
+	ctx := context.Background()
+
+	_, _, err = segmentgroup.Get(ctx, service, ids[0])
+	require.NoError(t, err)
+	_, err = segmentgroup.Update(ctx, other, ids[0], &segmentgroup.SegmentGroup{Name: "group", Description: "changed elsewhere"})
+	require.NoError(t, err)
+
+	journal, err := zscaler.NewJournal("")
+	require.NoError(t, err)
+	_, err = segmentgroup.Update(zscaler.WithJournal(ctx, journal), service, ids[0], &segmentgroup.SegmentGroup{Name: "group", Description: "after"})
+	require.NoError(t, err)
+	entries := journal.Entries()
+	require.Len(t, entries, 1)
+	assert.Contains(t, string(entries[0].PreImage), "changed elsewhere", "the pre-image is the current state, not a cached copy")
+}
@@ -0,0 +1,98 @@
+// Package zscaler provides unit tests for OneAPI client logging
+package zscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func TestOneAPIClient_SlogHandler(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 5, Name: "seeded"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: logger.LevelTrace})
+	cfg, err := zscaler.NewConfiguration(append(srv.ConfigSetters(), zscaler.WithSlogHandler(handler))...)
+	require.NoError(t, err)
+	service, err := zscaler.NewOneAPIClient(cfg)
+	require.NoError(t, err)
+	defer service.Client.Close()
+
+	_, err = rule_labels.Get(context.Background(), service, 5)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.NotContains(t, output, zscalertest.DefaultClientSecret)
+	token := cfg.Zscaler.Client.AuthToken.AccessToken
+	require.NotEmpty(t, token)
+	assert.NotContains(t, output, token)
+
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var rec map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		if rec["msg"] == "http request" && rec["path"] == "/zia/api/v1/ruleLabels/5" {
+			found = true
+			assert.Equal(t, "zia", rec["product"])
+			assert.Equal(t, "GET", rec["method"])
+			assert.EqualValues(t, 200, rec["status"])
+			assert.EqualValues(t, 0, rec["retry"])
+			assert.NotEmpty(t, rec["request_id"])
+		}
+	}
+	assert.True(t, found, "expected a structured http request record")
+}
+
+func TestOneAPIClient_RedactionPolicy(t *testing.T) {
+	rec := &capturingLogger{}
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithLogger(rec),
+		zscaler.WithRedactionPolicy(&logger.RedactionPolicy{Values: []string{"tenant-secret"}}),
+	)
+	require.NoError(t, err)
+
+	cfg.Logger.Printf("[INFO] value tenant-secret")
+	require.NotEmpty(t, rec.lines)
+	assert.Equal(t, "[INFO] value "+logger.RedactedValue, rec.lines[len(rec.lines)-1])
+}
+
+func TestOneAPIClient_RedactionPolicyKeepsHTTPClient(t *testing.T) {
+	policy := &logger.RedactionPolicy{Values: []string{"tenant-secret"}}
+	for name, l := range map[string]logger.Logger{
+		"nop":     logger.NewNopLogger(),
+		"wrapped": &capturingLogger{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &http.Client{}
+			cfg, err := zscaler.NewConfiguration(
+				zscaler.WithLogger(l),
+				zscaler.WithHttpClientPtr(client),
+				zscaler.WithRedactionPolicy(policy),
+			)
+			require.NoError(t, err)
+			assert.Same(t, client, cfg.HTTPClient)
+		})
+	}
+}
+
+type capturingLogger struct {
+	lines []string
+}
+
+func (c *capturingLogger) Printf(format string, v ...interface{}) {
+	c.lines = append(c.lines, format)
+}
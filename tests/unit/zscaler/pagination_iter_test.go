@@ -0,0 +1,164 @@
+// Package zscaler provides unit tests for the lazy pagination iterators
+package zscaler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	ziacommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	zidcommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zidentity/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zidentity/services/groups"
+	zpacommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+	ztwcommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/ztw/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/ztw/services/policyresources/ipgroups"
+)
+
+func TestIterAllPages_ZIAStopsEarly(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	labels := make([]interface{}, 0, 50)
+	for i := 0; i < 50; i++ {
+		labels = append(labels, rule_labels.RuleLabels{Name: fmt.Sprintf("label-%02d", i)})
+	}
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", labels...)
+	require.NoError(t, err)
+	service := newFakeService(t, srv)
+
+	var names []string
+	for label, err := range ziacommon.IterAllPages[rule_labels.RuleLabels](context.Background(), service.Client, "/zia/api/v1/ruleLabels", 10) {
+		require.NoError(t, err)
+		names = append(names, label.Name)
+		if len(names) == 15 {
+			break
+		}
+	}
+	assert.Equal(t, "label-14", names[14])
+	assert.Equal(t, 2, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels"), "only the pages reached are fetched")
+
+	var all []rule_labels.RuleLabels
+	require.NoError(t, ziacommon.ReadAllPages(context.Background(), service.Client, "/zia/api/v1/ruleLabels", &all, 10))
+	assert.Len(t, all, 50)
+}
+
+func TestIterAllPages_ZTW(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/ztw/api/v1/ipGroups",
+		ipgroups.IPGroups{Name: "a"}, ipgroups.IPGroups{Name: "b"}, ipgroups.IPGroups{Name: "c"})
+	require.NoError(t, err)
+	service := newFakeService(t, srv)
+
+	items := ztwcommon.IterAllPages[ipgroups.IPGroups](context.Background(), service.Client, "/ztw/api/v1/ipGroups")
+	assert.Zero(t, countRequests(srv, http.MethodGet, "/ztw/api/v1/ipGroups"), "nothing is fetched before iterating")
+	var names []string
+	for group, err := range items {
+		require.NoError(t, err)
+		names = append(names, group.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, 1, countRequests(srv, http.MethodGet, "/ztw/api/v1/ipGroups"))
+}
+
+func TestIterAllPages_ZPAAndZIdentity(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	collection := "/zpa/mgmtconfig/v1/admin/customers/" + zscalertest.CustomerID + "/segmentGroup"
+	seed := make([]interface{}, 0, 600)
+	for i := 0; i < 600; i++ {
+		seed = append(seed, segmentgroup.SegmentGroup{Name: fmt.Sprintf("seeded-%03d", i)})
+	}
+	_, err := srv.Seed(collection, seed...)
+	require.NoError(t, err)
+
+	idGroups := make([]interface{}, 0, 250)
+	for i := 0; i < 250; i++ {
+		idGroups = append(idGroups, groups.Groups{Name: fmt.Sprintf("group-%03d", i)})
+	}
+	_, err = srv.Seed("/admin/api/v1/groups", idGroups...)
+	require.NoError(t, err)
+	service := newFakeService(t, srv)
+
+	count := 0
+	for _, err := range zpacommon.IterAllPagesGeneric[segmentgroup.SegmentGroup](ctx, service.Client, collection, "") {
+		require.NoError(t, err)
+		count++
+	}
+	assert.Equal(t, 600, count)
+	assert.Equal(t, 2, countRequests(srv, http.MethodGet, collection))
+
+	count = 0
+	for _, err := range zidcommon.IterAllPagesWithPagination[groups.Groups](ctx, service.Client, "/admin/api/v1/groups", &zidcommon.PaginationQueryParams{Limit: 100}) {
+		require.NoError(t, err)
+		count++
+		if count == 100 {
+			break
+		}
+	}
+	assert.Equal(t, 1, countRequests(srv, http.MethodGet, "/admin/api/v1/groups"))
+}
+
+func TestIterAllPages_CancelledContext(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var errs []error
+	for _, err := range ziacommon.IterAllPages[rule_labels.RuleLabels](ctx, service.Client, "/zia/api/v1/ruleLabels") {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+	assert.Zero(t, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels"))
+}
+
+func TestPageConcurrency_PreservesOrder(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	collection := "/zpa/mgmtconfig/v1/admin/customers/" + zscalertest.CustomerID + "/segmentGroup"
+	seed := make([]interface{}, 0, 2300)
+	for i := 0; i < 2300; i++ {
+		seed = append(seed, segmentgroup.SegmentGroup{Name: fmt.Sprintf("seeded-%04d", i)})
+	}
+	_, err := srv.Seed(collection, seed...)
+	require.NoError(t, err)
+
+	idGroups := make([]interface{}, 0, 230)
+	for i := 0; i < 230; i++ {
+		idGroups = append(idGroups, groups.Groups{Name: fmt.Sprintf("group-%03d", i)})
+	}
+	_, err = srv.Seed("/admin/api/v1/groups", idGroups...)
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv, zscaler.WithPageConcurrency(3))
+
+	segments, _, err := zpacommon.GetAllPagesGeneric[segmentgroup.SegmentGroup](ctx, service.Client, collection, "")
+	require.NoError(t, err)
+	require.Len(t, segments, 2300)
+	for i, sg := range segments {
+		require.Equal(t, fmt.Sprintf("seeded-%04d", i), sg.Name)
+	}
+	assert.Equal(t, 5, countRequests(srv, http.MethodGet, collection))
+
+	all, err := zidcommon.ReadAllPagesWithPagination[groups.Groups](ctx, service.Client, "/admin/api/v1/groups", &zidcommon.PaginationQueryParams{Limit: 50})
+	require.NoError(t, err)
+	require.Len(t, all, 230)
+	for i, g := range all {
+		require.Equal(t, fmt.Sprintf("group-%03d", i), g.Name)
+	}
+	assert.Equal(t, 5, countRequests(srv, http.MethodGet, "/admin/api/v1/groups"))
+}
@@ -0,0 +1,223 @@
+// Package zscaler provides unit tests for SCIM requests sent through the OneAPI transport
+package zscaler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/scim"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia"
+	ziascim "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/scim_api"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa"
+	zpascim "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/scim_api"
+)
+
+const scimToken = "scim-bearer-token"
+
+// scimServer is a SCIM endpoint holding users u1..uN.
+type scimServer struct {
+	*httptest.Server
+	users int
+	mu    sync.Mutex
+	seen  []*http.Request
+	body  map[string][]byte
+	fail  atomic.Int32 // requests still answered with 503
+}
+
+func newScimServer(t *testing.T, users int) *scimServer {
+	s := &scimServer{users: users, body: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *scimServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.seen = append(s.seen, r)
+	s.body[r.Method+" "+r.URL.Path] = body
+	s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+scimToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.fail.Add(-1) >= 0 {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	switch {
+	case r.Method == http.MethodPatch:
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/missing"):
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"404","detail":"User missing not found"}`)
+	case strings.HasSuffix(r.URL.Path, "/Users") || strings.HasSuffix(r.URL.Path, "/Users/.search"):
+		start, count := 1, 10
+		if r.Method == http.MethodPost {
+			var search struct{ StartIndex, Count int }
+			_ = json.Unmarshal(body, &search)
+			start, count = search.StartIndex, search.Count
+		} else {
+			start, _ = strconv.Atoi(r.URL.Query().Get("startIndex"))
+			count, _ = strconv.Atoi(r.URL.Query().Get("count"))
+		}
+		resources := []map[string]interface{}{}
+		for i := start; i < start+count && i <= s.users; i++ {
+			resources = append(resources, map[string]interface{}{"id": fmt.Sprintf("u%d", i), "userName": fmt.Sprintf("user%d", i)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"totalResults": s.users, "Resources": resources})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *scimServer) requests(method, suffix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.seen {
+		if r.Method == method && strings.HasSuffix(r.URL.Path, suffix) {
+			n++
+		}
+	}
+	return n
+}
+
+func newZPAScim(t *testing.T, srv *scimServer, setters ...zscaler.ConfigSetter) *zscaler.ScimZPAService {
+	t.Helper()
+	base, err := url.Parse(srv.URL + "/scim/1/")
+	require.NoError(t, err)
+	cfg := &zpa.ScimConfiguration{BaseURL: base, HTTPClient: http.DefaultClient, Logger: logger.NewNopLogger()}
+	cfg.ZPAScim.Client.ZPAScimToken = scimToken
+	cfg.ZPAScim.Client.ZPAIdPID = "idp1"
+	setters = append([]zscaler.ConfigSetter{zscaler.WithRateLimitMinWait(time.Millisecond), zscaler.WithRateLimitMaxWait(10 * time.Millisecond)}, setters...)
+	service, err := zscaler.NewZPAScimServiceWithSetters(cfg, setters...)
+	require.NoError(t, err)
+	require.NotNil(t, service.Client.Transport, "SCIM requests use the OneAPI transport")
+	return service
+}
+
+func TestScim_ZPASharesTransport(t *testing.T) {
+	srv := newScimServer(t, 25)
+	srv.fail.Store(1)
+	service := newZPAScim(t, srv)
+	ctx := context.Background()
+
+	users, _, err := zpascim.GetAllUsers(ctx, service, 10)
+	require.NoError(t, err, "the 503 is retried")
+	assert.Len(t, users, 25)
+	assert.Equal(t, 4, srv.requests(http.MethodGet, "/scim/1/idp1/v2/Users"), "three pages and one retry")
+
+	srv.mu.Lock()
+	first := srv.seen[0]
+	srv.mu.Unlock()
+	assert.Equal(t, "application/scim+json", first.Header.Get("Content-Type"))
+
+	var ids []string
+	for user, err := range zpascim.IterAllUsers(ctx, service, 10) {
+		require.NoError(t, err)
+		ids = append(ids, user.ID)
+		if len(ids) == 12 {
+			break
+		}
+	}
+	assert.Equal(t, "u12", ids[11])
+	assert.Equal(t, 6, srv.requests(http.MethodGet, "/scim/1/idp1/v2/Users"), "iteration stops fetching pages when the loop ends")
+
+	_, _, err = zpascim.GetUser(ctx, service, "missing")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errorx.ErrNotFound))
+	var apiErr *errorx.ErrorResponse
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "User missing not found", apiErr.Parsed.Message)
+}
+
+func TestScim_PatchOperations(t *testing.T) {
+	srv := newScimServer(t, 1)
+	service := newZPAScim(t, srv)
+
+	patch := scim.NewPatch().Replace("active", false).AddMembers("u1").RemoveMembers("u2")
+	group, resp, err := zpascim.PatchGroupOperations(context.Background(), service, "g1", patch)
+	require.NoError(t, err)
+	assert.Nil(t, group)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var sent map[string]interface{}
+	require.NoError(t, json.Unmarshal(srv.body["PATCH /scim/1/idp1/v2/Groups/g1"], &sent))
+	assert.Equal(t, []interface{}{scim.PatchOpSchema}, sent["schemas"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"op": "replace", "path": "active", "value": false},
+		map[string]interface{}{"op": "add", "path": "members", "value": []interface{}{map[string]interface{}{"value": "u1"}}},
+		map[string]interface{}{"op": "remove", "path": `members[value eq "u2"]`},
+	}, sent["Operations"])
+
+	_, _, err = zpascim.PatchUserOperations(context.Background(), service, "u1", scim.NewPatch())
+	assert.Error(t, err, "an empty patch is rejected before it is sent")
+	assert.Error(t, scim.NewPatch().Remove("").Validate())
+	assert.Error(t, scim.NewPatch().Add("title", nil).Validate())
+	assert.Equal(t, 1, srv.requests(http.MethodPatch, ""))
+}
+
+func TestScim_ZIADryRunStillSearches(t *testing.T) {
+	srv := newScimServer(t, 3)
+	base, err := url.Parse(srv.URL + "/tenant/scim")
+	require.NoError(t, err)
+	cfg := &zia.ScimConfiguration{BaseURL: base, HTTPClient: http.DefaultClient, Logger: logger.NewNopLogger()}
+	cfg.ZIAScim.Client.ZIAScimApiToken = scimToken
+	service, err := zscaler.NewZIAScimServiceWithSetters(cfg, zscaler.WithDryRun(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var names []string
+	for user, err := range ziascim.IterAllUsers(ctx, service) {
+		require.NoError(t, err)
+		names = append(names, user.UserName)
+	}
+	assert.Equal(t, []string{"user1", "user2", "user3"}, names, "searches are reads and reach the API")
+
+	_, _, err = ziascim.PatchUserOperations(ctx, service, "u1", scim.NewPatch().Replace("displayName", "x"))
+	require.NoError(t, err)
+	assert.Zero(t, srv.requests(http.MethodPatch, ""))
+	require.NotNil(t, service.Plan())
+	calls := service.Plan().Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "zia", calls[0].Product)
+	assert.Equal(t, srv.URL+"/tenant/scim/Users/u1", calls[0].Endpoint)
+}
+
+func TestScim_SetupErrorsAreReturned(t *testing.T) {
+	base, err := url.Parse("https://scim.example.com/scim/1/")
+	require.NoError(t, err)
+	cfg := &zpa.ScimConfiguration{BaseURL: base, HTTPClient: http.DefaultClient, Logger: logger.NewNopLogger()}
+	missing := zscaler.WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	_, err = zscaler.NewZPAScimServiceWithSetters(cfg, missing)
+	assert.Error(t, err)
+	_, err = zscaler.NewZIAScimServiceWithSetters(&zia.ScimConfiguration{BaseURL: base, HTTPClient: http.DefaultClient, Logger: logger.NewNopLogger()}, missing)
+	assert.Error(t, err)
+
+	service := zscaler.NewZPAScimService(cfg)
+	require.NotNil(t, service)
+	assert.NotNil(t, service.Client.Transport, "without setters the shared transport is set up")
+}
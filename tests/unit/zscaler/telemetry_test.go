@@ -0,0 +1,125 @@
+// Package zscaler provides unit tests for OneAPI client tracing and metrics
+package zscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/cache"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry_SpansPerCallAndAttempt(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 7, Name: "seeded"})
+	require.NoError(t, err)
+	srv.InjectFault(zscalertest.EditLockFault("/zia/api/v1/ruleLabels/7", 1))
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	service := newFakeService(t, srv,
+		zscaler.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		zscaler.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		zscaler.WithRateLimitMinWait(10*time.Millisecond),
+	)
+
+	_, err = rule_labels.Get(context.Background(), service, 7)
+	require.NoError(t, err)
+
+	var call sdktrace.ReadOnlySpan
+	var attempts []sdktrace.ReadOnlySpan
+	for _, s := range spans.Ended() {
+		switch s.Name() {
+		case "zia GET /zia/api/v1/ruleLabels/{id}":
+			call = s
+		case "GET":
+			if spanAttr(s, "url.template").AsString() == "/zia/api/v1/ruleLabels/{id}" {
+				attempts = append(attempts, s)
+			}
+		}
+	}
+	require.NotNil(t, call, "expected a span for the logical call")
+	require.Len(t, attempts, 2)
+
+	assert.Equal(t, "zia", spanAttr(call, "zscaler.product").AsString())
+	assert.EqualValues(t, 200, spanAttr(call, "http.response.status_code").AsInt64())
+	assert.EqualValues(t, 2, spanAttr(call, "zscaler.attempt").AsInt64())
+	assert.Greater(t, spanAttr(call, "zscaler.wait.total").AsFloat64(), 0.0)
+
+	for _, a := range attempts {
+		assert.Equal(t, call.SpanContext().SpanID(), a.Parent().SpanID())
+	}
+	assert.EqualValues(t, 409, spanAttr(attempts[0], "http.response.status_code").AsInt64())
+	assert.EqualValues(t, 2, spanAttr(attempts[1], "zscaler.attempt").AsInt64())
+	assert.Equal(t, "edit_lock", spanAttr(attempts[1], "zscaler.retry.reason").AsString())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	assert.EqualValues(t, 1, sumCounter(rm, "zscaler.sdk.requests"))
+	assert.EqualValues(t, 1, sumCounter(rm, "zscaler.sdk.retries"))
+	assert.GreaterOrEqual(t, sumCounter(rm, "zscaler.sdk.token.renewals"), int64(1))
+}
+
+func TestTelemetry_CacheHitMetrics(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 7, Name: "seeded"})
+	require.NoError(t, err)
+
+	responseCache, err := cache.NewCache(time.Minute, time.Minute, 16)
+	require.NoError(t, err)
+	reader := sdkmetric.NewManualReader()
+	service := newFakeService(t, srv,
+		zscaler.WithCache(true),
+		zscaler.WithCacheManager(responseCache),
+		zscaler.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	for i := 0; i < 2; i++ {
+		_, err = rule_labels.Get(context.Background(), service, 7)
+		require.NoError(t, err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	assert.EqualValues(t, 1, sumCounter(rm, "zscaler.sdk.cache.misses"))
+	assert.EqualValues(t, 1, sumCounter(rm, "zscaler.sdk.cache.hits"))
+	assert.EqualValues(t, 2, sumCounter(rm, "zscaler.sdk.requests"))
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func sumCounter(rm metricdata.ResourceMetrics, name string) int64 {
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,131 @@
+// Package zscaler provides unit tests for the multi-tenant client pool
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func newPoolTenant(t *testing.T, name string, groups int) (zscaler.Tenant, *zscalertest.Server) {
+	t.Helper()
+	srv := zscalertest.NewServer(zscalertest.WithCredentials(name+"-id", name+"-secret"))
+	t.Cleanup(srv.Close)
+	seed := make([]interface{}, 0, groups)
+	for i := 0; i < groups; i++ {
+		seed = append(seed, segmentgroup.SegmentGroup{Name: fmt.Sprintf("%s-group-%d", name, i)})
+	}
+	_, err := srv.Seed("/zpa/mgmtconfig/v1/admin/customers/"+zscalertest.CustomerID+"/segmentGroup", seed...)
+	require.NoError(t, err)
+	return zscaler.Tenant{
+		Name:         name,
+		ClientID:     name + "-id",
+		ClientSecret: name + "-secret",
+		Setters:      []zscaler.ConfigSetter{zscaler.WithBaseURL(srv.URL)},
+	}, srv
+}
+
+func newTestTenantPool(t *testing.T) *zscaler.TenantPool {
+	t.Helper()
+	pool := zscaler.NewTenantPool(
+		zscaler.WithVanityDomain(zscalertest.VanityDomain),
+		zscaler.WithZPACustomerID(zscalertest.CustomerID),
+		zscaler.WithCache(false),
+	)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestTenantPool_RunTenants(t *testing.T) {
+	pool := newTestTenantPool(t)
+	acme, acmeSrv := newPoolTenant(t, "acme", 3)
+	globex, _ := newPoolTenant(t, "globex", 5)
+	broken, _ := newPoolTenant(t, "initech", 1)
+	broken.ClientSecret = "wrong"
+	require.NoError(t, pool.Add(globex, acme, broken))
+	assert.Error(t, pool.Add(zscaler.Tenant{Name: "acme"}))
+	assert.Equal(t, []string{"acme", "globex", "initech"}, pool.Tenants())
+
+	var running, peak atomic.Int32
+	count := func(ctx context.Context, tenant string, service *zscaler.Service) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		groups, _, err := segmentgroup.GetAll(ctx, service)
+		return len(groups), err
+	}
+
+	results := zscaler.RunTenants(context.Background(), pool, 2, count)
+	require.Len(t, results, 3)
+	assert.Equal(t, "acme", results[0].Tenant)
+	assert.Equal(t, 3, results[0].Value)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 5, results[1].Value)
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, []string{"initech"}, results.Failed())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	var tenantErr *zscaler.TenantError
+	require.True(t, errors.As(results.Err(), &tenantErr))
+	assert.Equal(t, "initech", tenantErr.Tenant)
+
+	// The second run reuses the authenticated clients.
+	results = zscaler.RunTenants(context.Background(), pool, 2, count, "acme")
+	require.Len(t, results, 1)
+	assert.NoError(t, results.Err())
+	assert.Equal(t, 1, countRequests(acmeSrv, http.MethodPost, "/oauth2/v1/token"))
+}
+
+func TestTenantPool_ServiceAndClose(t *testing.T) {
+	pool := newTestTenantPool(t)
+	acme, _ := newPoolTenant(t, "acme", 1)
+	require.NoError(t, pool.Add(acme))
+
+	first, err := pool.Service("acme")
+	require.NoError(t, err)
+	second, err := pool.Service("acme")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	_, err = pool.Service("unknown")
+	assert.Error(t, err)
+
+	assert.True(t, pool.Remove("acme"))
+	assert.Empty(t, pool.Tenants())
+
+	pool.Close()
+	_, err = pool.Service("acme")
+	assert.Error(t, err)
+	assert.Error(t, pool.Add(acme))
+}
+
+func TestTenantPool_CancelledContext(t *testing.T) {
+	pool := newTestTenantPool(t)
+	acme, _ := newPoolTenant(t, "acme", 1)
+	require.NoError(t, pool.Add(acme))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := zscaler.RunTenants(ctx, pool, 1, func(ctx context.Context, tenant string, service *zscaler.Service) (bool, error) {
+		return true, nil
+	})
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+	assert.False(t, results[0].Value)
+}
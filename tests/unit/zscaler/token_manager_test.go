@@ -0,0 +1,176 @@
+// Package zscaler provides unit tests for on-demand OAuth2 token refresh
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+// tokenEvents records the token lifecycle events of a client.
+type tokenEvents struct {
+	mu     sync.Mutex
+	events []zscaler.TokenEvent
+}
+
+func (e *tokenEvents) handle(event zscaler.TokenEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *tokenEvents) count(typ zscaler.TokenEventType) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, event := range e.events {
+		if event.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+const segmentGroupPath = "/zpa/mgmtconfig/v1/admin/customers/" + zscalertest.CustomerID + "/segmentGroup"
+
+func TestTokenManager_RefreshWithinSkew(t *testing.T) {
+	srv := zscalertest.NewServer(zscalertest.WithTokenLifetime(90 * time.Second))
+	defer srv.Close()
+	ctx := context.Background()
+
+	service := newFakeService(t, srv)
+	for i := 0; i < 3; i++ {
+		_, _, err := segmentgroup.GetAll(ctx, service)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, countRequests(srv, http.MethodPost, "/oauth2/v1/token"), "the token is reused outside the skew window")
+
+	srv = zscalertest.NewServer(zscalertest.WithTokenLifetime(90 * time.Second))
+	defer srv.Close()
+	service = newFakeService(t, srv, zscaler.WithTokenRefreshSkew(2*time.Minute))
+	for i := 0; i < 2; i++ {
+		_, _, err := segmentgroup.GetAll(ctx, service)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, countRequests(srv, http.MethodPost, "/oauth2/v1/token"), "a token inside the skew window is refreshed before use")
+}
+
+func TestTokenManager_SessionInvalidSharesOneRefresh(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	seed := make([]interface{}, 0, 8)
+	for i := 0; i < 8; i++ {
+		seed = append(seed, segmentgroup.SegmentGroup{Name: fmt.Sprintf("group-%d", i)})
+	}
+	ids, err := srv.Seed(segmentGroupPath, seed...)
+	require.NoError(t, err)
+
+	events := &tokenEvents{}
+	service := newFakeService(t, srv, zscaler.WithTokenEventHandler(events.handle))
+	srv.ExpireTokens()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			_, _, errs[i] = segmentgroup.Get(context.Background(), service, id)
+		}(i, id)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, countRequests(srv, http.MethodPost, "/oauth2/v1/token"), "requests rejected with the same token share one refresh")
+	assert.Equal(t, len(ids), events.count(zscaler.TokenEventInvalidated))
+	assert.Equal(t, 2, events.count(zscaler.TokenEventRefreshed))
+}
+
+func TestTokenManager_SessionInvalidBypassesTokenCache(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed(segmentGroupPath, segmentgroup.SegmentGroup{Name: "group"})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "token.json")
+
+	service := newFakeService(t, srv, zscaler.WithTokenCache(true, path))
+	srv.ExpireTokens()
+
+	_, _, err = segmentgroup.Get(context.Background(), service, ids[0])
+	require.NoError(t, err, "the rejected token is not served again from the cache")
+	assert.Equal(t, 2, countRequests(srv, http.MethodPost, "/oauth2/v1/token"))
+
+	// Later clients share the replacement token.
+	other := newFakeService(t, srv, zscaler.WithTokenCache(true, path))
+	_, _, err = segmentgroup.Get(context.Background(), other, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRequests(srv, http.MethodPost, "/oauth2/v1/token"))
+}
+
+func TestTokenManager_BackoffAndEvents(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+
+	var mu sync.Mutex
+	calls := 0
+	source := zscaler.TokenSourceFunc(func(ctx context.Context) (*zscaler.AuthToken, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls < 3 {
+			return nil, errorx.New(errorx.ErrTimeout, "token endpoint timed out")
+		}
+		return &zscaler.AuthToken{TokenType: "Bearer", AccessToken: "token", ExpiresIn: "3600"}, nil
+	})
+
+	events := &tokenEvents{}
+	_, err := srv.NewService(
+		zscaler.WithTokenSource(source),
+		zscaler.WithTokenRefreshBackoff(time.Millisecond),
+		zscaler.WithTokenEventHandler(events.handle),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	require.Len(t, events.events, 3)
+	assert.Equal(t, zscaler.TokenEventRefreshFailed, events.events[0].Type)
+	assert.Equal(t, 2, events.events[1].Attempt)
+	assert.ErrorIs(t, events.events[1].Err, errorx.ErrTimeout)
+	refreshed := events.events[2]
+	assert.Equal(t, zscaler.TokenEventRefreshed, refreshed.Type)
+	assert.Equal(t, zscaler.TokenRefreshInitial, refreshed.Reason)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), refreshed.Expiry, 5*time.Second, "expiry is derived from expires_in")
+
+	// Giving up after the configured number of retries.
+	calls = 0
+	_, err = srv.NewService(
+		zscaler.WithTokenSource(source),
+		zscaler.WithTokenRefreshBackoff(time.Millisecond),
+		zscaler.WithTokenRefreshMaxRetries(1),
+	)
+	require.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTokenManager_RejectedCredentialsAreNotRetried(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+
+	_, err := srv.NewService(zscaler.WithClientSecret("wrong"), zscaler.WithTokenRefreshBackoff(time.Millisecond))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errorx.ErrUnauthorized))
+	assert.Equal(t, 1, countRequests(srv, http.MethodPost, "/oauth2/v1/token"))
+}
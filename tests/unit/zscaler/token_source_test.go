@@ -0,0 +1,212 @@
+// Package zscaler provides unit tests for OneAPI token sources
+package zscaler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+)
+
+// countingTokenSource hands out a new token on every call and counts the calls
+func countingTokenSource(calls *int32, lifetime time.Duration) zscaler.TokenSource {
+	return zscaler.TokenSourceFunc(func(ctx context.Context) (*zscaler.AuthToken, error) {
+		n := atomic.AddInt32(calls, 1)
+		return &zscaler.AuthToken{
+			TokenType:   "Bearer",
+			AccessToken: "token-" + string(rune('a'+n-1)),
+			ExpiresIn:   json.Number("3600"),
+			Expiry:      time.Now().Add(lifetime),
+		}, nil
+	})
+}
+
+func TestFileTokenSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reuses valid cached token across instances", func(t *testing.T) {
+		var calls int32
+		path := filepath.Join(t.TempDir(), "token.json")
+		base := countingTokenSource(&calls, time.Hour)
+
+		first, err := zscaler.NewFileTokenSource(base, path, []byte("key"))
+		require.NoError(t, err)
+		second, err := zscaler.NewFileTokenSource(base, path, []byte("key"))
+		require.NoError(t, err)
+
+		tok1, err := first.Token(context.Background())
+		require.NoError(t, err)
+		tok2, err := second.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, tok1.AccessToken, tok2.AccessToken)
+		assert.WithinDuration(t, tok1.Expiry, tok2.Expiry, time.Second)
+	})
+
+	t.Run("Encrypts token at rest", func(t *testing.T) {
+		var calls int32
+		path := filepath.Join(t.TempDir(), "token.json")
+		ts, err := zscaler.NewFileTokenSource(countingTokenSource(&calls, time.Hour), path, []byte("key"))
+		require.NoError(t, err)
+
+		tok, err := ts.Token(context.Background())
+		require.NoError(t, err)
+
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), tok.AccessToken)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("Refetches when key does not match", func(t *testing.T) {
+		var calls int32
+		path := filepath.Join(t.TempDir(), "token.json")
+		base := countingTokenSource(&calls, time.Hour)
+
+		writer, err := zscaler.NewFileTokenSource(base, path, []byte("key-one"))
+		require.NoError(t, err)
+		reader, err := zscaler.NewFileTokenSource(base, path, []byte("key-two"))
+		require.NoError(t, err)
+
+		_, err = writer.Token(context.Background())
+		require.NoError(t, err)
+		_, err = reader.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Refetches when cached token is close to expiry", func(t *testing.T) {
+		var calls int32
+		path := filepath.Join(t.TempDir(), "token.json")
+		ts, err := zscaler.NewFileTokenSource(countingTokenSource(&calls, time.Minute), path, []byte("key"),
+			zscaler.WithTokenCacheMinTTL(5*time.Minute))
+		require.NoError(t, err)
+
+		_, err = ts.Token(context.Background())
+		require.NoError(t, err)
+		_, err = ts.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Concurrent callers share a single fetch", func(t *testing.T) {
+		var calls int32
+		path := filepath.Join(t.TempDir(), "token.json")
+		ts, err := zscaler.NewFileTokenSource(countingTokenSource(&calls, time.Hour), path, []byte("key"))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := ts.Token(context.Background())
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Propagates base errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token.json")
+		ts, err := zscaler.NewFileTokenSource(zscaler.TokenSourceFunc(func(ctx context.Context) (*zscaler.AuthToken, error) {
+			return nil, errors.New("boom")
+		}), path, []byte("key"))
+		require.NoError(t, err)
+
+		_, err = ts.Token(context.Background())
+		assert.EqualError(t, err, "boom")
+		_, statErr := os.Stat(path)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("Validates arguments", func(t *testing.T) {
+		var calls int32
+		_, err := zscaler.NewFileTokenSource(nil, "path", []byte("key"))
+		assert.Error(t, err)
+		_, err = zscaler.NewFileTokenSource(countingTokenSource(&calls, time.Hour), "", []byte("key"))
+		assert.Error(t, err)
+		_, err = zscaler.NewFileTokenSource(countingTokenSource(&calls, time.Hour), "path", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestOneAPIClient_CustomTokenSource(t *testing.T) {
+	var calls int32
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithTokenSource(countingTokenSource(&calls, time.Hour)),
+	)
+	require.NoError(t, err)
+
+	service, err := zscaler.NewOneAPIClient(cfg)
+	require.NoError(t, err)
+	defer service.Client.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	require.NotNil(t, cfg.Zscaler.Client.AuthToken)
+	assert.Equal(t, "token-a", cfg.Zscaler.Client.AuthToken.AccessToken)
+}
+
+func TestOneAPIClient_TokenCache(t *testing.T) {
+	var calls int32
+	path := filepath.Join(t.TempDir(), "token.json")
+
+	for i := 0; i < 2; i++ {
+		cfg, err := zscaler.NewConfiguration(
+			zscaler.WithTokenSource(countingTokenSource(&calls, time.Hour)),
+			zscaler.WithTokenCache(true, path),
+			zscaler.WithTokenCacheKey([]byte("cache-key")),
+		)
+		require.NoError(t, err)
+
+		service, err := zscaler.NewOneAPIClient(cfg)
+		require.NoError(t, err)
+		service.Client.Close()
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOneAPIClient_TokenCacheRequiresKeyWithCustomTokenSource(t *testing.T) {
+	var calls int32
+	cfg, err := zscaler.NewConfiguration(
+		zscaler.WithTokenSource(countingTokenSource(&calls, time.Hour)),
+		zscaler.WithTokenCache(true, filepath.Join(t.TempDir(), "token.json")),
+	)
+	require.NoError(t, err)
+
+	_, err = zscaler.NewOneAPIClient(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WithTokenCacheKey")
+	assert.Zero(t, atomic.LoadInt32(&calls), "no token is fetched without a cache key")
+}
+
+func TestDefaultTokenSource(t *testing.T) {
+	t.Parallel()
+
+	cfg := &zscaler.Configuration{}
+	cfg.Zscaler.Client.ClientID = "client"
+	_, err := zscaler.DefaultTokenSource(cfg).Token(context.Background())
+	assert.Error(t, err)
+
+	cfg.Zscaler.Client.PrivateKey = []byte("not-a-pem")
+	_, err = zscaler.DefaultTokenSource(cfg).Token(context.Background())
+	assert.Error(t, err)
+}
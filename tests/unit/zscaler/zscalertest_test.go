@@ -0,0 +1,181 @@
+// Package zscaler provides unit tests for the zscalertest fake OneAPI server
+package zscaler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	zidcommon "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zidentity/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zidentity/services/groups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/segmentgroup"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zscalertest"
+)
+
+func newFakeService(t *testing.T, srv *zscalertest.Server, setters ...zscaler.ConfigSetter) *zscaler.Service {
+	t.Helper()
+	service, err := srv.NewService(setters...)
+	require.NoError(t, err)
+	t.Cleanup(service.Client.Close)
+	return service
+}
+
+func countRequests(srv *zscalertest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestZscalerTest_ZIACRUD(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	ctx := context.Background()
+
+	created, _, err := rule_labels.Create(ctx, service, &rule_labels.RuleLabels{Name: "label-a", Description: "first"})
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+
+	got, err := rule_labels.Get(ctx, service, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "label-a", got.Name)
+
+	_, _, err = rule_labels.Create(ctx, service, &rule_labels.RuleLabels{Name: "label-a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DUPLICATE_ITEM")
+
+	got.Description = "updated"
+	updated, _, err := rule_labels.Update(ctx, service, created.ID, got)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", updated.Description)
+
+	_, err = rule_labels.Delete(ctx, service, created.ID)
+	require.NoError(t, err)
+
+	_, err = rule_labels.Get(ctx, service, created.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RESOURCE_NOT_FOUND")
+	assert.Empty(t, srv.Objects("/zia/api/v1/ruleLabels"))
+}
+
+func TestZscalerTest_ZIAPagination(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+
+	labels := make([]interface{}, 0, 1500)
+	for i := 0; i < 1500; i++ {
+		labels = append(labels, rule_labels.RuleLabels{Name: fmt.Sprintf("label-%04d", i)})
+	}
+	ids, err := srv.Seed("/zia/api/v1/ruleLabels", labels...)
+	require.NoError(t, err)
+	require.Len(t, ids, 1500)
+
+	service := newFakeService(t, srv)
+	all, err := rule_labels.GetAll(context.Background(), service)
+	require.NoError(t, err)
+	assert.Len(t, all, 1500)
+	assert.Equal(t, 2, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels"))
+}
+
+func TestZscalerTest_ZPACRUDAndPagination(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+	service := newFakeService(t, srv)
+	ctx := context.Background()
+
+	collection := "/zpa/mgmtconfig/v1/admin/customers/" + zscalertest.CustomerID + "/segmentGroup"
+	seed := make([]interface{}, 0, 700)
+	for i := 0; i < 700; i++ {
+		seed = append(seed, segmentgroup.SegmentGroup{Name: fmt.Sprintf("seeded-%03d", i), Enabled: true})
+	}
+	_, err := srv.Seed(collection, seed...)
+	require.NoError(t, err)
+
+	created, _, err := segmentgroup.Create(ctx, service, &segmentgroup.SegmentGroup{Name: "app-group", Enabled: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.ID)
+
+	created.Description = "changed"
+	_, err = segmentgroup.Update(ctx, service, created.ID, created)
+	require.NoError(t, err)
+
+	byName, _, err := segmentgroup.GetByName(ctx, service, "app-group")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, byName.ID)
+	assert.Equal(t, "changed", byName.Description)
+
+	all, _, err := segmentgroup.GetAll(ctx, service)
+	require.NoError(t, err)
+	assert.Len(t, all, 701)
+
+	_, err = segmentgroup.Delete(ctx, service, created.ID)
+	require.NoError(t, err)
+	_, _, err = segmentgroup.Get(ctx, service, created.ID)
+	assert.Error(t, err)
+}
+
+func TestZscalerTest_ZIdentityPagination(t *testing.T) {
+	srv := zscalertest.NewServer()
+	defer srv.Close()
+
+	seed := make([]interface{}, 0, 250)
+	for i := 0; i < 250; i++ {
+		seed = append(seed, groups.Groups{Name: fmt.Sprintf("group-%03d", i)})
+	}
+	_, err := srv.Seed("/admin/api/v1/groups", seed...)
+	require.NoError(t, err)
+
+	service := newFakeService(t, srv)
+	all, err := groups.GetAll(context.Background(), service, &zidcommon.PaginationQueryParams{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, all, 250)
+	assert.Equal(t, 3, countRequests(srv, http.MethodGet, "/admin/api/v1/groups"))
+}
+
+func TestZscalerTest_Faults(t *testing.T) {
+	t.Run("Retries after rate limiting", func(t *testing.T) {
+		srv := zscalertest.NewServer()
+		defer srv.Close()
+		_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 7, Name: "seeded"})
+		require.NoError(t, err)
+		service := newFakeService(t, srv)
+
+		srv.InjectFault(zscalertest.RateLimitFault("/zia/api/v1/ruleLabels", 1, 100*time.Millisecond))
+		got, err := rule_labels.Get(context.Background(), service, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "seeded", got.Name)
+		assert.Equal(t, 2, countRequests(srv, http.MethodGet, "/zia/api/v1/ruleLabels/7"))
+	})
+
+	t.Run("Refreshes the token on SESSION_NOT_VALID", func(t *testing.T) {
+		srv := zscalertest.NewServer()
+		defer srv.Close()
+		_, err := srv.Seed("/zia/api/v1/ruleLabels", rule_labels.RuleLabels{ID: 7, Name: "seeded"})
+		require.NoError(t, err)
+		service := newFakeService(t, srv)
+		tokenRequests := countRequests(srv, http.MethodPost, "/oauth2/v1/token")
+
+		srv.ExpireTokens()
+		got, err := rule_labels.Get(context.Background(), service, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "seeded", got.Name)
+		assert.Greater(t, countRequests(srv, http.MethodPost, "/oauth2/v1/token"), tokenRequests)
+	})
+
+	t.Run("Rejects unknown credentials", func(t *testing.T) {
+		srv := zscalertest.NewServer()
+		defer srv.Close()
+		_, err := srv.NewService(zscaler.WithClientSecret("wrong"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,433 @@
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultActivationPollInterval = 2 * time.Second
+	defaultActivationTimeout      = 5 * time.Minute
+	maxActivationRetryDelay       = 5 * time.Minute
+)
+
+// ActivationStatus is the activation status of a tenant as reported by an
+// ActivationBackend.
+type ActivationStatus struct {
+	// Status is the raw status returned by the API.
+	Status string
+	// InProgress is set while an activation is running.
+	InProgress bool
+	// Failed is set when the last activation failed.
+	Failed bool
+	// OthersPending is set when changes of other admins still await activation.
+	OthersPending bool
+}
+
+// ActivationBackend activates the changes of one product. The zia and ztw
+// activation packages provide backends.
+type ActivationBackend interface {
+	// Product is the product whose mutations are tracked, e.g. "zia".
+	Product() string
+	// Tracks reports whether a mutation of endpoint needs an activation.
+	Tracks(endpoint string) bool
+	// Preflight checks that the tenant can activate before each activation.
+	Preflight(ctx context.Context) error
+	// Status reads the activation status.
+	Status(ctx context.Context) (ActivationStatus, error)
+	// Activate requests the activation of the pending changes.
+	Activate(ctx context.Context) (ActivationStatus, error)
+}
+
+// ActivationState is the state of an ActivationManager.
+type ActivationState string
+
+const (
+	// ActivationIdle means no tracked change awaits activation.
+	ActivationIdle ActivationState = "idle"
+	// ActivationPending means tracked changes await activation.
+	ActivationPending ActivationState = "pending"
+	// ActivationRunning means an activation is being requested or polled.
+	ActivationRunning ActivationState = "activating"
+	// ActivationClosed means the manager was closed and tracks nothing.
+	ActivationClosed ActivationState = "closed"
+)
+
+// ActivationEventType identifies an ActivationEvent.
+type ActivationEventType string
+
+const (
+	ActivationEventTracked   ActivationEventType = "tracked"
+	ActivationEventStarted   ActivationEventType = "started"
+	ActivationEventSucceeded ActivationEventType = "succeeded"
+	ActivationEventFailed    ActivationEventType = "failed"
+)
+
+// ActivationEvent reports a transition of an ActivationManager. Pending is the
+// number of tracked changes the event concerns.
+type ActivationEvent struct {
+	Type          ActivationEventType
+	Endpoint      string
+	Pending       int
+	Status        string
+	OthersPending bool
+	Err           error
+}
+
+// ActivationOption configures an ActivationManager.
+type ActivationOption func(*activationOptions)
+
+type activationOptions struct {
+	debounce        time.Duration
+	maxDelay        time.Duration
+	pollInterval    time.Duration
+	timeout         time.Duration
+	activateOnClose bool
+	onEvent         func(ActivationEvent)
+}
+
+// WithActivationDebounce activates automatically once no change was tracked
+// for d. Without it changes are only activated by Commit or Close.
+func WithActivationDebounce(d time.Duration) ActivationOption {
+	return func(o *activationOptions) {
+		o.debounce = d
+	}
+}
+
+// WithActivationMaxDelay bounds how long a debounced activation can be put
+// off by a steady stream of changes, counted from the first pending change.
+func WithActivationMaxDelay(d time.Duration) ActivationOption {
+	return func(o *activationOptions) {
+		o.maxDelay = d
+	}
+}
+
+// WithActivationPollInterval sets how often the status is read while an
+// activation is in progress. The default is 2 seconds.
+func WithActivationPollInterval(d time.Duration) ActivationOption {
+	return func(o *activationOptions) {
+		o.pollInterval = d
+	}
+}
+
+// WithActivationTimeout bounds each activation, including waiting for the
+// tenant to settle. The default is 5 minutes.
+func WithActivationTimeout(d time.Duration) ActivationOption {
+	return func(o *activationOptions) {
+		o.timeout = d
+	}
+}
+
+// WithActivateOnClose makes Close activate the pending changes.
+func WithActivateOnClose(enabled bool) ActivationOption {
+	return func(o *activationOptions) {
+		o.activateOnClose = enabled
+	}
+}
+
+// WithActivationEventHandler registers fn to receive every ActivationEvent.
+// It is called synchronously and must not call back into the manager.
+func WithActivationEventHandler(fn func(ActivationEvent)) ActivationOption {
+	return func(o *activationOptions) {
+		o.onEvent = fn
+	}
+}
+
+// ErrActivationFailed is returned when the tenant reports a failed activation.
+var ErrActivationFailed = errors.New("activation failed")
+
+// ActivationManager tracks the mutations a client makes to one product and
+// activates them in batches, either explicitly with Commit, after a quiet
+// period set with WithActivationDebounce, or on Close. Each activation runs
+// the backend preflight, waits for an activation already in progress, requests
+// the activation and polls the status until the tenant settles. Changes of a
+// failed activation stay pending; with a debounce they are retried after a
+// delay that doubles with each consecutive failure, up to 5 minutes unless the
+// debounce is longer. It is safe for concurrent use.
+type ActivationManager struct {
+	client  *Client
+	backend ActivationBackend
+	opts    activationOptions
+	remove  func()
+
+	mu        sync.Mutex
+	pending   int
+	firstSeen time.Time
+	timer     *time.Timer
+	running   chan struct{}
+	lastErr   error
+	failures  int
+	closed    bool
+}
+
+// NewActivationManager starts tracking the mutations service makes to the
+// product of backend. Only requests sent by the OneAPI client are tracked.
+func NewActivationManager(service *Service, backend ActivationBackend, opts ...ActivationOption) *ActivationManager {
+	m := &ActivationManager{
+		client:  service.Client,
+		backend: backend,
+		opts: activationOptions{
+			pollInterval: defaultActivationPollInterval,
+			timeout:      defaultActivationTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+	m.remove = service.Client.onMutation(m.track)
+	return m
+}
+
+// Pending returns the number of tracked changes awaiting activation.
+func (m *ActivationManager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pending
+}
+
+// State returns the current state of the manager.
+func (m *ActivationManager) State() ActivationState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.running != nil:
+		return ActivationRunning
+	case m.closed:
+		return ActivationClosed
+	case m.pending > 0:
+		return ActivationPending
+	}
+	return ActivationIdle
+}
+
+// Err returns the error of the last activation, or nil if it succeeded.
+func (m *ActivationManager) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
+// Commit activates the pending changes and waits until the tenant settles. It
+// first waits for an activation already running. Nothing is sent when no
+// change is pending.
+func (m *ActivationManager) Commit(ctx context.Context) error {
+	return m.run(ctx, false)
+}
+
+// Activate requests an activation even when no change was tracked, e.g. for
+// changes made outside the SDK, and waits until the tenant settles.
+func (m *ActivationManager) Activate(ctx context.Context) error {
+	return m.run(ctx, true)
+}
+
+// Close stops tracking. With WithActivateOnClose it first commits the pending
+// changes and returns the commit error.
+func (m *ActivationManager) Close(ctx context.Context) error {
+	var err error
+	if m.opts.activateOnClose {
+		err = m.Commit(ctx)
+	}
+	m.mu.Lock()
+	m.closed = true
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	m.mu.Unlock()
+	m.remove()
+	return err
+}
+
+// track records a successful mutation made through the client.
+func (m *ActivationManager) track(product, endpoint string) {
+	if product != m.backend.Product() || !m.backend.Tracks(endpoint) {
+		return
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	if m.pending == 0 {
+		m.firstSeen = time.Now()
+	}
+	m.pending++
+	pending := m.pending
+	m.scheduleLocked()
+	m.mu.Unlock()
+	m.emit(ActivationEvent{Type: ActivationEventTracked, Endpoint: endpoint, Pending: pending})
+}
+
+// scheduleLocked (re)arms the debounce timer. After failed activations the
+// delay backs off instead, so a rejected activation is not retried in a loop.
+func (m *ActivationManager) scheduleLocked() {
+	if m.opts.debounce <= 0 || m.closed || m.pending == 0 {
+		return
+	}
+	delay := m.opts.debounce
+	if m.failures > 0 {
+		limit := max(m.opts.debounce, maxActivationRetryDelay)
+		for i := 0; i < m.failures && delay < limit; i++ {
+			delay *= 2
+		}
+		if delay > limit {
+			delay = limit
+		}
+	} else if m.opts.maxDelay > 0 {
+		if left := time.Until(m.firstSeen.Add(m.opts.maxDelay)); left < delay {
+			delay = max(left, 0)
+		}
+	}
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	m.timer = time.AfterFunc(delay, func() {
+		if err := m.Commit(context.Background()); err != nil {
+			m.client.oauth2Credentials.Logger.Printf("[ERROR] Automatic %s activation failed: %v", m.backend.Product(), err)
+		}
+	})
+}
+
+func (m *ActivationManager) run(ctx context.Context, force bool) error {
+	for {
+		m.mu.Lock()
+		if running := m.running; running != nil {
+			m.mu.Unlock()
+			select {
+			case <-running:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if m.pending == 0 && !force {
+			m.mu.Unlock()
+			return nil
+		}
+		batch := m.pending
+		m.pending = 0
+		if m.timer != nil {
+			m.timer.Stop()
+			m.timer = nil
+		}
+		done := make(chan struct{})
+		m.running = done
+		m.mu.Unlock()
+
+		err := m.activate(ctx, batch)
+
+		m.mu.Lock()
+		m.running = nil
+		m.lastErr = err
+		if err != nil {
+			// The changes were not activated; they join any tracked meanwhile.
+			if m.pending == 0 {
+				m.firstSeen = time.Now()
+			}
+			m.pending += batch
+			m.failures++
+		} else {
+			m.failures = 0
+		}
+		m.scheduleLocked()
+		close(done)
+		m.mu.Unlock()
+		return err
+	}
+}
+
+// activate runs one activation of batch changes.
+func (m *ActivationManager) activate(ctx context.Context, batch int) error {
+	ctx, cancel := context.WithTimeout(ctx, m.opts.timeout)
+	defer cancel()
+	product := m.backend.Product()
+	m.emit(ActivationEvent{Type: ActivationEventStarted, Pending: batch})
+
+	status, err := m.activateAndWait(ctx)
+	if err == nil && status.Failed {
+		err = fmt.Errorf("%w: %s status %s", ErrActivationFailed, product, status.Status)
+	}
+	if err != nil {
+		m.emit(ActivationEvent{Type: ActivationEventFailed, Pending: batch, Status: status.Status, Err: err})
+		return err
+	}
+	m.client.oauth2Credentials.Logger.Printf("[INFO] Activated %d %s change(s), status %s", batch, product, status.Status)
+	if status.OthersPending {
+		m.client.oauth2Credentials.Logger.Printf("[WARN] Changes of other %s admins are still pending activation", product)
+	}
+	m.emit(ActivationEvent{Type: ActivationEventSucceeded, Pending: batch, Status: status.Status, OthersPending: status.OthersPending})
+	return nil
+}
+
+func (m *ActivationManager) activateAndWait(ctx context.Context) (ActivationStatus, error) {
+	if err := m.backend.Preflight(ctx); err != nil {
+		return ActivationStatus{}, err
+	}
+	// An activation started by another admin must finish first.
+	if _, err := m.waitSettled(ctx, ActivationStatus{InProgress: true}); err != nil {
+		return ActivationStatus{}, err
+	}
+	status, err := m.backend.Activate(ctx)
+	if err != nil {
+		return status, err
+	}
+	return m.waitSettled(ctx, status)
+}
+
+// waitSettled polls the status until no activation is in progress.
+func (m *ActivationManager) waitSettled(ctx context.Context, status ActivationStatus) (ActivationStatus, error) {
+	for first := true; status.InProgress; first = false {
+		if !first {
+			select {
+			case <-time.After(m.opts.pollInterval):
+			case <-ctx.Done():
+				return status, fmt.Errorf("waiting for %s activation: %w", m.backend.Product(), ctx.Err())
+			}
+		}
+		var err error
+		if status, err = m.backend.Status(ctx); err != nil {
+			return status, err
+		}
+	}
+	return status, nil
+}
+
+func (m *ActivationManager) emit(e ActivationEvent) {
+	if m.opts.onEvent != nil {
+		m.opts.onEvent(e)
+	}
+}
+
+// onMutation registers fn to be called after every successful mutating request
+// and returns a function removing it.
+func (c *Client) onMutation(fn func(product, endpoint string)) func() {
+	c.Lock()
+	defer c.Unlock()
+	if c.mutationHooks == nil {
+		c.mutationHooks = map[int]func(product, endpoint string){}
+	}
+	id := c.nextHook
+	c.nextHook++
+	c.mutationHooks[id] = fn
+	return func() {
+		c.Lock()
+		defer c.Unlock()
+		delete(c.mutationHooks, id)
+	}
+}
+
+func (c *Client) notifyMutation(endpoint string) {
+	c.Lock()
+	hooks := make([]func(product, endpoint string), 0, len(c.mutationHooks))
+	for _, fn := range c.mutationHooks {
+		hooks = append(hooks, fn)
+	}
+	c.Unlock()
+	product := c.productOf(endpoint)
+	for _, fn := range hooks {
+		fn(product, endpoint)
+	}
+}
@@ -0,0 +1,290 @@
+package zscaler
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	clientAssertionType            = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	clientAssertionAudience        = "https://api.zscaler.com"
+	defaultClientAssertionLifetime = 10 * time.Minute
+)
+
+// WithClientAssertionSigner authenticates with a JWT client assertion signed by
+// signer instead of a private key held in the configuration, for keys that
+// cannot be exported, such as keys kept in an HSM or a cloud KMS. RSA, ECDSA
+// (P-256, P-384, P-521) and Ed25519 keys are supported.
+func WithClientAssertionSigner(signer crypto.Signer) ConfigSetter {
+	return func(c *Configuration) {
+		c.ClientAssertionSigner = signer
+	}
+}
+
+// WithClientAssertionAlgorithm sets the JWS algorithm of the client assertion,
+// e.g. PS256 to sign with RSA-PSS. By default it follows the key: RS256 for RSA,
+// ES256/ES384/ES512 for ECDSA and EdDSA for Ed25519 keys.
+func WithClientAssertionAlgorithm(alg string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.ClientAssertion.Algorithm = alg
+	}
+}
+
+// WithClientAssertionKeyID sets the kid header of the client assertion.
+func WithClientAssertionKeyID(kid string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.ClientAssertion.KeyID = kid
+	}
+}
+
+// WithClientAssertionLifetime sets how long a client assertion is valid (10 minutes by default).
+func WithClientAssertionLifetime(lifetime time.Duration) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.ClientAssertion.Lifetime = lifetime
+	}
+}
+
+// WithClientAssertionCertificate adds the x5t and x5t#S256 thumbprints of cert
+// to the client assertion header.
+func WithClientAssertionCertificate(cert *x509.Certificate) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.ClientAssertion.Certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+}
+
+// WithClientAssertionID sets the function generating the jti claim of each
+// client assertion. Random UUIDs are used by default.
+func WithClientAssertionID(jti func() string) ConfigSetter {
+	return func(c *Configuration) {
+		c.clientAssertionID = jti
+	}
+}
+
+// usesClientAssertion reports whether the configuration authenticates with a JWT
+// client assertion rather than a client secret.
+func (c *Configuration) usesClientAssertion() bool {
+	return len(c.Zscaler.Client.PrivateKey) > 0 || c.ClientAssertionSigner != nil || c.PrivateKeySigner != nil
+}
+
+// clientAssertion builds and signs the JWT the client presents to the token endpoint.
+func (c *Configuration) clientAssertion() (string, error) {
+	creds := c.Zscaler.Client
+	opts := creds.ClientAssertion
+	lifetime := opts.Lifetime
+	if lifetime <= 0 {
+		lifetime = defaultClientAssertionLifetime
+	}
+	jti := uuid.NewString()
+	if c.clientAssertionID != nil {
+		jti = c.clientAssertionID()
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": creds.ClientID,
+		"sub": creds.ClientID,
+		"aud": clientAssertionAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(lifetime).Unix(),
+	}
+	if jti != "" {
+		claims["jti"] = jti
+	}
+
+	// A jose.Signer carries its own algorithm and headers.
+	if c.PrivateKeySigner != nil {
+		payload, err := json.Marshal(claims)
+		if err != nil {
+			return "", err
+		}
+		jws, err := c.PrivateKeySigner.Sign(payload)
+		if err != nil {
+			return "", fmt.Errorf("error signing JWT: %w", err)
+		}
+		return jws.CompactSerialize()
+	}
+
+	signer := c.ClientAssertionSigner
+	if signer == nil {
+		var err error
+		if signer, err = ParsePrivateKeyPEM(creds.PrivateKey); err != nil {
+			return "", fmt.Errorf("error parsing private key: %w", err)
+		}
+	}
+	method, err := signingMethodFor(signer.Public(), opts.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	if opts.KeyID != "" {
+		token.Header["kid"] = opts.KeyID
+	}
+	if len(opts.Certificate) > 0 {
+		der, err := certificateDER(opts.Certificate)
+		if err != nil {
+			return "", err
+		}
+		sha1Sum := sha1.Sum(der)
+		sha256Sum := sha256.Sum256(der)
+		token.Header["x5t"] = base64.RawURLEncoding.EncodeToString(sha1Sum[:])
+		token.Header["x5t#S256"] = base64.RawURLEncoding.EncodeToString(sha256Sum[:])
+	}
+	assertion, err := token.SignedString(signer)
+	if err != nil {
+		return "", fmt.Errorf("error signing JWT: %w", err)
+	}
+	return assertion, nil
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded PKCS#1 RSA, SEC 1 EC or PKCS#8 private key.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func certificateDER(data []byte) ([]byte, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	if _, err := x509.ParseCertificate(data); err != nil {
+		return nil, fmt.Errorf("error parsing client assertion certificate: %w", err)
+	}
+	return data, nil
+}
+
+// signingMethodFor returns the JWS signing method for the public key, using alg
+// when it is set.
+func signingMethodFor(pub crypto.PublicKey, alg string) (jwt.SigningMethod, error) {
+	alg = strings.ToUpper(alg)
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		switch alg {
+		case "", "RS256":
+			return &signerMethod{alg: "RS256", hash: crypto.SHA256}, nil
+		case "RS384":
+			return &signerMethod{alg: alg, hash: crypto.SHA384}, nil
+		case "RS512":
+			return &signerMethod{alg: alg, hash: crypto.SHA512}, nil
+		case "PS256":
+			return &signerMethod{alg: alg, hash: crypto.SHA256, pss: true}, nil
+		case "PS384":
+			return &signerMethod{alg: alg, hash: crypto.SHA384, pss: true}, nil
+		case "PS512":
+			return &signerMethod{alg: alg, hash: crypto.SHA512, pss: true}, nil
+		}
+	case *ecdsa.PublicKey:
+		var method *signerMethod
+		switch key.Curve {
+		case elliptic.P256():
+			method = &signerMethod{alg: "ES256", hash: crypto.SHA256, ecSize: 32}
+		case elliptic.P384():
+			method = &signerMethod{alg: "ES384", hash: crypto.SHA384, ecSize: 48}
+		case elliptic.P521():
+			method = &signerMethod{alg: "ES512", hash: crypto.SHA512, ecSize: 66}
+		default:
+			return nil, fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+		}
+		if alg == "" || alg == method.alg {
+			return method, nil
+		}
+	case ed25519.PublicKey:
+		if alg == "" || alg == "EDDSA" {
+			return &signerMethod{alg: "EdDSA"}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil, fmt.Errorf("algorithm %s cannot be used with a %T key", alg, pub)
+}
+
+// signerMethod signs JWTs with any crypto.Signer, so the private key never has
+// to leave the device or service holding it.
+type signerMethod struct {
+	alg    string
+	hash   crypto.Hash
+	pss    bool
+	ecSize int
+}
+
+func (m *signerMethod) Alg() string { return m.alg }
+
+func (m *signerMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+	if m.hash == 0 {
+		// Ed25519 signs the message itself.
+		return signer.Sign(rand.Reader, []byte(signingString), crypto.Hash(0))
+	}
+	h := m.hash.New()
+	h.Write([]byte(signingString))
+	digest := h.Sum(nil)
+
+	var opts crypto.SignerOpts = m.hash
+	if m.pss {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: m.hash}
+	}
+	sig, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil || m.ecSize == 0 {
+		return sig, err
+	}
+	// crypto.Signer returns ASN.1 encoded ECDSA signatures; JWS wants R || S.
+	var parsed struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	out := make([]byte, 2*m.ecSize)
+	parsed.R.FillBytes(out[:m.ecSize])
+	parsed.S.FillBytes(out[m.ecSize:])
+	return out, nil
+}
+
+func (m *signerMethod) Verify(signingString string, sig []byte, key interface{}) error {
+	var method jwt.SigningMethod
+	switch m.alg {
+	case "EdDSA":
+		method = jwt.SigningMethodEdDSA
+	default:
+		method = jwt.GetSigningMethod(m.alg)
+	}
+	if method == nil {
+		return jwt.ErrSignatureInvalid
+	}
+	return method.Verify(signingString, sig, key)
+}
@@ -0,0 +1,592 @@
+package zscaler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSource identifies where the effective value of a setting came from.
+type ConfigSource string
+
+const (
+	// ConfigSourceDefault is the SDK default.
+	ConfigSourceDefault ConfigSource = "default"
+	// ConfigSourceFile is the configuration file, or one of its profiles.
+	ConfigSourceFile ConfigSource = "file"
+	// ConfigSourceEnvironment is an environment variable.
+	ConfigSourceEnvironment ConfigSource = "env"
+	// ConfigSourceSetter is a ConfigSetter, or a field assigned in code.
+	ConfigSourceSetter ConfigSource = "setter"
+
+	defaultProfileName = "default"
+	defaultConfigFile  = ".zscaler/zscaler.yaml"
+)
+
+// secretSettings are reported as REDACTED by ValidateConfiguration.
+var secretSettings = []string{"clientSecret", "privateKey", "password", "sandboxToken"}
+
+// configReference matches ${VAR} and ${file:path} references in file values.
+var configReference = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// WithProfile selects a named profile of the configuration file, overriding
+// ZSCALER_PROFILE. Profiles are read from the "profiles" section of the file;
+// see WithConfigFile.
+func WithProfile(name string) ConfigSetter {
+	return func(c *Configuration) {
+		c.profile = name
+		c.reloadConfigFile()
+	}
+}
+
+// WithConfigFile reads settings from path instead of ~/.zscaler/zscaler.yaml,
+// overriding ZSCALER_CONFIG_FILE.
+func WithConfigFile(path string) ConfigSetter {
+	return func(c *Configuration) {
+		c.configFile = path
+		c.reloadConfigFile()
+	}
+}
+
+// configLeaf is a single setting of the Configuration, addressed by its YAML path.
+type configLeaf struct {
+	path  string
+	env   string
+	value reflect.Value
+}
+
+// configLeaves lists the settings that can be read from a file, keyed by the
+// path of their YAML tags, e.g. zscaler.client.vanityDomain.
+func configLeaves(c *Configuration) []configLeaf {
+	var leaves []configLeaf
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if !f.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				walk(v.Field(i), prefix+name+".")
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Func, reflect.Chan:
+				// Runtime state such as tokens, not settings.
+			default:
+				leaves = append(leaves, configLeaf{path: prefix + name, env: f.Tag.Get("envconfig"), value: v.Field(i)})
+			}
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return leaves
+}
+
+type configOrigin struct {
+	source ConfigSource
+	detail string
+}
+
+// configLayers tracks which source set each setting while a Configuration is
+// assembled from defaults, the configuration file and the environment.
+type configLayers struct {
+	defaults map[string]interface{}
+	// applied holds the values last written by the file and environment
+	// layers; a setting that differs from it was set in code.
+	applied  map[string]interface{}
+	origins  map[string]configOrigin
+	explicit bool
+	fileErr  error
+	envErr   error
+}
+
+func newConfigLayers(c *Configuration) *configLayers {
+	l := &configLayers{
+		defaults: make(map[string]interface{}),
+		applied:  make(map[string]interface{}),
+		origins:  make(map[string]configOrigin),
+	}
+	for _, leaf := range configLeaves(c) {
+		l.defaults[leaf.path] = leaf.value.Interface()
+		l.applied[leaf.path] = leaf.value.Interface()
+	}
+	return l
+}
+
+// record attributes every setting changed since the previous layer to source.
+func (l *configLayers) record(c *Configuration, source ConfigSource) {
+	for _, leaf := range configLeaves(c) {
+		current := leaf.value.Interface()
+		if reflect.DeepEqual(current, l.applied[leaf.path]) {
+			continue
+		}
+		l.applied[leaf.path] = current
+		detail := ""
+		if source == ConfigSourceEnvironment {
+			detail = leaf.env
+		}
+		l.origins[leaf.path] = configOrigin{source: source, detail: detail}
+	}
+}
+
+// configFilePath returns the configuration file to read and whether it was
+// chosen explicitly rather than being the default location.
+func (c *Configuration) configFilePath() (string, bool) {
+	if c.configFile != "" {
+		return expandHome(c.configFile), true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return "", false
+	}
+	return filepath.Join(home, defaultConfigFile), false
+}
+
+// applyConfigFile loads the selected profile of the configuration file. Settings
+// already set by the environment or in code are kept; settings loaded from a
+// previously selected profile that the new one does not define are reset to
+// their defaults.
+func (c *Configuration) applyConfigFile() {
+	l := c.layers
+	path, explicit := c.configFilePath()
+	l.explicit = explicit || c.profile != ""
+
+	types := make(map[string]reflect.Type)
+	leaves := configLeaves(c)
+	for _, leaf := range leaves {
+		types[leaf.path] = leaf.value.Type()
+	}
+	values, err := loadConfigFile(path, c.profile, l.explicit, types)
+	l.fileErr = err
+	if err != nil && !l.explicit {
+		c.Logger.Printf("[WARN] Ignoring invalid settings in %s: %v", path, err)
+	}
+
+	detail := path
+	if c.profile != "" {
+		detail = fmt.Sprintf("%s (profile %s)", path, c.profile)
+	}
+	for _, leaf := range leaves {
+		origin := l.origins[leaf.path]
+		if origin.source == ConfigSourceEnvironment || !reflect.DeepEqual(leaf.value.Interface(), l.applied[leaf.path]) {
+			continue
+		}
+		if v, ok := values[leaf.path]; ok {
+			leaf.value.Set(v)
+			l.applied[leaf.path] = v.Interface()
+			l.origins[leaf.path] = configOrigin{source: ConfigSourceFile, detail: detail}
+		} else if origin.source == ConfigSourceFile {
+			leaf.value.Set(reflect.ValueOf(l.defaults[leaf.path]))
+			l.applied[leaf.path] = l.defaults[leaf.path]
+			delete(l.origins, leaf.path)
+		}
+	}
+}
+
+// reloadConfigFile applies a newly selected profile or file from a ConfigSetter.
+func (c *Configuration) reloadConfigFile() {
+	if c.layers == nil {
+		c.layers = newConfigLayers(c)
+	}
+	c.applyConfigFile()
+}
+
+// loadConfigFile reads the settings of profile from the file at path, keyed by
+// setting path. The top-level "zscaler" block is the default profile unless the
+// file defines one named "default" under "profiles". A missing file is only an
+// error when it or the profile was chosen explicitly.
+func loadConfigFile(path, profile string, explicit bool, types map[string]reflect.Type) (map[string]reflect.Value, error) {
+	if path == "" {
+		if explicit {
+			return nil, errors.New("cannot locate the configuration file: no home directory")
+		}
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	name := profile
+	if name == "" {
+		name = defaultProfileName
+	}
+	body, usesRoot, err := resolveProfile(root, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]reflect.Value)
+	var errs []error
+	visit := func(key string, raw interface{}) {
+		if _, ok := types[key]; !ok {
+			return
+		}
+		v, err := decodeConfigValue(raw, types[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			return
+		}
+		values[key] = v
+	}
+	flatten(body.merged, "zscaler", visit)
+	if usesRoot {
+		if raw, ok := root["useLegacyClient"]; ok {
+			visit("useLegacyClient", raw)
+		}
+	}
+	// The top-level block is shared with the legacy product clients, which read
+	// settings this configuration does not know, so only profiles are strict.
+	for _, key := range body.profileKeys {
+		if _, ok := types[key]; !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting", key))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return values, fmt.Errorf("%s: profile %q: %w", path, name, err)
+	}
+	return values, nil
+}
+
+type resolvedProfile struct {
+	merged map[string]interface{}
+	// profileKeys are the settings defined in the "profiles" section.
+	profileKeys []string
+}
+
+// resolveProfile merges a profile with the profiles it inherits from. It reports
+// whether the top-level "zscaler" block is part of the result.
+func resolveProfile(root map[string]interface{}, name string) (resolvedProfile, bool, error) {
+	profiles, _ := root["profiles"].(map[string]interface{})
+	var chain []map[string]interface{}
+	var resolved resolvedProfile
+	seen := make(map[string]bool)
+	usesRoot := false
+	for n := name; n != ""; {
+		if seen[n] {
+			return resolved, false, fmt.Errorf("profile %q: inheritance cycle through %q", name, n)
+		}
+		seen[n] = true
+		raw, ok := profiles[n]
+		if !ok {
+			if n != defaultProfileName {
+				return resolved, false, fmt.Errorf("profile %q is not defined", n)
+			}
+			block, _ := root["zscaler"].(map[string]interface{})
+			chain = append(chain, block)
+			usesRoot = true
+			break
+		}
+		body, ok := raw.(map[string]interface{})
+		if raw != nil && !ok {
+			return resolved, false, fmt.Errorf("profile %q must be a mapping", n)
+		}
+		parent := ""
+		if inherits, ok := body["inherits"]; ok {
+			if parent, ok = inherits.(string); !ok {
+				return resolved, false, fmt.Errorf("profile %q: inherits must be a profile name", n)
+			}
+		}
+		settings := make(map[string]interface{})
+		mergeMaps(settings, body)
+		delete(settings, "inherits")
+		flatten(settings, "zscaler", func(key string, _ interface{}) {
+			resolved.profileKeys = append(resolved.profileKeys, key)
+		})
+		chain = append(chain, settings)
+		n = parent
+	}
+
+	resolved.merged = make(map[string]interface{})
+	for i := len(chain) - 1; i >= 0; i-- {
+		mergeMaps(resolved.merged, chain[i])
+	}
+	sort.Strings(resolved.profileKeys)
+	return resolved, usesRoot, nil
+}
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sub, ok := v.(map[string]interface{}); ok {
+			if existing, ok := dst[k].(map[string]interface{}); ok {
+				mergeMaps(existing, sub)
+				continue
+			}
+			copied := make(map[string]interface{})
+			mergeMaps(copied, sub)
+			dst[k] = copied
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+func flatten(m map[string]interface{}, prefix string, visit func(key string, value interface{})) {
+	for k, v := range m {
+		key := prefix + "." + k
+		if sub, ok := v.(map[string]interface{}); ok {
+			flatten(sub, key, visit)
+			continue
+		}
+		visit(key, v)
+	}
+}
+
+// decodeConfigValue converts a YAML value to the type of a setting, expanding
+// ${VAR} and ${file:path} references in strings first.
+func decodeConfigValue(raw interface{}, t reflect.Type) (reflect.Value, error) {
+	if s, ok := raw.(string); ok {
+		expanded, err := expandReferences(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf([]byte(expanded)).Convert(t), nil
+		}
+		raw = expanded
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(t)
+	if err := yaml.Unmarshal(data, v.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid value %v for %s", raw, t)
+	}
+	return v.Elem(), nil
+}
+
+func expandReferences(s string) (string, error) {
+	var err error
+	expanded := configReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.TrimSpace(ref[2 : len(ref)-1])
+		if file, ok := strings.CutPrefix(name, "file:"); ok {
+			content, readErr := os.ReadFile(expandHome(strings.TrimSpace(file)))
+			if readErr != nil {
+				err = errors.Join(err, fmt.Errorf("cannot read %s: %w", ref, readErr))
+				return ""
+			}
+			return strings.TrimRight(string(content), "\r\n")
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			err = errors.Join(err, fmt.Errorf("environment variable %s referenced by %s is not set", name, ref))
+		}
+		return value
+	})
+	return expanded, err
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// ConfigValue is the effective value of one setting and where it came from.
+type ConfigValue struct {
+	// Path is the YAML path of the setting, e.g. zscaler.client.vanityDomain.
+	Path string
+	// Value is the formatted value; secrets are REDACTED.
+	Value  string
+	Source ConfigSource
+	// Origin names the file and profile or the environment variable the value
+	// was read from.
+	Origin string
+}
+
+// ConfigReport lists the settings of a Configuration, sorted by path.
+type ConfigReport []ConfigValue
+
+// Lookup returns the entry of the setting at path.
+func (r ConfigReport) Lookup(path string) (ConfigValue, bool) {
+	for _, v := range r {
+		if v.Path == path {
+			return v, true
+		}
+	}
+	return ConfigValue{}, false
+}
+
+func (r ConfigReport) String() string {
+	var b strings.Builder
+	for _, v := range r {
+		fmt.Fprintf(&b, "%s = %s (%s", v.Path, v.Value, v.Source)
+		if v.Origin != "" {
+			fmt.Fprintf(&b, " %s", v.Origin)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// origin returns where the current value of the setting at path came from.
+func (c *Configuration) origin(leaf configLeaf) configOrigin {
+	if c.layers == nil {
+		if leaf.value.IsZero() {
+			return configOrigin{source: ConfigSourceDefault}
+		}
+		return configOrigin{source: ConfigSourceSetter}
+	}
+	if !reflect.DeepEqual(leaf.value.Interface(), c.layers.applied[leaf.path]) {
+		return configOrigin{source: ConfigSourceSetter}
+	}
+	if o, ok := c.layers.origins[leaf.path]; ok {
+		return o
+	}
+	return configOrigin{source: ConfigSourceDefault}
+}
+
+// ValidateConfiguration checks that cfg can be used to create a client. It
+// returns a report of every setting with the source of its value, and an error
+// joining the problems found: failures to read the configuration file or the
+// environment, and an *errorx.ValidationError naming each invalid setting.
+func ValidateConfiguration(cfg *Configuration) (ConfigReport, error) {
+	leaves := make(map[string]configLeaf)
+	var report ConfigReport
+	for _, leaf := range configLeaves(cfg) {
+		leaves[leaf.path] = leaf
+		o := cfg.origin(leaf)
+		report = append(report, ConfigValue{
+			Path:   leaf.path,
+			Value:  formatConfigValue(leaf),
+			Source: o.source,
+			Origin: o.detail,
+		})
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Path < report[j].Path })
+
+	var fields []errorx.FieldError
+	invalid := func(path, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		if leaf, ok := leaves[path]; ok {
+			if o := cfg.origin(leaf); o.source != ConfigSourceDefault {
+				msg += fmt.Sprintf(" (from %s", o.source)
+				if o.detail != "" {
+					msg += " " + o.detail
+				}
+				msg += ")"
+			}
+		}
+		fields = append(fields, errorx.FieldError{Field: path, Message: msg})
+	}
+
+	client := cfg.Zscaler.Client
+	if !cfg.UseLegacyClient && cfg.TokenSource == nil {
+		if client.ClientID == "" {
+			invalid("zscaler.client.clientId", "is required")
+		}
+		if client.VanityDomain == "" {
+			invalid("zscaler.client.vanityDomain", "is required")
+		}
+		if client.ClientSecret == "" && !cfg.usesClientAssertion() {
+			invalid("zscaler.client.clientSecret", "or zscaler.client.privateKey is required")
+		}
+		if len(client.PrivateKey) > 0 && cfg.ClientAssertionSigner == nil && cfg.PrivateKeySigner == nil {
+			if _, err := ParsePrivateKeyPEM(client.PrivateKey); err != nil {
+				invalid("zscaler.client.privateKey", "is not a PEM encoded private key: %v", err)
+			}
+		}
+		if signer := cfg.ClientAssertionSigner; signer != nil || client.ClientAssertion.Algorithm != "" {
+			if signer == nil {
+				signer, _ = ParsePrivateKeyPEM(client.PrivateKey)
+			}
+			if signer != nil {
+				if _, err := signingMethodFor(signer.Public(), client.ClientAssertion.Algorithm); err != nil {
+					invalid("zscaler.client.clientAssertion.algorithm", "%v", err)
+				}
+			}
+		}
+		if len(client.ClientAssertion.Certificate) > 0 {
+			if _, err := certificateDER(client.ClientAssertion.Certificate); err != nil {
+				invalid("zscaler.client.clientAssertion.certificate", "%v", err)
+			}
+		}
+	}
+	if client.BaseURL != "" {
+		if u, err := url.Parse(client.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("zscaler.client.baseUrl", "must be an absolute http or https URL")
+		}
+	}
+	if client.Proxy.Port < 0 || client.Proxy.Port > 65535 {
+		invalid("zscaler.client.proxy.port", "must be between 0 and 65535")
+	}
+	if client.Proxy.Port > 0 && client.Proxy.Host == "" {
+		invalid("zscaler.client.proxy.host", "is required when a proxy port is set")
+	}
+	if client.RequestTimeout < 0 {
+		invalid("zscaler.client.requestTimeout", "must not be negative")
+	}
+	if client.PageConcurrency < 0 {
+		invalid("zscaler.client.pageConcurrency", "must not be negative")
+	}
+	if client.Cache.DefaultTtl < 0 || client.Cache.DefaultTti < 0 || client.Cache.DefaultCacheMaxSizeMB < 0 {
+		invalid("zscaler.client.cache", "ttl, tti and size must not be negative")
+	}
+	if client.RateLimit.MaxRetries < 0 {
+		invalid("zscaler.client.rateLimit.maxRetries", "must not be negative")
+	}
+	if client.TokenRefresh.Skew < 0 || client.TokenRefresh.MaxRetries < 0 || client.TokenRefresh.Backoff < 0 {
+		invalid("zscaler.client.tokenRefresh", "skew, maxRetries and backoff must not be negative")
+	}
+	if client.RateLimit.RetryWaitMin > client.RateLimit.RetryWaitMax {
+		invalid("zscaler.client.rateLimit.minWait", "%v exceeds zscaler.client.rateLimit.maxWait %v", client.RateLimit.RetryWaitMin, client.RateLimit.RetryWaitMax)
+	}
+	switch RecorderMode(strings.ToLower(cfg.Zscaler.Testing.Recorder.Mode)) {
+	case RecorderModeDisabled, RecorderModeRecord, RecorderModeRecordOnce, RecorderModeReplay, RecorderModeReplayWithNewEpisodes, RecorderModePassthrough:
+	default:
+		invalid("zscaler.testing.recorder.mode", "%q is not a recorder mode", cfg.Zscaler.Testing.Recorder.Mode)
+	}
+
+	var errs []error
+	if cfg.layers != nil {
+		if cfg.layers.fileErr != nil {
+			errs = append(errs, fmt.Errorf("configuration file: %w", cfg.layers.fileErr))
+		}
+		if cfg.layers.envErr != nil {
+			errs = append(errs, fmt.Errorf("environment: %w", cfg.layers.envErr))
+		}
+	}
+	if cfg.recorderErr != nil {
+		errs = append(errs, cfg.recorderErr)
+	}
+	if len(fields) > 0 {
+		errs = append(errs, &errorx.ValidationError{Message: "invalid configuration", Fields: fields})
+	}
+	return report, errors.Join(errs...)
+}
+
+func formatConfigValue(leaf configLeaf) string {
+	if leaf.value.IsZero() {
+		return ""
+	}
+	name := leaf.path[strings.LastIndex(leaf.path, ".")+1:]
+	for _, secret := range secretSettings {
+		if strings.EqualFold(name, secret) {
+			return redactedValue
+		}
+	}
+	if b, ok := leaf.value.Interface().([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(leaf.value.Interface())
+}
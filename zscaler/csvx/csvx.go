@@ -0,0 +1,404 @@
+// Package csvx decodes CSV reports into typed records.
+//
+// The fields of a record struct name their column in a `csv` tag. A tag may
+// list several names separated by "|" to accept the header variations of
+// different report versions. Header names are compared ignoring case, spaces,
+// underscores and hyphens. Rows before the header row, such as the report
+// summary lines ZIA writes at the top of its exports, are skipped. A field of
+// type map[string]string tagged `csv:"*"` receives the columns no other field
+// takes, keyed by their header, so columns added to a report are not lost.
+//
+//	type Entry struct {
+//		Time   time.Time `csv:"Timestamp|Time"`
+//		Admin  string    `csv:"Admin"`
+//		Result string    `csv:"Result"`
+//	}
+//	dec := csvx.NewDecoder[Entry](body)
+//	for entry, err := range dec.All() {
+//		...
+//	}
+package csvx
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoHeader is returned when no row in the header search window names a
+// column of the record type.
+var ErrNoHeader = errors.New("csvx: no header row found")
+
+// DefaultTimeLayouts are the layouts time.Time fields are parsed with, in
+// order. Integer values are read as Unix epoch milliseconds.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"01/02/2006 15:04:05",
+	"01/02/2006 03:04:05 PM",
+	"Jan 2, 2006 3:04:05 PM",
+	"January 2, 2006 3:04:05 PM MST",
+	"Mon Jan 02 15:04:05 MST 2006",
+	"2006-01-02",
+}
+
+// DefaultNullValues are the cells read as empty by fields other than strings.
+var DefaultNullValues = []string{"N/A", "NA", "-", "--", "null", "None"}
+
+// ParseError reports a cell that could not be converted to its field type.
+type ParseError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("csvx: line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Decoder.
+type Option func(*config)
+
+type config struct {
+	comma       rune
+	timeLayouts []string
+	location    *time.Location
+	headerRows  int
+	nullValues  []string
+}
+
+// WithComma sets the field delimiter. The default is ','.
+func WithComma(comma rune) Option {
+	return func(c *config) {
+		c.comma = comma
+	}
+}
+
+// WithTimeLayouts replaces DefaultTimeLayouts.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(c *config) {
+		c.timeLayouts = layouts
+	}
+}
+
+// WithLocation sets the location of times without a zone. The default is UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(c *config) {
+		c.location = loc
+	}
+}
+
+// WithNullValues replaces DefaultNullValues.
+func WithNullValues(values ...string) Option {
+	return func(c *config) {
+		c.nullValues = values
+	}
+}
+
+// WithHeaderSearch sets how many rows are searched for the header row. The
+// default is 50.
+func WithHeaderSearch(rows int) Option {
+	return func(c *config) {
+		c.headerRows = rows
+	}
+}
+
+type field struct {
+	index []int
+	names []string
+}
+
+var extraType = reflect.TypeOf(map[string]string(nil))
+
+// Decoder reads records of type T, which must be a struct, from a CSV stream.
+type Decoder[T any] struct {
+	r       *csv.Reader
+	cfg     config
+	fields  []field
+	extra   []int
+	columns map[int]field
+	header  []string
+	line    int
+	err     error
+}
+
+// NewDecoder returns a decoder reading from r. Rows are read as they are
+// decoded, so a report streamed from a response body is never held in memory
+// as a whole.
+func NewDecoder[T any](r io.Reader, opts ...Option) *Decoder[T] {
+	cfg := config{comma: ',', timeLayouts: DefaultTimeLayouts, location: time.UTC, headerRows: 50, nullValues: DefaultNullValues}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = cfg.comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	d := &Decoder[T]{r: cr, cfg: cfg}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		d.err = fmt.Errorf("csvx: record type %s is not a struct", t)
+		return d
+	}
+	d.fields = fieldsOf(t, nil)
+	for i, f := range d.fields {
+		if slices.Equal(f.names, []string{"*"}) {
+			d.extra = f.index
+			d.fields = slices.Delete(d.fields, i, i+1)
+			break
+		}
+	}
+	if d.extra != nil && t.FieldByIndex(d.extra).Type != extraType {
+		d.err = fmt.Errorf("csvx: field %s tagged \"*\" is not a map[string]string", t.FieldByIndex(d.extra).Name)
+	}
+	return d
+}
+
+func fieldsOf(t reflect.Type, parent []int) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		index := append(append([]int(nil), parent...), i)
+		tag, ok := sf.Tag.Lookup("csv")
+		if tag == "-" {
+			continue
+		}
+		if !ok && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, fieldsOf(sf.Type, index)...)
+			continue
+		}
+		if !ok {
+			tag = sf.Name
+		}
+		var names []string
+		for _, name := range strings.Split(tag, "|") {
+			if name = normalize(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		fields = append(fields, field{index: index, names: names})
+	}
+	return fields
+}
+
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-', '\t', '\uFEFF':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Header returns the header row, once the first record was decoded.
+func (d *Decoder[T]) Header() []string {
+	return d.header
+}
+
+// Decode returns the next record, or io.EOF after the last one.
+func (d *Decoder[T]) Decode() (T, error) {
+	var record T
+	if d.err != nil {
+		return record, d.err
+	}
+	if d.columns == nil {
+		if d.err = d.readHeader(); d.err != nil {
+			return record, d.err
+		}
+	}
+	for {
+		row, err := d.r.Read()
+		if err != nil {
+			d.err = err
+			return record, err
+		}
+		d.line, _ = d.r.FieldPos(0)
+		if blank(row) {
+			continue
+		}
+		v := reflect.ValueOf(&record).Elem()
+		for i, cell := range row {
+			f, ok := d.columns[i]
+			if !ok {
+				d.setExtra(v, i, cell)
+				continue
+			}
+			if err := d.set(v.FieldByIndex(f.index), strings.TrimSpace(cell)); err != nil {
+				return record, &ParseError{Line: d.line, Column: d.header[i], Err: err}
+			}
+		}
+		return record, nil
+	}
+}
+
+// All iterates over the remaining records. Iteration stops after the first
+// error, which is yielded; the end of the input is not an error.
+func (d *Decoder[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			record, err := d.Decode()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *Decoder[T]) readHeader() error {
+	for n := 0; n < d.cfg.headerRows; n++ {
+		row, err := d.r.Read()
+		if errors.Is(err, io.EOF) && n == 0 {
+			// An empty report has no records.
+			return io.EOF
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		columns := make(map[int]field)
+		for i, name := range row {
+			name = normalize(name)
+			for _, f := range d.fields {
+				if slices.Contains(f.names, name) {
+					columns[i] = f
+					break
+				}
+			}
+		}
+		if len(columns) > 0 {
+			d.columns = columns
+			d.header = row
+			return nil
+		}
+	}
+	return ErrNoHeader
+}
+
+func (d *Decoder[T]) setExtra(v reflect.Value, i int, cell string) {
+	if d.extra == nil || i >= len(d.header) || strings.TrimSpace(d.header[i]) == "" {
+		return
+	}
+	m := v.FieldByIndex(d.extra)
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(extraType))
+	}
+	m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(d.header[i])), reflect.ValueOf(strings.TrimSpace(cell)))
+}
+
+func (d *Decoder[T]) set(v reflect.Value, cell string) error {
+	if v.Kind() != reflect.String && slices.Contains(d.cfg.nullValues, cell) {
+		cell = ""
+	}
+	if v.Kind() == reflect.Pointer {
+		if cell == "" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		if cell == "" {
+			return nil
+		}
+		t, err := d.parseTime(cell)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(cell))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(cell)
+		return nil
+	}
+	if cell == "" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := parseBool(cell)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.ReplaceAll(cell, ",", ""), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.ReplaceAll(cell, ",", ""), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(cell, ",", ""), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func (d *Decoder[T]) parseTime(cell string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	for _, layout := range d.cfg.timeLayouts {
+		if t, err := time.ParseInLocation(layout, cell, d.cfg.location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", cell)
+}
+
+func parseBool(cell string) (bool, error) {
+	switch strings.ToLower(cell) {
+	case "yes", "y", "enabled", "on":
+		return true, nil
+	case "no", "n", "disabled", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(cell)
+}
+
+func blank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,338 @@
+package zscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
+)
+
+// WithDryRun stops the client from sending POST, PUT, PATCH and DELETE requests.
+// They are recorded in the plan returned by Client.Plan instead and answered
+// with the request body, while GET requests still reach the API. Only the
+// OneAPI client honours dry runs; legacy clients send every request.
+func WithDryRun(enabled bool) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.DryRun = enabled
+	}
+}
+
+// DryRunHeader is set on the responses synthesized for planned requests.
+const DryRunHeader = "X-Zscaler-Dry-Run"
+
+// PlannedCall is a mutating request held back by a dry run. Current is the
+// state of the resource read with a GET on the same endpoint before a PUT,
+// PATCH or DELETE; CurrentError explains why it could not be read. Sensitive
+// fields of Body and Current are masked.
+type PlannedCall struct {
+	Method       string          `json:"method"`
+	Endpoint     string          `json:"endpoint"`
+	Product      string          `json:"product,omitempty"`
+	Body         json.RawMessage `json:"body,omitempty"`
+	Current      json.RawMessage `json:"current,omitempty"`
+	CurrentError string          `json:"currentError,omitempty"`
+	PlannedAt    time.Time       `json:"plannedAt"`
+}
+
+// Plan collects the calls of a dry run in the order they were made. It is safe
+// for concurrent use.
+type Plan struct {
+	mu     sync.Mutex
+	calls  []PlannedCall
+	policy *logger.RedactionPolicy
+}
+
+// NewPlan returns an empty plan masking the sensitive fields of policy, or of
+// the default redaction policy when policy is nil.
+func NewPlan(policy *logger.RedactionPolicy) *Plan {
+	if policy == nil {
+		policy = logger.DefaultRedactionPolicy()
+	}
+	return &Plan{policy: policy}
+}
+
+// Calls returns a copy of the planned calls.
+func (p *Plan) Calls() []PlannedCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedCall(nil), p.calls...)
+}
+
+// Len returns the number of planned calls.
+func (p *Plan) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.calls)
+}
+
+// Reset discards the planned calls.
+func (p *Plan) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = nil
+}
+
+// MarshalJSON exports the plan as {"calls": [...]}.
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Calls []PlannedCall `json:"calls"`
+	}{Calls: p.Calls()})
+}
+
+// Diff renders the plan for human review. Each call lists the fields it sets
+// (+), changes (~) or, for DELETE, removes (-) relative to the current state.
+// Fields the body leaves out are not shown.
+func (p *Plan) Diff() string {
+	var b strings.Builder
+	for i, call := range p.Calls() {
+		fmt.Fprintf(&b, "%d. %s %s", i+1, call.Method, call.Endpoint)
+		if call.Product != "" {
+			fmt.Fprintf(&b, " (%s)", call.Product)
+		}
+		b.WriteByte('\n')
+		if call.CurrentError != "" {
+			fmt.Fprintf(&b, "   ! current state unavailable: %s\n", call.CurrentError)
+		}
+		current, body := flattenJSON(call.Current), flattenJSON(call.Body)
+		if call.Method == http.MethodDelete {
+			for _, k := range sortedKeys(current) {
+				fmt.Fprintf(&b, "   - %s: %s\n", k, current[k])
+			}
+			continue
+		}
+		changed := false
+		for _, k := range sortedKeys(body) {
+			old, ok := current[k]
+			switch {
+			case !ok:
+				fmt.Fprintf(&b, "   + %s: %s\n", k, body[k])
+			case old != body[k]:
+				fmt.Fprintf(&b, "   ~ %s: %s => %s\n", k, old, body[k])
+			default:
+				continue
+			}
+			changed = true
+		}
+		if !changed && len(body) > 0 {
+			b.WriteString("   (no changes)\n")
+		}
+	}
+	return b.String()
+}
+
+func (p *Plan) add(call PlannedCall) {
+	call.Body = p.redact(call.Body)
+	call.Current = p.redact(call.Current)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, call)
+}
+
+// redact masks the sensitive fields of a JSON document.
+func (p *Plan) redact(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	replacement := p.policy.Replacement
+	if replacement == "" {
+		replacement = logger.RedactedValue
+	}
+	var walk func(interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, child := range t {
+				if p.policy.IsSensitiveField(k) {
+					t[k] = replacement
+				} else {
+					t[k] = walk(child)
+				}
+			}
+		case []interface{}:
+			for i, child := range t {
+				t[i] = walk(child)
+			}
+		}
+		return v
+	}
+	out, err := json.Marshal(walk(v))
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// Plan returns the plan of a client configured with WithDryRun, or nil.
+func (c *Client) Plan() *Plan {
+	return c.plan
+}
+
+// Plan returns the plan of the service's client in dry-run mode, or nil.
+func (service *Service) Plan() *Plan {
+	if service.Client == nil {
+		return nil
+	}
+	return service.Client.Plan()
+}
+
+func isMutatingMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// readOnlyPosts are the endpoints, matched by path suffix, that take a POST
+// but only read: lookups and exports whose request body is the query.
+var readOnlyPosts = []string{
+	"/zia/api/v1/urlLookup",
+	"/zia/api/v1/exportPolicies",
+	"/zia/api/v1/shadowIT/applications/export",
+	"/zia/api/v1/shadowIT/applications/USER/exportCsv",
+	"/zia/api/v1/shadowIT/applications/LOCATION/exportCsv",
+	"/application/validate",
+}
+
+func isReadOnlyPost(path string) bool {
+	for _, suffix := range readOnlyPosts {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// planRequest records a mutating request instead of sending it and answers it
+// the way the API would acknowledge it: with the request body.
+func (c *Client) planRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values) ([]byte, *http.Response, *http.Request, error) {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = io.ReadAll(body); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	if len(urlParams) > 0 {
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		endpoint += sep + urlParams.Encode()
+	}
+	product := c.productOf(endpoint)
+	call := PlannedCall{
+		Method:    strings.ToUpper(method),
+		Endpoint:  endpoint,
+		Product:   product,
+		Body:      jsonOrString(bodyBytes),
+		PlannedAt: time.Now(),
+	}
+	if call.Method != http.MethodPost {
+		current, _, _, err := c.ExecuteRequest(WithoutCache(ctx), http.MethodGet, endpoint, nil, nil, contentTypeJSON)
+		if err != nil {
+			call.CurrentError = err.Error()
+		} else {
+			call.Current = jsonOrString(current)
+		}
+	}
+	c.plan.add(call)
+	c.oauth2Credentials.Logger.Printf("[INFO] Dry run: planned %s %s", call.Method, endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, call.Method, endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	status, respBody := http.StatusOK, bodyBytes
+	if call.Method == http.MethodDelete {
+		status, respBody = http.StatusNoContent, nil
+	}
+	resp := &http.Response{
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode: status,
+		Header:     http.Header{DryRunHeader: []string{"true"}},
+		Body:       io.NopCloser(bytes.NewReader(respBody)),
+		Request:    req,
+	}
+	if len(respBody) > 0 {
+		resp.Header.Set("Content-Type", contentTypeJSON)
+	}
+	return respBody, resp, req, nil
+}
+
+// jsonOrString returns data if it is a JSON document and data as a JSON string otherwise.
+func jsonOrString(data []byte) json.RawMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	s, _ := json.Marshal(string(data))
+	return s
+}
+
+// flattenJSON maps the dotted paths of the leaves of a JSON document to their
+// JSON encoding.
+func flattenJSON(raw json.RawMessage) map[string]string {
+	out := map[string]string{}
+	if len(raw) == 0 {
+		return out
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return out
+	}
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, child := range t {
+				path := k
+				if prefix != "" {
+					path = prefix + "." + k
+				}
+				walk(path, child)
+			}
+			return
+		case []interface{}:
+			for i, child := range t {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+			if len(t) > 0 {
+				return
+			}
+		}
+		if prefix == "" {
+			prefix = "(body)"
+		}
+		encoded, _ := json.Marshal(v)
+		out[prefix] = string(encoded)
+	}
+	walk("", v)
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -52,7 +52,7 @@ func CheckErrorInResponse(res *http.Response, respErr error) error {
 	defer res.Body.Close()
 
 	contentType := res.Header.Get("Content-Type")
-	isJSON := strings.Contains(contentType, "application/json")
+	isJSON := strings.Contains(contentType, "application/json") || strings.Contains(contentType, "application/scim+json")
 	msg := strings.TrimSpace(string(bodyBytes))
 
 	// ✅ Only fallback if it's non-JSON and the message matches known OneAPI error
@@ -84,6 +84,13 @@ func CheckErrorInResponse(res *http.Response, respErr error) error {
 			if ex, ok := jsonBody["exception"].(string); ok {
 				parsed.Exception = ex
 			}
+			// SCIM errors (RFC 7644 section 3.12) carry detail and scimType.
+			if detail, ok := jsonBody["detail"].(string); ok && parsed.Message == "" {
+				parsed.Message = detail
+			}
+			if scimType, ok := jsonBody["scimType"].(string); ok && parsed.Code == nil {
+				parsed.Code = scimType
+			}
 		} else {
 			parsed.Message = fmt.Sprintf("Failed to parse JSON error body: %s", err.Error())
 		}
@@ -160,23 +167,9 @@ func IsSessionInvalidError(res *http.Response) bool {
 	// Rewind the response body for potential reuse
 	res.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
 
-	bodyStr := string(bodyBytes)
-
 	// Only check for error messages we know for certain are returned by the API
 	// The API may return different formats depending on the service/endpoint
-	knownSessionInvalidMessages := []string{
-		"SESSION_NOT_VALID",                         // Legacy/direct error code
-		"getAttribute: Session already invalidated", // Java exception message format
-		"Resource Access Blocked",                   // Occurs under high concurrency/load - API returns 401 instead of 429
-	}
-
-	for _, msg := range knownSessionInvalidMessages {
-		if strings.Contains(bodyStr, msg) {
-			return true
-		}
-	}
-
-	return false
+	return containsAny(string(bodyBytes), knownSessionInvalidMessages)
 }
 
 // IsEditLockError checks if the response indicates an edit lock conflict error
@@ -0,0 +1,303 @@
+package errorx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error kinds. Every error returned by the SDK for a failed API call matches at
+// most one of them with errors.Is, whichever product produced it:
+//
+//	if errors.Is(err, errorx.ErrNotFound) { ... }
+//
+// ErrSessionInvalid also matches ErrUnauthorized.
+var (
+	ErrNotFound       = errors.New("resource not found")
+	ErrConflict       = errors.New("resource conflict")
+	ErrRateLimited    = errors.New("rate limit exceeded")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrSessionInvalid = fmt.Errorf("session is no longer valid: %w", ErrUnauthorized)
+	ErrValidation     = errors.New("validation failed")
+	ErrTimeout        = errors.New("request timed out")
+)
+
+// knownSessionInvalidMessages are the markers the API uses to report that a
+// token or session was invalidated server side.
+var knownSessionInvalidMessages = []string{
+	"SESSION_NOT_VALID",                         // Legacy/direct error code
+	"getAttribute: Session already invalidated", // Java exception message format
+	"Resource Access Blocked",                   // Occurs under high concurrency/load - API returns 401 instead of 429
+}
+
+// RateLimitError is the ErrRateLimited kind. Use errors.As to read how long the
+// API asked the client to wait.
+type RateLimitError struct {
+	// RetryAfter is the delay requested by the API, or 0 if it did not say.
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limit exceeded, retry after %v", e.RetryAfter)
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// FieldError is the validation failure of a single field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is the ErrValidation kind. Fields is filled when the API
+// names the offending fields.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field
+		if f.Message != "" {
+			parts[i] += ": " + f.Message
+		}
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// Unwrap exposes the cause of the failure and its kind to errors.Is and errors.As.
+func (r *ErrorResponse) Unwrap() []error {
+	var errs []error
+	if r.Err != nil {
+		errs = append(errs, r.Err)
+	}
+	if kind := r.kind(); kind != nil {
+		errs = append(errs, kind)
+	}
+	return errs
+}
+
+// kind classifies the response by status code and error body.
+func (r *ErrorResponse) kind() error {
+	if r == nil {
+		return nil
+	}
+	if r.IsObjectNotFound() {
+		return ErrNotFound
+	}
+	status := 0
+	if r.Response != nil {
+		status = r.Response.StatusCode
+	} else if r.Parsed != nil {
+		status = r.Parsed.Status
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		if containsAny(r.Message, knownSessionInvalidMessages) || (r.Parsed != nil && containsAny(fmt.Sprint(r.Parsed.Code), knownSessionInvalidMessages)) {
+			return ErrSessionInvalid
+		}
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		retryAfter, _ := r.RetryAfter()
+		return &RateLimitError{RetryAfter: retryAfter, Message: r.parsedMessage()}
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return &ValidationError{Message: r.parsedMessage(), Fields: parseFieldErrors([]byte(r.Message))}
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	return nil
+}
+
+// RetryAfter returns the delay requested by the API in the Retry-After header,
+// or in the "Retry-After" field ZIA puts in its error body.
+func (r *ErrorResponse) RetryAfter() (time.Duration, bool) {
+	if r == nil {
+		return 0, false
+	}
+	if r.Response != nil && r.Response.Header != nil {
+		if d, ok := parseRetryAfter(r.Response.Header.Get("Retry-After")); ok {
+			return d, true
+		}
+	}
+	var body struct {
+		RetryAfter string `json:"Retry-After"`
+	}
+	if json.Unmarshal([]byte(r.Message), &body) == nil {
+		return parseRetryAfter(body.RetryAfter)
+	}
+	return 0, false
+}
+
+func (r *ErrorResponse) parsedMessage() string {
+	if r.Parsed == nil {
+		return ""
+	}
+	if r.Parsed.Message != "" {
+		return r.Parsed.Message
+	}
+	return r.Parsed.Reason
+}
+
+// RetryAfter returns the delay carried by a rate-limited error.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rle *RateLimitError
+	if errors.As(err, &rle) && rle.RetryAfter > 0 {
+		return rle.RetryAfter, true
+	}
+	return 0, false
+}
+
+// StatusKind returns the error kind of an HTTP status code, or nil. Unlike the
+// kinds of an ErrorResponse it does not look at the body.
+func StatusKind(res *http.Response) error {
+	if res == nil {
+		return nil
+	}
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	return nil
+}
+
+// kindError is an error raised by the SDK itself, such as an exhausted retry
+// budget, tagged with an error kind.
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.err, e.kind} }
+
+// New returns an error with the given text that matches kind with errors.Is.
+// A nil kind returns a plain error.
+func New(kind error, text string) error {
+	return wrapKind(kind, errors.New(text))
+}
+
+// Errorf formats an error like fmt.Errorf, including %w wrapping, and makes it
+// match kind with errors.Is. A nil kind returns a plain error.
+func Errorf(kind error, format string, args ...interface{}) error {
+	return wrapKind(kind, fmt.Errorf(format, args...))
+}
+
+func wrapKind(kind, err error) error {
+	if kind == nil {
+		return err
+	}
+	return &kindError{err: err, kind: kind}
+}
+
+// parseFieldErrors extracts per-field details from the validation error bodies
+// used across the products: ZPA lists field names in "params", others return an
+// array of objects under "errors", "fieldErrors" or "details".
+func parseFieldErrors(body []byte) []FieldError {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return nil
+	}
+	var fields []FieldError
+	for _, key := range []string{"errors", "fieldErrors", "details"} {
+		var items []map[string]interface{}
+		if json.Unmarshal(raw[key], &items) != nil {
+			continue
+		}
+		for _, item := range items {
+			f := FieldError{
+				Field:   firstString(item, "field", "fieldName", "name", "path", "attribute"),
+				Message: firstString(item, "message", "reason", "description", "detail"),
+			}
+			if f.Field != "" || f.Message != "" {
+				fields = append(fields, f)
+			}
+		}
+	}
+	var params []string
+	if json.Unmarshal(raw["params"], &params) == nil {
+		var reason string
+		_ = json.Unmarshal(raw["reason"], &reason)
+		for _, p := range params {
+			fields = append(fields, FieldError{Field: p, Message: reason})
+		}
+	}
+	return fields
+}
+
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter accepts delay-seconds, an HTTP date, a Go duration or ZIA's
+// "N seconds" form.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(v, " seconds"), " second")); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return d, true
+	}
+	return 0, false
+}
+
+// AuthFailureKind returns the error kind of a failed authentication response:
+// client errors other than 429 mean the credentials were rejected.
+func AuthFailureKind(res *http.Response) error {
+	if res != nil && res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+		return ErrUnauthorized
+	}
+	return StatusKind(res)
+}
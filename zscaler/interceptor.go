@@ -0,0 +1,109 @@
+package zscaler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+)
+
+// RequestInfo describes a single attempt made by Client.ExecuteRequest.
+type RequestInfo struct {
+	// Product is the service the endpoint belongs to: "zia", "ztw", "zpa", "zcc", "zdx" or "admin".
+	Product string
+	// Method is the HTTP method of the request.
+	Method string
+	// Endpoint is the relative endpoint passed to ExecuteRequest, without query parameters added by the SDK.
+	Endpoint string
+	// Attempt is the 1-based attempt number; it increases on every retry.
+	Attempt int
+}
+
+// RequestInterceptor is called before each attempt is sent. It may modify req,
+// for example to add headers. A non-nil error aborts the request and is returned
+// to the caller.
+type RequestInterceptor func(ctx context.Context, req *http.Request, info RequestInfo) error
+
+// ResponseInterceptor is called after each attempt with the response (nil on
+// transport errors) and the error the attempt produced: the transport error, or
+// the parsed API error for non-2xx responses. The response body can be read;
+// it is rewound afterwards. Successful responses of ExecuteStreamRequest are
+// handed over with an empty body, so downloads are never buffered. A non-nil
+// error aborts the request and is returned to the caller.
+type ResponseInterceptor func(ctx context.Context, resp *http.Response, info RequestInfo, err error) error
+
+// WithRequestInterceptor appends interceptors to the request chain. Interceptors
+// run in the order they were added.
+func WithRequestInterceptor(interceptors ...RequestInterceptor) ConfigSetter {
+	return func(c *Configuration) {
+		c.requestInterceptors = append(c.requestInterceptors, interceptors...)
+	}
+}
+
+// WithResponseInterceptor appends interceptors to the response chain. Interceptors
+// run in the order they were added.
+func WithResponseInterceptor(interceptors ...ResponseInterceptor) ConfigSetter {
+	return func(c *Configuration) {
+		c.responseInterceptors = append(c.responseInterceptors, interceptors...)
+	}
+}
+
+// interceptRequest runs the request chain.
+func (c *Client) interceptRequest(ctx context.Context, req *http.Request, info RequestInfo) error {
+	for _, intercept := range c.oauth2Credentials.requestInterceptors {
+		if err := intercept(ctx, req, info); err != nil {
+			return fmt.Errorf("request interceptor: %w", err)
+		}
+	}
+	return nil
+}
+
+// interceptResponse runs the response chain, handing each interceptor a fresh view of the body.
+func (c *Client) interceptResponse(ctx context.Context, resp *http.Response, info RequestInfo, attemptErr error) error {
+	chain := c.oauth2Credentials.responseInterceptors
+	if len(chain) == 0 {
+		return nil
+	}
+
+	if streamed(ctx) && resp != nil && resp.StatusCode < 300 {
+		// The body stays unread for the caller of ExecuteStreamRequest.
+		view := *resp
+		view.Body = http.NoBody
+		for _, intercept := range chain {
+			if err := intercept(ctx, &view, info, attemptErr); err != nil {
+				return fmt.Errorf("response interceptor: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var body []byte
+	if resp != nil && resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if attemptErr == nil && resp != nil && resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotModified {
+		attemptErr = errorx.CheckErrorInResponse(resp, fmt.Errorf("API error"))
+	}
+
+	for _, intercept := range chain {
+		if resp != nil {
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if err := intercept(ctx, resp, info, attemptErr); err != nil {
+			return fmt.Errorf("response interceptor: %w", err)
+		}
+	}
+	if resp != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package zscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/filelock"
+)
+
+const journalVersion = 1
+
+// JournalState is the state of a journaled request.
+type JournalState string
+
+const (
+	// JournalPending is recorded before the request is sent. A pending entry
+	// left behind by a crashed run has an unknown outcome and is not rolled back.
+	JournalPending JournalState = "pending"
+	// JournalApplied marks a request the API accepted.
+	JournalApplied JournalState = "applied"
+	// JournalFailed marks a request the API rejected; nothing needs undoing.
+	JournalFailed JournalState = "failed"
+	// JournalRolledBack marks an applied request whose inverse succeeded.
+	JournalRolledBack JournalState = "rolled_back"
+)
+
+// JournalOp is a request replayed to undo a journaled one.
+type JournalOp struct {
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// JournalEntry records one mutating request. PreImage is the resource read
+// before a PUT, PATCH or DELETE and ResourceID the ID returned by a create.
+// Inverse is nil when the request cannot be undone, e.g. a POST that is not a
+// create or an update of an endpoint that cannot be read.
+type JournalEntry struct {
+	Seq        int             `json:"seq"`
+	Method     string          `json:"method"`
+	Endpoint   string          `json:"endpoint"`
+	Product    string          `json:"product,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	PreImage   json.RawMessage `json:"preImage,omitempty"`
+	ResourceID string          `json:"resourceId,omitempty"`
+	Inverse    *JournalOp      `json:"inverse,omitempty"`
+	State      JournalState    `json:"state"`
+	Error      string          `json:"error,omitempty"`
+	Time       time.Time       `json:"time"`
+}
+
+// Journal records the create, update and delete requests made with a context
+// returned by WithJournal, so they can be undone in reverse order. A journal
+// with a path is rewritten after every change, letting a crashed run be rolled
+// back later with OpenJournal. The file holds request bodies and pre-images
+// verbatim and is created with mode 0600.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	entries []JournalEntry
+}
+
+type journalFile struct {
+	Version int            `json:"version"`
+	Entries []JournalEntry `json:"entries"`
+}
+
+type journalContextKey struct{}
+
+// NewJournal starts an empty journal persisted to path, or kept in memory when
+// path is empty. It refuses to overwrite an existing journal.
+func NewJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+	if path == "" {
+		return j, nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("journal %s already exists; open it with OpenJournal to roll it back", path)
+	}
+	if err := j.save(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// OpenJournal loads the journal persisted at path.
+func OpenJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f journalFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid journal %s: %w", path, err)
+	}
+	if f.Version != journalVersion {
+		return nil, fmt.Errorf("journal %s has unsupported version %d", path, f.Version)
+	}
+	return &Journal{path: path, entries: f.Entries}, nil
+}
+
+// WithJournal returns a context whose mutating OneAPI requests are recorded in
+// j. Requests sent through legacy clients are not journaled.
+func WithJournal(ctx context.Context, j *Journal) context.Context {
+	return context.WithValue(ctx, journalContextKey{}, j)
+}
+
+func journalFrom(ctx context.Context) *Journal {
+	j, _ := ctx.Value(journalContextKey{}).(*Journal)
+	return j
+}
+
+// Path returns the file the journal is persisted to.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Entries returns a copy of the journal entries in the order they were recorded.
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalEntry(nil), j.entries...)
+}
+
+// Commit accepts the recorded changes and removes the journal file.
+func (j *Journal) Commit() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+	if j.path == "" {
+		return nil
+	}
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Rollback replays the inverse of every applied entry in reverse order. It
+// carries on past failures and returns them joined, together with the entries
+// that cannot be undone. The journal file is removed once everything was
+// rolled back. Deleted resources are re-created with new IDs.
+func (j *Journal) Rollback(ctx context.Context, service *Service) error {
+	ctx = WithJournal(ctx, nil)
+	var errs []error
+	for i := len(j.Entries()) - 1; i >= 0; i-- {
+		j.mu.Lock()
+		entry := j.entries[i]
+		j.mu.Unlock()
+
+		switch {
+		case entry.State == JournalPending:
+			errs = append(errs, fmt.Errorf("journal entry %d (%s %s) has an unknown outcome", entry.Seq, entry.Method, entry.Endpoint))
+			continue
+		case entry.State != JournalApplied:
+			continue
+		case entry.Inverse == nil:
+			errs = append(errs, fmt.Errorf("journal entry %d (%s %s) cannot be undone", entry.Seq, entry.Method, entry.Endpoint))
+			continue
+		}
+
+		op := entry.Inverse
+		var body io.Reader
+		if len(op.Body) > 0 {
+			body = bytes.NewReader(op.Body)
+		}
+		_, _, _, err := service.Client.ExecuteRequest(ctx, op.Method, op.Endpoint, body, nil, contentTypeJSON)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rolling back journal entry %d (%s %s): %w", entry.Seq, entry.Method, entry.Endpoint, err))
+			continue
+		}
+		service.Client.oauth2Credentials.Logger.Printf("[INFO] Rolled back %s %s with %s %s", entry.Method, entry.Endpoint, op.Method, op.Endpoint)
+		if err := j.update(i, func(e *JournalEntry) { e.State = JournalRolledBack }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return j.Commit()
+}
+
+// Transaction runs fn with a context journaling its requests in a journal
+// persisted to path (in memory when empty). The changes are committed when fn
+// succeeds and rolled back when it fails.
+func Transaction(ctx context.Context, service *Service, path string, fn func(ctx context.Context) error) error {
+	j, err := NewJournal(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(WithJournal(ctx, j)); err != nil {
+		if rbErr := j.Rollback(ctx, service); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+		}
+		return err
+	}
+	return j.Commit()
+}
+
+func (j *Journal) begin(entry JournalEntry) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry.Seq = len(j.entries) + 1
+	entry.State = JournalPending
+	entry.Time = time.Now()
+	j.entries = append(j.entries, entry)
+	return len(j.entries) - 1, j.saveLocked()
+}
+
+func (j *Journal) update(i int, fn func(*JournalEntry)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(&j.entries[i])
+	return j.saveLocked()
+}
+
+func (j *Journal) save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.saveLocked()
+}
+
+func (j *Journal) saveLocked() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(journalFile{Version: journalVersion, Entries: j.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := filelock.WriteFileAtomic(j.path, data, 0o600); err != nil {
+		return fmt.Errorf("writing journal %s: %w", j.path, err)
+	}
+	return nil
+}
+
+// journaledRequest sends a mutating request, recording it in j together with
+// what is needed to undo it.
+func (c *Client) journaledRequest(ctx context.Context, j *Journal, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) ([]byte, *http.Response, *http.Request, error) {
+	ctx = WithJournal(ctx, nil)
+	method = strings.ToUpper(method)
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = io.ReadAll(body); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	full := endpoint
+	if len(urlParams) > 0 {
+		sep := "?"
+		if strings.Contains(full, "?") {
+			sep = "&"
+		}
+		full += sep + urlParams.Encode()
+	}
+	product := c.productOf(endpoint)
+	entry := JournalEntry{Method: method, Endpoint: full, Product: product, Body: jsonOrString(bodyBytes)}
+
+	// Endpoints without a GET counterpart, such as the ZPA policy reorder
+	// calls, have no pre-image. Their change is made but cannot be undone.
+	if method != http.MethodPost {
+		pre, _, _, err := c.ExecuteRequest(WithoutCache(ctx), http.MethodGet, full, nil, nil, contentTypeJSON)
+		if err != nil {
+			c.oauth2Credentials.Logger.Printf("[WARN] Journal: reading %s before %s failed, the change cannot be undone: %v", full, method, err)
+		} else {
+			entry.PreImage = jsonOrString(pre)
+		}
+	}
+	i, err := j.begin(entry)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	respBody, resp, req, err := c.ExecuteRequest(ctx, method, endpoint, bytes.NewReader(bodyBytes), urlParams, contentType)
+	if err != nil {
+		if saveErr := j.update(i, func(e *JournalEntry) { e.State, e.Error = JournalFailed, err.Error() }); saveErr != nil {
+			c.oauth2Credentials.Logger.Printf("[ERROR] %v", saveErr)
+		}
+		return respBody, resp, req, err
+	}
+
+	path, query, _ := strings.Cut(full, "?")
+	withQuery := func(p string) string {
+		if query == "" {
+			return p
+		}
+		return p + "?" + query
+	}
+	var id string
+	var inverse *JournalOp
+	switch method {
+	case http.MethodPost:
+		if id = resourceID(respBody); id != "" {
+			inverse = &JournalOp{Method: http.MethodDelete, Endpoint: withQuery(strings.TrimSuffix(path, "/") + "/" + id)}
+		}
+	case http.MethodPut, http.MethodPatch:
+		if len(entry.PreImage) > 0 {
+			inverse = &JournalOp{Method: method, Endpoint: full, Body: entry.PreImage}
+		}
+	case http.MethodDelete:
+		if k := strings.LastIndex(strings.TrimSuffix(path, "/"), "/"); k > 0 && len(entry.PreImage) > 0 {
+			inverse = &JournalOp{Method: http.MethodPost, Endpoint: withQuery(path[:k]), Body: entry.PreImage}
+		}
+	}
+	if err := j.update(i, func(e *JournalEntry) { e.State, e.ResourceID, e.Inverse = JournalApplied, id, inverse }); err != nil {
+		return respBody, resp, req, err
+	}
+	return respBody, resp, req, nil
+}
+
+// resourceID returns the id field of a JSON object response.
+func resourceID(body []byte) string {
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return ""
+	}
+	switch id := obj["id"].(type) {
+	case string:
+		return id
+	case json.Number:
+		return id.String()
+	}
+	return ""
+}
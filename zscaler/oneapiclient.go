@@ -2,14 +2,16 @@ package zscaler
 
 import (
 	"context"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
-	"os/user"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -17,18 +19,20 @@ import (
 	"time"
 
 	"github.com/go-jose/go-jose/v3"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/cache"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
 	rl "github.com/SecurityGeekIO/zscaler-sdk-go/v3/ratelimiter"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zcc"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa"
 	ztw "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/ztw"
-	"gopkg.in/yaml.v3"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	vcr "gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
 )
 
 type contextKey string
@@ -96,11 +100,28 @@ type Configuration struct {
 			AccessToken   *AuthToken `yaml:"accessToken"`
 			SandboxToken  string     `yaml:"sandboxToken" envconfig:"ZSCALER_SANDBOX_TOKEN"`
 			SandboxCloud  string     `yaml:"sandboxCloud" envconfig:"ZSCALER_SANDBOX_CLOUD"`
-			Cache         struct {
+			BaseURL       string     `yaml:"baseUrl" envconfig:"ZSCALER_CLIENT_BASE_URL"`
+			TokenCache    struct {
+				Enabled bool   `yaml:"enabled" envconfig:"ZSCALER_CLIENT_TOKEN_CACHE_ENABLED"`
+				Path    string `yaml:"path" envconfig:"ZSCALER_CLIENT_TOKEN_CACHE_PATH"`
+			} `yaml:"tokenCache"`
+			ClientAssertion struct {
+				Algorithm   string        `yaml:"algorithm" envconfig:"ZSCALER_CLIENT_ASSERTION_ALGORITHM"`
+				KeyID       string        `yaml:"keyId" envconfig:"ZSCALER_CLIENT_ASSERTION_KEY_ID"`
+				Lifetime    time.Duration `yaml:"lifetime" envconfig:"ZSCALER_CLIENT_ASSERTION_LIFETIME"`
+				Certificate []byte        `yaml:"certificate" envconfig:"ZSCALER_CLIENT_ASSERTION_CERTIFICATE"`
+			} `yaml:"clientAssertion"`
+			TokenRefresh struct {
+				Skew       time.Duration `yaml:"skew" envconfig:"ZSCALER_CLIENT_TOKEN_REFRESH_SKEW"`
+				MaxRetries int32         `yaml:"maxRetries" envconfig:"ZSCALER_CLIENT_TOKEN_REFRESH_MAX_RETRIES"`
+				Backoff    time.Duration `yaml:"backoff" envconfig:"ZSCALER_CLIENT_TOKEN_REFRESH_BACKOFF"`
+			} `yaml:"tokenRefresh"`
+			Cache struct {
 				Enabled               bool          `yaml:"enabled" envconfig:"ZSCALER_CLIENT_CACHE_ENABLED"`
 				DefaultTtl            time.Duration `yaml:"defaultTtl" envconfig:"ZSCALER_CLIENT_CACHE_DEFAULT_TTL"`
 				DefaultTti            time.Duration `yaml:"defaultTti" envconfig:"ZSCALER_CLIENT_CACHE_DEFAULT_TTI"`
 				DefaultCacheMaxSizeMB int64         `yaml:"defaultSize" envconfig:"ZSCALER_CLIENT_CACHE_DEFAULT_SIZE"`
+				Dir                   string        `yaml:"dir" envconfig:"ZSCALER_CLIENT_CACHE_DIR"`
 			} `yaml:"cache"`
 			Proxy struct {
 				Port     int32  `yaml:"port" envconfig:"ZSCALER_CLIENT_PROXY_PORT"`
@@ -108,23 +129,60 @@ type Configuration struct {
 				Username string `yaml:"username" envconfig:"ZSCALER_CLIENT_PROXY_USERNAME"`
 				Password string `yaml:"password" envconfig:"ZSCALER_CLIENT_PROXY_PASSWORD"`
 			} `yaml:"proxy"`
-			RequestTimeout time.Duration `yaml:"requestTimeout" envconfig:"ZSCALER_CLIENT_REQUEST_TIMEOUT"`
-			RateLimit      struct {
+			RequestTimeout  time.Duration `yaml:"requestTimeout" envconfig:"ZSCALER_CLIENT_REQUEST_TIMEOUT"`
+			PageConcurrency int32         `yaml:"pageConcurrency" envconfig:"ZSCALER_CLIENT_PAGE_CONCURRENCY"`
+			DryRun          bool          `yaml:"dryRun" envconfig:"ZSCALER_CLIENT_DRY_RUN"`
+			RateLimit       struct {
 				MaxRetries                int32         `yaml:"maxRetries" envconfig:"ZSCALER_CLIENT_RATE_LIMIT_MAX_RETRIES"`
 				RetryWaitMin              time.Duration `yaml:"minWait" envconfig:"ZSCALER_CLIENT_RATE_LIMIT_MIN_WAIT"`
 				RetryWaitMax              time.Duration `yaml:"maxWait" envconfig:"ZSCALER_CLIENT_RATE_LIMIT_MAX_WAIT"`
 				RetryRemainingThreshold   int32         `yaml:"remainingThreshold" envconfig:"ZSCALER_CLIENT_REMAINING_THRESHOLD"`
 				MaxSessionNotValidRetries int32         `yaml:"maxSessionNotValidRetries" envconfig:"ZSCALER_CLIENT_MAX_SESSION_NOT_VALID_RETRIES"`
+				Adaptive                  bool          `yaml:"adaptive" envconfig:"ZSCALER_CLIENT_RATE_LIMIT_ADAPTIVE"`
+				SharedDir                 string        `yaml:"sharedDir" envconfig:"ZSCALER_CLIENT_RATE_LIMIT_SHARED_DIR"`
 			} `yaml:"rateLimit"`
 		} `yaml:"client"`
 		Testing struct {
 			DisableHttpsCheck bool `yaml:"disableHttpsCheck" envconfig:"ZSCALER_TESTING_DISABLE_HTTPS_CHECK"`
+			Recorder          struct {
+				Mode        string `yaml:"mode" envconfig:"ZSCALER_TESTING_RECORDER_MODE"`
+				CassetteDir string `yaml:"cassetteDir" envconfig:"ZSCALER_TESTING_CASSETTE_DIR"`
+				Cassette    string `yaml:"cassette" envconfig:"ZSCALER_TESTING_CASSETTE"`
+			} `yaml:"recorder"`
 		} `yaml:"testing"`
 	} `yaml:"zscaler"`
 	PrivateKeySigner jose.Signer
+	TokenSource      TokenSource
+	TokenCacheKey    []byte                  `ignored:"true"`
+	RedactionPolicy  *logger.RedactionPolicy `ignored:"true"`
 	CacheManager     cache.Cache
+	TracerProvider   trace.TracerProvider
+	RateLimitBackend rl.Backend
+	MeterProvider    metric.MeterProvider
 	UseLegacyClient  bool `yaml:"useLegacyClient" envconfig:"ZSCALER_USE_LEGACY_CLIENT"`
 	LegacyClient     *LegacyClient
+	// ClientAssertionSigner signs the JWT client assertion; see WithClientAssertionSigner.
+	ClientAssertionSigner crypto.Signer
+
+	recorder         *vcr.Recorder
+	recorderMode     RecorderMode
+	recorderCassette string
+	recorderErr      error
+
+	requestInterceptors  []RequestInterceptor
+	responseInterceptors []ResponseInterceptor
+
+	telemetry        *telemetry
+	rateLimiters     map[string]rl.Limiter
+	customCache      bool
+	customHTTPClient bool
+
+	profile    string
+	configFile string
+	layers     *configLayers
+
+	clientAssertionID func() string
+	tokenEvents       func(TokenEvent)
 }
 
 // NewConfiguration is the main configuration function, implementing the ConfigSetter pattern.
@@ -142,6 +200,9 @@ func NewConfiguration(conf ...ConfigSetter) (*Configuration, error) {
 	cfg.Zscaler.Client.RateLimit.RetryWaitMax = time.Second * time.Duration(RetryWaitMaxSeconds)
 	cfg.Zscaler.Client.RateLimit.RetryWaitMin = time.Second * time.Duration(RetryWaitMinSeconds)
 	cfg.Zscaler.Client.RateLimit.MaxSessionNotValidRetries = 3 // Default to 3 consecutive SESSION_NOT_VALID retries
+	cfg.Zscaler.Client.TokenRefresh.Skew = defaultTokenRefreshSkew
+	cfg.Zscaler.Client.TokenRefresh.MaxRetries = defaultTokenRefreshMaxRetries
+	cfg.Zscaler.Client.TokenRefresh.Backoff = defaultTokenRefreshBackoff
 
 	cfg.Zscaler.Client.RequestTimeout = time.Duration(requestTimeout) * time.Second
 
@@ -154,20 +215,37 @@ func NewConfiguration(conf ...ConfigSetter) (*Configuration, error) {
 		cfg.Zscaler.Client.Cache.DefaultTti = time.Minute * 8
 	}
 
-	cfg.CacheManager = newCache(cfg)
-
 	cfg.Zscaler.Testing.DisableHttpsCheck = false
 
-	cfg = readConfigFromSystem(*cfg)
+	// Settings are layered: defaults, then the configuration file, then the
+	// environment, then the setters.
+	cfg.layers = newConfigLayers(cfg)
+	cfg.profile = os.Getenv("ZSCALER_PROFILE")
+	cfg.configFile = os.Getenv("ZSCALER_CONFIG_FILE")
+	cfg.applyConfigFile()
 	cfg = readConfigFromEnvironment(*cfg)
 
-	setHttpClients(cfg)
-
 	// Apply each ConfigSetter function.
 	for _, confSetter := range conf {
 		confSetter(cfg)
 	}
 
+	// A profile or file that was asked for must load; the default file is optional.
+	if cfg.layers.explicit && cfg.layers.fileErr != nil {
+		return nil, fmt.Errorf("loading configuration: %w", cfg.layers.fileErr)
+	}
+
+	// The cache depends on the credentials, so it is built once every setter ran.
+	if !cfg.customCache {
+		cfg.CacheManager = newCache(cfg)
+	}
+
+	cfg.applyRedactionPolicy()
+	cfg.telemetry = newTelemetry(cfg)
+
+	// Setters only record options; the HTTP clients are built from all of them.
+	setHttpClients(cfg)
+
 	// Recheck and adjust defaults after setters are applied.
 	if cfg.Zscaler.Client.RateLimit.MaxRetries == 0 {
 		cfg.Zscaler.Client.RateLimit.MaxRetries = 4 // Default to 4 if user set it to zero.
@@ -192,6 +270,41 @@ func NewConfiguration(conf ...ConfigSetter) (*Configuration, error) {
 	return cfg, nil
 }
 
+// sharedRateLimitRules mirror the fixed windows of setHttpClients for budgets
+// shared across processes through a rate limit backend.
+var sharedRateLimitRules = map[string][]rl.Rule{
+	"zia": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 20, Period: 10 * time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 10, Period: 61 * time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 950, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite}, Limit: 950, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassDelete}, Limit: 380, Period: time.Hour},
+	},
+	"ztw": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 20, Period: 10 * time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 10, Period: 61 * time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 950, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite}, Limit: 950, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassDelete}, Limit: 380, Period: time.Hour},
+	},
+	"zpa": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 20, Period: 10 * time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 10, Period: 10 * time.Second},
+	},
+	"zcc": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 100, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 3, Period: 24 * time.Hour},
+	},
+	"zdx": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 100, Period: time.Hour},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 3, Period: 24 * time.Hour},
+	},
+	"admin": {
+		{Classes: []rl.MethodClass{rl.MethodClassRead}, Limit: 2, Period: time.Second},
+		{Classes: []rl.MethodClass{rl.MethodClassWrite, rl.MethodClassDelete}, Limit: 1, Period: time.Second},
+	},
+}
+
 func setHttpClients(cfg *Configuration) {
 	// ZIA-specific rate limits with hourly tracking:
 	// Per-second: GET: 2/sec, POST/PUT: 1/sec, DELETE: 1/sec
@@ -224,36 +337,71 @@ func setHttpClients(cfg *Configuration) {
 	// Default case for unknown or unhandled services
 	defaultRateLimiter := rl.NewRateLimiter(2, 1, 1, 1) // Default limits
 
+	// The fixed windows above stay in charge until the API reports its own budget.
+	cfg.rateLimiters = map[string]rl.Limiter{
+		"admin": defaultRateLimiter,
+		"zia":   ziaRateLimiter,
+		"ztw":   ztwRateLimiter,
+		"zpa":   zpaRateLimiter,
+		"zcc":   zccRateLimiter,
+		"zdx":   zdxRateLimiter,
+	}
+	if backend := cfg.rateLimitBackend(); backend != nil {
+		for product, fixed := range cfg.rateLimiters {
+			shared := rl.NewSharedRateLimiter(backend, func() string {
+				return strings.ToLower(cfg.Zscaler.Client.VanityDomain) + "/" + product
+			}, sharedRateLimitRules[product]...)
+			shared.Fallback = fixed
+			shared.Logger = cfg.Logger
+			cfg.rateLimiters[product] = shared
+		}
+	}
+	if cfg.Zscaler.Client.RateLimit.Adaptive {
+		for product, limiter := range cfg.rateLimiters {
+			cfg.rateLimiters[product] = rl.NewAdaptiveRateLimiter(limiter)
+		}
+	}
+
+	if err := cfg.initRecorder(); err != nil {
+		cfg.Logger.Printf("[ERROR] Failed to initialize HTTP recorder: %v", err)
+	}
+
 	// Pass the config to getHTTPClient so it can access proxy settings
-	cfg.HTTPClient = getHTTPClient(cfg.Logger, defaultRateLimiter, cfg)
-	cfg.ZIAHTTPClient = getHTTPClient(cfg.Logger, ziaRateLimiter, cfg)
-	cfg.ZTWHTTPClient = getHTTPClient(cfg.Logger, ztwRateLimiter, cfg)
-	cfg.ZPAHTTPClient = getHTTPClient(cfg.Logger, zpaRateLimiter, cfg)
-	cfg.ZCCHTTPClient = getHTTPClient(cfg.Logger, zccRateLimiter, cfg)
-	cfg.ZDXHTTPClient = getHTTPClient(cfg.Logger, zdxRateLimiter, cfg)
+	if !cfg.customHTTPClient {
+		cfg.HTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["admin"], cfg)
+	}
+	cfg.ZIAHTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["zia"], cfg)
+	cfg.ZTWHTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["ztw"], cfg)
+	cfg.ZPAHTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["zpa"], cfg)
+	cfg.ZCCHTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["zcc"], cfg)
+	cfg.ZDXHTTPClient = getHTTPClient(cfg.Logger, cfg.rateLimiters["zdx"], cfg)
 }
 
 // Authenticate performs OAuth2 authentication and retrieves an AuthToken.
 func Authenticate(ctx context.Context, cfg *Configuration, l logger.Logger) (*AuthToken, error) {
 	creds := cfg.Zscaler.Client
 
-	if creds.ClientID == "" || (creds.ClientSecret == "" && len(creds.PrivateKey) == 0) {
-		return nil, errors.New("no client credentials were provided")
+	if creds.ClientID == "" || (creds.ClientSecret == "" && !cfg.usesClientAssertion()) {
+		return nil, errorx.New(errorx.ErrValidation, "no client credentials were provided")
 	}
 
-	// If private key is provided, use JWT-based authentication.
-	if len(creds.PrivateKey) > 0 {
+	// If a private key or signer is provided, use JWT-based authentication.
+	if cfg.usesClientAssertion() {
 		return authenticateWithCert(cfg)
 	}
+	return authenticateWithSecret(ctx, cfg, l)
+}
 
-	// Determine the OAuth2 provider URL based on the cloud parameter.
-	var authUrl string
-	if creds.Cloud == "" || strings.EqualFold(creds.Cloud, "PRODUCTION") {
-		authUrl = fmt.Sprintf("https://%s.zslogin.net/oauth2/v1/token", creds.VanityDomain)
-	} else {
-		authUrl = fmt.Sprintf("https://%s.zslogin%s.net/oauth2/v1/token", creds.VanityDomain, strings.ToLower(creds.Cloud))
+// authenticateWithSecret performs the OAuth2 client-credentials exchange using the client secret.
+func authenticateWithSecret(ctx context.Context, cfg *Configuration, l logger.Logger) (*AuthToken, error) {
+	creds := cfg.Zscaler.Client
+
+	if creds.ClientID == "" || creds.ClientSecret == "" {
+		return nil, errorx.New(errorx.ErrValidation, "no client credentials were provided")
 	}
 
+	authUrl := cfg.tokenURL()
+
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 	data.Set("client_secret", creds.ClientSecret)
@@ -284,65 +432,52 @@ func Authenticate(ctx context.Context, cfg *Configuration, l logger.Logger) (*Au
 	}
 
 	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("[ERROR] Failed to sign in the user %s, got http status: %d, response body: %s", creds.ClientID, resp.StatusCode, respBody)
+		return nil, errorx.Errorf(errorx.AuthFailureKind(resp), "[ERROR] Failed to sign in the user %s, got http status: %d, response body: %s", creds.ClientID, resp.StatusCode, respBody)
 	}
 
 	var token AuthToken
 	if err := json.Unmarshal(respBody, &token); err != nil {
 		return nil, fmt.Errorf("[ERROR] Failed to sign in: %v", err)
 	}
+	if err := token.setExpiry(); err != nil {
+		return nil, err
+	}
+	cfg.Logger.Printf("[DEBUG] parsed expires_in=%s seconds, token expiry set to: %s", token.ExpiresIn, token.Expiry.Format(time.RFC3339))
+	return &token, nil
+}
 
-	secondsStr := token.ExpiresIn.String()
-	seconds, err := strconv.ParseInt(secondsStr, 10, 64)
+// setExpiry derives Expiry from the expires_in value of the token response.
+func (t *AuthToken) setExpiry() error {
+	seconds, err := strconv.ParseInt(t.ExpiresIn.String(), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("[ERROR] invalid expires_in value: %v", err)
+		return fmt.Errorf("[ERROR] invalid expires_in value: %v", err)
 	}
-	token.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
-	cfg.Logger.Printf("[DEBUG] parsed expires_in=%d seconds, token expiry set to: %s", seconds, token.Expiry.Format(time.RFC3339))
-	return &token, nil
+	t.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	return nil
 }
 
 // authenticateWithCert performs JWT-based authentication using a private key.
 func authenticateWithCert(cfg *Configuration) (*AuthToken, error) {
 	creds := cfg.Zscaler.Client
 
-	if creds.ClientID == "" || len(creds.PrivateKey) == 0 {
-		return nil, errors.New("client ID or private key is missing")
+	if creds.ClientID == "" || !cfg.usesClientAssertion() {
+		return nil, errorx.New(errorx.ErrValidation, "client ID or private key is missing")
 	}
 
-	// Create the JWT payload.
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	assertion, err := cfg.clientAssertion()
 	if err != nil {
-		return nil, fmt.Errorf("error parsing private key: %v", err)
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
-		"iss": creds.ClientID,
-		"sub": creds.ClientID,
-		"aud": "https://api.zscaler.com",
-		"exp": time.Now().Add(10 * time.Minute).Unix(),
-	})
-
-	assertion, err := token.SignedString(privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("error signing JWT: %v", err)
+		return nil, err
 	}
 
 	formData := url.Values{
 		"grant_type":            {"client_credentials"},
 		"client_id":             {creds.ClientID},
 		"client_assertion":      {assertion},
-		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
-		"audience":              {"https://api.zscaler.com"},
+		"client_assertion_type": {clientAssertionType},
+		"audience":              {clientAssertionAudience},
 	}
 
-	// Determine the OAuth2 provider URL based on the cloud parameter.
-	var authUrl string
-	if creds.Cloud == "" || strings.EqualFold(creds.Cloud, "PRODUCTION") {
-		authUrl = fmt.Sprintf("https://%s.zslogin.net/oauth2/v1/token", creds.VanityDomain)
-	} else {
-		authUrl = fmt.Sprintf("https://%s.zslogin%s.net/oauth2/v1/token", creds.VanityDomain, strings.ToLower(creds.Cloud))
-	}
+	authUrl := cfg.tokenURL()
 
 	// Make the POST request.
 	resp, err := cfg.HTTPClient.PostForm(authUrl, formData)
@@ -357,7 +492,7 @@ func authenticateWithCert(cfg *Configuration) (*AuthToken, error) {
 	}
 
 	if resp.StatusCode > 299 {
-		return nil, fmt.Errorf("auth error: %v", string(body))
+		return nil, errorx.Errorf(errorx.AuthFailureKind(resp), "auth error: %v", string(body))
 	}
 	// Parse the response.
 	var tokenResponse AuthToken
@@ -365,17 +500,16 @@ func authenticateWithCert(cfg *Configuration) (*AuthToken, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing response: %v", err)
 	}
+	if err := tokenResponse.setExpiry(); err != nil {
+		return nil, err
+	}
 
 	return &tokenResponse, nil
 }
 
 // getServiceHTTPClient returns the appropriate http client for the current service
 func (client *Client) getServiceHTTPClient(endpoint string) *http.Client {
-	service, err := detectServiceType(endpoint)
-	if err != nil {
-		return client.oauth2Credentials.HTTPClient
-	}
-	switch service {
+	switch client.productOf(endpoint) {
 	case "zpa":
 		return client.oauth2Credentials.ZPAHTTPClient
 	case "zia":
@@ -412,48 +546,66 @@ func detectServiceType(endpoint string) (string, error) {
 	return "", fmt.Errorf("unsupported service")
 }
 
-// GetAPIBaseURL gets the appropriate base url based on the cloud and sandbox mode.
-func GetAPIBaseURL(cloud string) string {
-	baseURL := "https://api.zsapi.net"
-	if cloud != "" && !strings.EqualFold(cloud, "PRODUCTION") {
-		baseURL = fmt.Sprintf("https://api.%s.zsapi.net", strings.ToLower(cloud))
+// applyRedactionPolicy hands the configured redaction policy to the logger.
+func (c *Configuration) applyRedactionPolicy() {
+	if c.RedactionPolicy == nil || logger.IsNop(c.Logger) {
+		return
 	}
-
-	return baseURL
+	if r, ok := c.Logger.(logger.RedactionSetter); ok {
+		r.SetRedactionPolicy(c.RedactionPolicy)
+		return
+	}
+	c.Logger = logger.NewRedactingLogger(c.Logger, c.RedactionPolicy)
 }
 
-func readConfigFromFile(location string, c Configuration) (*Configuration, error) {
-	yamlConfig, err := os.ReadFile(location)
-	if err != nil {
-		return nil, err
+// rateLimitBackend returns the configured backend for shared rate limit budgets,
+// opening a file backend in the shared directory when one is set.
+func (c *Configuration) rateLimitBackend() rl.Backend {
+	if c.RateLimitBackend != nil {
+		return c.RateLimitBackend
+	}
+	if c.Zscaler.Client.RateLimit.SharedDir == "" {
+		return nil
 	}
-	err = yaml.Unmarshal(yamlConfig, &c)
+	backend, err := rl.NewFileBackend(c.Zscaler.Client.RateLimit.SharedDir)
 	if err != nil {
-		return nil, err
+		c.Logger.Printf("[ERROR] Failed to open shared rate limit directory, using local limits: %v", err)
+		return nil
 	}
-	return &c, err
+	return backend
 }
 
-func readConfigFromSystem(c Configuration) *Configuration {
-	currUser, err := user.Current()
-	if err != nil {
-		return &c
+// tokenURL returns the OAuth2 token endpoint for the configured vanity domain and cloud,
+// or the one served under the base URL override when it is set.
+func (c *Configuration) tokenURL() string {
+	creds := c.Zscaler.Client
+	if creds.BaseURL != "" {
+		return strings.TrimSuffix(creds.BaseURL, "/") + "/oauth2/v1/token"
 	}
-	if currUser.HomeDir == "" {
-		return &c
+	if creds.Cloud == "" || strings.EqualFold(creds.Cloud, "PRODUCTION") {
+		return fmt.Sprintf("https://%s.zslogin.net/oauth2/v1/token", creds.VanityDomain)
 	}
-	conf, err := readConfigFromFile(currUser.HomeDir+"/.zscaler/zscaler.yaml", c)
-	if err != nil {
-		return &c
+	return fmt.Sprintf("https://%s.zslogin%s.net/oauth2/v1/token", creds.VanityDomain, strings.ToLower(creds.Cloud))
+}
+
+// GetAPIBaseURL gets the appropriate base url based on the cloud and sandbox mode.
+func GetAPIBaseURL(cloud string) string {
+	baseURL := "https://api.zsapi.net"
+	if cloud != "" && !strings.EqualFold(cloud, "PRODUCTION") {
+		baseURL = fmt.Sprintf("https://api.%s.zsapi.net", strings.ToLower(cloud))
 	}
-	return conf
+
+	return baseURL
 }
 
 func readConfigFromEnvironment(c Configuration) *Configuration {
 	err := envconfig.Process("zscaler", &c)
 	if err != nil {
-		fmt.Println("error parsing")
-		return &c
+		c.Logger.Printf("[ERROR] Failed to read configuration from the environment: %v", err)
+	}
+	if c.layers != nil {
+		c.layers.envErr = err
+		c.layers.record(&c, ConfigSourceEnvironment)
 	}
 	return &c
 }
@@ -506,6 +658,14 @@ func WithSandboxCloud(sandboxCloud string) ConfigSetter {
 	}
 }
 
+// WithBaseURL sends every API, sandbox and token request to baseURL instead of the
+// Zscaler cloud endpoints, e.g. to run against a local test server.
+func WithBaseURL(baseURL string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.BaseURL = baseURL
+	}
+}
+
 func WithZPACustomerID(customerID string) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.CustomerID = customerID
@@ -533,6 +693,17 @@ func WithCache(cache bool) ConfigSetter {
 func WithCacheManager(cacheManager cache.Cache) ConfigSetter {
 	return func(c *Configuration) {
 		c.CacheManager = cacheManager
+		c.customCache = true
+	}
+}
+
+// WithCacheDir enables the response cache and persists it in dir, so entries
+// survive across processes. Expired entries are revalidated with conditional
+// requests when the API returned an ETag or Last-Modified header.
+func WithCacheDir(dir string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.Cache.Enabled = true
+		c.Zscaler.Client.Cache.Dir = dir
 	}
 }
 
@@ -540,6 +711,14 @@ func newCache(c *Configuration) cache.Cache {
 	if !c.Zscaler.Client.Cache.Enabled {
 		return cache.NewNopCache()
 	}
+	if c.Zscaler.Client.Cache.Dir != "" {
+		cche, err := newDiskCache(c)
+		if err != nil {
+			c.Logger.Printf("[ERROR] Failed to open cache directory, using the in-memory cache: %v", err)
+		} else {
+			return cche
+		}
+	}
 	cche, err := cache.NewCache(time.Duration(c.Zscaler.Client.Cache.DefaultTtl), time.Duration(c.Zscaler.Client.Cache.DefaultTti), int(c.Zscaler.Client.Cache.DefaultCacheMaxSizeMB))
 	if err != nil {
 		return cache.NewNopCache()
@@ -547,24 +726,35 @@ func newCache(c *Configuration) cache.Cache {
 	return cche
 }
 
+// newDiskCache opens the cache directory of the configured credentials. Each
+// tenant and client gets its own subdirectory, and entries are encrypted with
+// the token cache key, so clients sharing a directory never read each other's
+// responses.
+func newDiskCache(c *Configuration) (cache.Cache, error) {
+	key, err := tokenCacheKey(c)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(c.Zscaler.Client.Cache.Dir, credentialsID(c))
+	return cache.NewDiskCache(dir, c.Zscaler.Client.Cache.DefaultTtl, int(c.Zscaler.Client.Cache.DefaultCacheMaxSizeMB),
+		cache.WithEncryptionKey(append([]byte("zscaler-response-cache|"), key...)))
+}
+
 func WithCacheTtl(i time.Duration) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.Cache.DefaultTtl = i
-		c.CacheManager = newCache(c)
 	}
 }
 
 func WithCacheMaxSizeMB(size int64) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.Cache.DefaultCacheMaxSizeMB = size
-		c.CacheManager = newCache(c)
 	}
 }
 
 func WithCacheTti(i time.Duration) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.Cache.DefaultTti = i
-		c.CacheManager = newCache(c)
 	}
 }
 
@@ -572,6 +762,7 @@ func WithCacheTti(i time.Duration) ConfigSetter {
 func WithHttpClientPtr(httpClient *http.Client) ConfigSetter {
 	return func(c *Configuration) {
 		c.HTTPClient = httpClient
+		c.customHTTPClient = true
 	}
 }
 
@@ -608,35 +799,39 @@ func WithTestingDisableHttpsCheck(httpsCheck bool) ConfigSetter {
 func WithRequestTimeout(requestTimeout time.Duration) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.RequestTimeout = requestTimeout
-		setHttpClients(c)
+	}
+}
+
+// WithPageConcurrency lets the ZPA and ZIdentity list helpers fetch up to n
+// pages at once after the first page has reported the total. Requests still go
+// through the product rate limiters. The default of 1 fetches pages one by one.
+func WithPageConcurrency(n int32) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.PageConcurrency = n
 	}
 }
 
 func WithRateLimitMaxRetries(maxRetries int32) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.RateLimit.MaxRetries = maxRetries
-		setHttpClients(c)
 	}
 }
 
 func WithRateLimitMaxWait(maxWait time.Duration) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.RateLimit.RetryWaitMax = maxWait
-		setHttpClients(c)
 	}
 }
 
 func WithRateLimitMinWait(minWait time.Duration) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.RateLimit.RetryWaitMin = minWait
-		setHttpClients(c)
 	}
 }
 
 func WithRateLimitRemainingThreshold(threshold int32) ConfigSetter {
 	return func(c *Configuration) {
 		c.Zscaler.Client.RateLimit.RetryRemainingThreshold = threshold
-		setHttpClients(c)
 	}
 }
 
@@ -646,6 +841,32 @@ func WithRateLimitMaxSessionNotValidRetries(maxRetries int32) ConfigSetter {
 	}
 }
 
+// WithRateLimitBackend shares rate limit budgets through backend. Every process
+// using the same backend and vanity domain draws from one budget per product and
+// method class.
+func WithRateLimitBackend(backend rl.Backend) ConfigSetter {
+	return func(c *Configuration) {
+		c.RateLimitBackend = backend
+	}
+}
+
+// WithSharedRateLimitDir shares rate limit budgets with the other processes on
+// the host that use the same directory.
+func WithSharedRateLimitDir(dir string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.RateLimit.SharedDir = dir
+	}
+}
+
+// WithAdaptiveRateLimit makes the client-side rate limiters follow the budget the
+// API reports in rate-limit response headers, per product and method class. The
+// fixed windows remain in use until those headers are seen.
+func WithAdaptiveRateLimit(adaptive bool) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.RateLimit.Adaptive = adaptive
+	}
+}
+
 // WithUserAgent sets the UserAgent in the Config.
 func WithUserAgentExtra(userAgent string) ConfigSetter {
 	return func(c *Configuration) {
@@ -664,6 +885,28 @@ func WithDebug(debug bool) ConfigSetter {
 	}
 }
 
+// WithLogger replaces the logger used by the client and its HTTP transports.
+func WithLogger(l logger.Logger) ConfigSetter {
+	return func(c *Configuration) {
+		c.Logger = l
+	}
+}
+
+// WithSlogHandler sends SDK log output to a log/slog handler. Messages are leveled,
+// redacted and enriched with request ID, product, method, path, status, duration
+// and retry count attributes.
+func WithSlogHandler(handler slog.Handler) ConfigSetter {
+	return WithLogger(logger.NewSlogLogger(handler, nil))
+}
+
+// WithRedactionPolicy sets the values masked in log output. Loggers that do not
+// support redaction themselves are wrapped.
+func WithRedactionPolicy(policy *logger.RedactionPolicy) ConfigSetter {
+	return func(c *Configuration) {
+		c.RedactionPolicy = policy
+	}
+}
+
 // WithPrivateKey sets private key, privateKey can be the raw key value or a path to the pem file.
 func WithPrivateKey(privateKey string) ConfigSetter {
 	return func(c *Configuration) {
@@ -679,6 +922,48 @@ func WithPrivateKey(privateKey string) ConfigSetter {
 	}
 }
 
+// WithTokenSource sets a custom TokenSource used to obtain OAuth2 access tokens,
+// replacing the built-in client secret and JWT flows.
+func WithTokenSource(tokenSource TokenSource) ConfigSetter {
+	return func(c *Configuration) {
+		c.TokenSource = tokenSource
+	}
+}
+
+// WithTokenCache enables the persistent on-disk token cache. An empty path selects
+// a per-tenant file under ~/.zscaler/cache.
+func WithTokenCache(enabled bool, path string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.TokenCache.Enabled = enabled
+		c.Zscaler.Client.TokenCache.Path = path
+	}
+}
+
+// WithTokenCacheKey sets the key the token cache and the response cache
+// directory are encrypted with. It is required with a custom TokenSource, since
+// there is then no client secret or private key to derive the key from.
+func WithTokenCacheKey(key []byte) ConfigSetter {
+	return func(c *Configuration) {
+		c.TokenCacheKey = key
+	}
+}
+
+// WithRecorder wraps every HTTP client with a record/replay transport backed by a
+// cassette in cassetteDir. Credentials are scrubbed from cassettes before they are saved.
+func WithRecorder(mode RecorderMode, cassetteDir string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Testing.Recorder.Mode = string(mode)
+		c.Zscaler.Testing.Recorder.CassetteDir = cassetteDir
+	}
+}
+
+// WithRecorderCassette sets the cassette name used by the recorder (defaults to "zscaler").
+func WithRecorderCassette(name string) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Testing.Recorder.Cassette = name
+	}
+}
+
 func WithPrivateKeySigner(signer jose.Signer) ConfigSetter {
 	return func(c *Configuration) {
 		c.PrivateKeySigner = signer
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -47,7 +49,11 @@ type inFlightRequest struct {
 type Client struct {
 	sync.Mutex
 	oauth2Credentials *Configuration
-	stopTicker        chan bool
+	refresh           *tokenRefresh
+	plan              *Plan
+	scim              *scimTarget
+	mutationHooks     map[int]func(product, endpoint string)
+	nextHook          int
 	inFlightRequests  sync.Map // Map[string]*inFlightRequest - tracks in-flight GET requests for deduplication
 }
 
@@ -55,73 +61,57 @@ type Client struct {
 func NewOneAPIClient(config *Configuration) (*Service, error) {
 	cli := &Client{
 		oauth2Credentials: config,
-		stopTicker:        make(chan bool),
+	}
+	if config.Zscaler.Client.DryRun {
+		cli.plan = NewPlan(config.RedactionPolicy)
 	}
 
 	if !config.UseLegacyClient {
-		// Authenticate and start token renewal
-		if err := cli.authenticate(); err != nil {
+		if err := config.resolveTokenSource(); err != nil {
+			return nil, fmt.Errorf("token source setup failed: %w", err)
+		}
+		// The token is renewed on demand by the requests that find it expiring.
+		if err := cli.authenticate(config.Context); err != nil {
 			return nil, fmt.Errorf("initial authentication failed: %w", err)
 		}
-		cli.startTokenRenewalTicker()
 	}
 
 	return NewService(cli, nil), nil
 }
 
-// startTokenRenewalTicker starts a ticker to renew the token before it expires.
-func (c *Client) startTokenRenewalTicker() {
-	tokenExpiry := time.Now()
-	if c.oauth2Credentials.Zscaler.Client.AuthToken != nil {
-		tokenExpiry = c.oauth2Credentials.Zscaler.Client.AuthToken.Expiry
-	}
-	renewalInterval := time.Until(tokenExpiry) - (time.Minute * 1) // Renew 1 minute before expiration
-
-	if renewalInterval > 0 {
-		ticker := time.NewTicker(renewalInterval)
-		go func() {
-			for {
-				select {
-				case <-ticker.C:
-					// Refresh the token with proper locking to prevent race conditions
-					c.Lock()
-					authToken, err := Authenticate(c.oauth2Credentials.Context, c.oauth2Credentials, c.oauth2Credentials.Logger)
-					if err != nil {
-						c.oauth2Credentials.Logger.Printf("[ERROR] Failed to renew OAuth2 token: %v", err)
-					} else {
-						c.oauth2Credentials.Zscaler.Client.AuthToken = authToken
-						c.oauth2Credentials.Logger.Printf("[INFO] OAuth2 token successfully renewed")
-						// Reset the ticker for the next renewal
-						renewalInterval = time.Until(authToken.Expiry) - (time.Minute * 1)
-						ticker.Reset(renewalInterval)
-					}
-					c.Unlock()
-				case <-c.stopTicker:
-					ticker.Stop()
-					return
-				}
-			}
-		}()
+// Close cleans up resources held by the client.
+func (c *Client) Close() {
+	if err := c.oauth2Credentials.StopRecorder(); err != nil {
+		c.oauth2Credentials.Logger.Printf("[ERROR] Failed to save HTTP recorder cassette: %v", err)
 	}
 }
 
-// Close stops the token renewal ticker and cleans up resources.
-func (c *Client) Close() {
-	c.Lock()
-	defer c.Unlock()
+func (client *Client) GetLogger() logger.Logger {
+	return client.oauth2Credentials.Logger
+}
 
-	if c.stopTicker != nil {
-		close(c.stopTicker)
-		c.stopTicker = nil
+// RateLimitState returns the budget each product's rate limiter currently holds,
+// keyed by product. Only adaptive limiters report state; see WithAdaptiveRateLimit.
+func (client *Client) RateLimitState() map[string][]rl.State {
+	states := make(map[string][]rl.State)
+	for product, limiter := range client.oauth2Credentials.rateLimiters {
+		if reporter, ok := limiter.(rl.StateReporter); ok {
+			states[product] = reporter.State()
+		}
 	}
+	return states
 }
 
-func (client *Client) GetLogger() logger.Logger {
-	return client.oauth2Credentials.Logger
+// PageConcurrency returns how many pages the list helpers may fetch at once.
+func (client *Client) PageConcurrency() int {
+	if n := int(client.oauth2Credentials.Zscaler.Client.PageConcurrency); n > 1 {
+		return n
+	}
+	return 1
 }
 
 // getHTTPClient sets up the retryable HTTP client with backoff and retry policies.
-func getHTTPClient(l logger.Logger, rateLimiter *rl.RateLimiter, cfg *Configuration) *http.Client {
+func getHTTPClient(l logger.Logger, rateLimiter rl.Limiter, cfg *Configuration) *http.Client {
 	retryableClient := retryablehttp.NewClient()
 
 	// Set the retry settings, allowing user to override defaults.
@@ -166,6 +156,31 @@ func getHTTPClient(l logger.Logger, rateLimiter *rl.RateLimiter, cfg *Configurat
 		retryableClient.HTTPClient.Timeout = cfg.Zscaler.Client.RequestTimeout
 	}
 
+	base := cfg.recorderTransport(newBaseTransport(l, cfg))
+
+	// Wrap the transport with rate limiting. Replayed requests never reach the
+	// network, so they are not rate limited.
+	if rateLimiter != nil && !cfg.isReplaying() {
+		rateLimitedTransport := &rl.RateLimitTransport{
+			Base:            base,
+			Limiter:         rateLimiter,
+			Logger:          l,
+			AdditionalDelay: 0,
+			OnWait: func(req *http.Request, d time.Duration) {
+				recordLimiterWait(req.Context(), d)
+			},
+		}
+		retryableClient.HTTPClient.Transport = rateLimitedTransport
+	} else {
+		retryableClient.HTTPClient.Transport = base
+	}
+	retryableClient.HTTPClient.Transport = &telemetryTransport{base: retryableClient.HTTPClient.Transport, cfg: cfg}
+
+	return retryableClient.StandardClient()
+}
+
+// newBaseTransport builds the network transport honoring the proxy and TLS settings of the configuration.
+func newBaseTransport(l logger.Logger, cfg *Configuration) *http.Transport {
 	// Configure proxy settings from configuration
 	proxyFunc := http.ProxyFromEnvironment // Default behavior (uses system/env variables)
 	if cfg.Zscaler.Client.Proxy.Host != "" {
@@ -199,20 +214,7 @@ func getHTTPClient(l logger.Logger, rateLimiter *rl.RateLimiter, cfg *Configurat
 		l.Printf("[INFO] HTTPS certificate validation is disabled (testing mode).")
 	}
 
-	// Wrap the transport with rate limiting
-	if rateLimiter != nil {
-		rateLimitedTransport := &rl.RateLimitTransport{
-			Base:            transport,
-			Limiter:         rateLimiter,
-			Logger:          l,
-			AdditionalDelay: 0,
-		}
-		retryableClient.HTTPClient.Transport = rateLimitedTransport
-	} else {
-		retryableClient.HTTPClient.Transport = transport
-	}
-
-	return retryableClient.StandardClient()
+	return transport
 }
 
 func getRetryAfter(resp *http.Response, cfg *Configuration) time.Duration {
@@ -319,6 +321,9 @@ func (c *Client) buildRequest(ctx context.Context, method, endpoint string, body
 	if contentType == "" {
 		contentType = contentTypeJSON
 	}
+	if c.scim != nil {
+		return c.buildScimRequest(ctx, method, endpoint, body, urlParams, contentType)
+	}
 
 	// Initialize urlParams if it's nil to prevent panic when calling urlParams.Set()
 	if urlParams == nil {
@@ -339,9 +344,15 @@ func (c *Client) buildRequest(ctx context.Context, method, endpoint string, body
 	} else {
 		baseUrl = GetAPIBaseURL(c.oauth2Credentials.Zscaler.Client.Cloud)
 	}
+	overrideURL := strings.TrimSuffix(c.oauth2Credentials.Zscaler.Client.BaseURL, "/")
+	if overrideURL != "" {
+		baseUrl = overrideURL
+	}
 	if isSandboxRequest {
-		fullURL = fmt.Sprintf("%s%s", c.GetSandboxURL(), endpoint)
+		fullURL = fmt.Sprintf("%s%s", baseUrl, endpoint)
 		urlParams.Set("api_token", c.GetSandboxToken()) // Append Sandbox token
+	} else if isZIdentityRequest && overrideURL != "" {
+		fullURL = fmt.Sprintf("%s%s", overrideURL, endpoint)
 	} else if isZIdentityRequest {
 		// For zidentity endpoints, construct URL using vanity domain directly
 		// Format: https://{vanity_domain}-admin.zslogin{cloud}.net/admin/api/v1/...
@@ -395,20 +406,20 @@ func (c *Client) buildRequest(ctx context.Context, method, endpoint string, body
 
 	// For non-sandbox requests, handle OAuth2 authentication
 	if !isSandboxRequest {
-		err = c.authenticate()
-		if err != nil {
-			return nil, err
-		}
 		// Extract token from context if available
 		if token, ok := ctx.Value(ContextAccessToken).(string); ok && token != "" {
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 			if c.oauth2Credentials.Debug {
 				c.oauth2Credentials.Logger.Printf("[DEBUG] Using Authorization header from context: Bearer %s...", token[:min(len(token), 20)])
 			}
-		} else {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.oauth2Credentials.Zscaler.Client.AuthToken.AccessToken))
+		} else if !c.oauth2Credentials.UseLegacyClient {
+			authToken, err := c.token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken.AccessToken))
 			if c.oauth2Credentials.Debug {
-				token := c.oauth2Credentials.Zscaler.Client.AuthToken.AccessToken
+				token := authToken.AccessToken
 				c.oauth2Credentials.Logger.Printf("[DEBUG] Using Authorization header from AuthToken: Bearer %s...", token[:min(len(token), 20)])
 			}
 		}
@@ -417,7 +428,64 @@ func (c *Client) buildRequest(ctx context.Context, method, endpoint string, body
 	return req, nil
 }
 
+type noCacheContextKey struct{}
+
+// WithoutCache returns a context whose GET requests bypass the response cache:
+// they are neither served from nor stored in it. Use it to poll state that
+// changes without a mutation from this client, such as a running report task.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheContextKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(noCacheContextKey{}).(bool)
+	return bypass
+}
+
+type streamContextKey struct{}
+
+// ExecuteStreamRequest sends a request like ExecuteRequest but returns the
+// response with its body unread, so large downloads such as CSV reports are
+// never held in memory. The response is not cached and the caller must close
+// its body. Retries only happen before the body is returned.
+func (c *Client) ExecuteStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) (*http.Response, error) {
+	if c.oauth2Credentials.UseLegacyClient {
+		return nil, fmt.Errorf("streaming requests are not supported by legacy clients")
+	}
+	ctx = context.WithValue(WithoutCache(ctx), streamContextKey{}, true)
+	_, resp, _, err := c.ExecuteRequest(ctx, method, endpoint, body, urlParams, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func streamed(ctx context.Context) bool {
+	stream, _ := ctx.Value(streamContextKey{}).(bool)
+	return stream
+}
+
+// ExecuteRequest sends a request to the OneAPI, serving GETs from the cache when
+// enabled and retrying rate limited, session invalidated and failed attempts.
 func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) ([]byte, *http.Response, *http.Request, error) {
+	if c.plan != nil && c.isMutating(method, endpoint) {
+		return c.planRequest(ctx, method, endpoint, body, urlParams)
+	}
+	mutating := c.isMutating(method, endpoint)
+	if j := journalFrom(ctx); j != nil && mutating {
+		return c.journaledRequest(ctx, j, method, endpoint, body, urlParams, contentType)
+	}
+	product := c.productOf(endpoint)
+	ctx, call := c.oauth2Credentials.telemetry.startCall(ctx, product, method, endpoint)
+	respBody, resp, req, err := c.executeRequest(ctx, call, method, endpoint, body, urlParams, contentType)
+	call.end(ctx, resp, err)
+	if err == nil && mutating {
+		c.notifyMutation(endpoint)
+	}
+	return respBody, resp, req, err
+}
+
+func (c *Client) executeRequest(ctx context.Context, call *callTrace, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) ([]byte, *http.Response, *http.Request, error) {
 	// Buffer the request body so we can retry with SESSION_NOT_VALID errors
 	var requestBodyBytes []byte
 	if body != nil {
@@ -434,15 +502,21 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 	}
 
 	isSandboxRequest := strings.Contains(endpoint, "/zscsb")
+	product := c.productOf(endpoint)
 	overallStartTime := time.Now()
 	totalWaitTime := time.Duration(0) // Track time spent waiting for rate limits
+	if call != nil {
+		defer func() { call.totalWait = totalWaitTime }()
+	}
 
 	// Create cache key using the actual request
 	key := cache.CreateCacheKey(req)
 
 	// For GET requests, check cache first and handle request deduplication
 	var ifr *inFlightRequest
-	if method == http.MethodGet && c.oauth2Credentials.Zscaler.Client.Cache.Enabled && !isSandboxRequest {
+	var stale *http.Response
+	bypassCache := cacheBypassed(ctx)
+	if method == http.MethodGet && c.oauth2Credentials.Zscaler.Client.Cache.Enabled && !isSandboxRequest && !bypassCache {
 		// Check cache first
 		cachedResp := c.oauth2Credentials.CacheManager.Get(key)
 		if cachedResp != nil {
@@ -451,6 +525,7 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 				cachedResp.Body = io.NopCloser(bytes.NewBuffer(respData))
 			}
 			c.oauth2Credentials.Logger.Printf("[INFO] served from cache, key:%s\n", key)
+			call.cacheHit(ctx)
 			return respData, cachedResp, req, nil
 		}
 
@@ -458,6 +533,7 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 		if inFlight, exists := c.inFlightRequests.Load(key); exists {
 			if existingIfr, ok := inFlight.(*inFlightRequest); ok {
 				c.oauth2Credentials.Logger.Printf("[INFO] waiting for in-flight request to complete, key:%s\n", key)
+				call.deduplicated(ctx)
 				existingIfr.wg.Wait() // Wait for the in-flight request to complete
 				// Once the request completes, check cache again
 				cachedResp := c.oauth2Credentials.CacheManager.Get(key)
@@ -467,6 +543,7 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 						cachedResp.Body = io.NopCloser(bytes.NewBuffer(respData))
 					}
 					c.oauth2Credentials.Logger.Printf("[INFO] served from cache after waiting for in-flight request, key:%s\n", key)
+					call.cacheHit(ctx)
 					return respData, cachedResp, req, nil
 				}
 				// If still not in cache, there was an error - check the in-flight request error
@@ -477,6 +554,13 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 			}
 		}
 
+		call.cacheMiss(ctx)
+
+		// An expired entry is revalidated with a conditional request instead of refetched.
+		if rc, ok := c.oauth2Credentials.CacheManager.(cache.RevalidatingCache); ok {
+			stale = rc.GetStale(key)
+		}
+
 		// Create new in-flight request and mark it as in-flight
 		ifr = &inFlightRequest{}
 		ifr.wg.Add(1)
@@ -504,7 +588,7 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 	for retry := 1; ; retry++ { // Infinite loop for retries if MaxRetries=0
 		// Check MaxRetries if non-zero
 		if c.oauth2Credentials.Zscaler.Client.RateLimit.MaxRetries > 0 && retry > int(c.oauth2Credentials.Zscaler.Client.RateLimit.MaxRetries) {
-			return nil, resp, nil, fmt.Errorf("max retries exceeded")
+			return nil, resp, nil, errorx.New(errorx.StatusKind(resp), "max retries exceeded")
 		}
 
 		// Check RequestTimeout - exclude rate limiting wait times from the calculation
@@ -513,17 +597,46 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 		if c.oauth2Credentials.Zscaler.Client.RequestTimeout > 0 && elapsedTime >= c.oauth2Credentials.Zscaler.Client.RequestTimeout {
 			c.oauth2Credentials.Logger.Printf("[ERROR] Request timeout exceeded: elapsed=%v, waited=%v, total=%v, timeout=%v",
 				elapsedTime, totalWaitTime, time.Since(overallStartTime), c.oauth2Credentials.Zscaler.Client.RequestTimeout)
-			return nil, resp, nil, fmt.Errorf("request timeout exceeded after %v (excluding %v of rate limit waits)",
+			return nil, resp, nil, errorx.Errorf(errorx.ErrTimeout, "request timeout exceeded after %v (excluding %v of rate limit waits)",
 				elapsedTime, totalWaitTime)
 		}
 
+		if stale != nil && !cache.SetConditionalHeaders(req, stale) {
+			stale = nil
+		}
+
+		info := RequestInfo{Product: product, Method: method, Endpoint: endpoint, Attempt: retry}
+		if err := c.interceptRequest(ctx, req, info); err != nil {
+			return nil, resp, req, err
+		}
+
 		start := time.Now()
 		reqID := uuid.New().String()
 		logger.LogRequest(c.oauth2Credentials.Logger, req, reqID, nil, !isSandboxRequest)
 		httpClient := c.getServiceHTTPClient(endpoint)
 		resp, err = httpClient.Do(req)
 		logger.LogResponse(c.oauth2Credentials.Logger, resp, start, reqID)
+		attempt := logger.Attempt{
+			RequestID: reqID,
+			Product:   product,
+			Method:    req.Method,
+			Path:      req.URL.Path,
+			Duration:  time.Since(start),
+			Retry:     retry - 1,
+			Err:       err,
+		}
+		if resp != nil {
+			attempt.Status = resp.StatusCode
+		}
+		logger.LogAttempt(ctx, c.oauth2Credentials.Logger, attempt)
+		if interceptErr := c.interceptResponse(ctx, resp, info, err); interceptErr != nil {
+			return nil, resp, req, interceptErr
+		}
 		if err != nil {
+			var netErr net.Error
+			if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+				err = errorx.Errorf(errorx.ErrTimeout, "%w", err)
+			}
 			return nil, resp, nil, err
 		}
 
@@ -537,38 +650,36 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 				if errorx.IsSessionInvalidError(resp) {
 					sessionNotValidRetryCount++
 					if sessionNotValidRetryCount > maxSessionNotValidRetries {
-						return nil, resp, req, fmt.Errorf("max SESSION_NOT_VALID retries exceeded (%d), possible authentication issue", maxSessionNotValidRetries)
+						return nil, resp, req, errorx.Errorf(errorx.ErrSessionInvalid, "max SESSION_NOT_VALID retries exceeded (%d), possible authentication issue", maxSessionNotValidRetries)
 					}
 
 					c.oauth2Credentials.Logger.Printf("[WARN] Session invalidation detected (attempt %d, session retry %d/%d), refreshing token and retrying...", retry, sessionNotValidRetryCount, maxSessionNotValidRetries)
 
 					// Enhanced debugging for session invalidation analysis
+					staleToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
 					if c.oauth2Credentials.Debug {
+						c.Lock()
 						tok := c.oauth2Credentials.Zscaler.Client.AuthToken
+						c.Unlock()
 						c.oauth2Credentials.Logger.Printf("[DEBUG] Session invalidation analysis:")
 						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Token exists: %v", tok != nil)
-						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Token expiry: %s", tok.Expiry.Format(time.RFC3339))
+						if tok != nil {
+							c.oauth2Credentials.Logger.Printf("[DEBUG]   - Token expiry: %s", tok.Expiry.Format(time.RFC3339))
+							c.oauth2Credentials.Logger.Printf("[DEBUG]   - Time until expiry: %.2f seconds", time.Until(tok.Expiry).Seconds())
+						}
 						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Current time: %s", time.Now().Format(time.RFC3339))
-						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Time until expiry: %.2f seconds", time.Until(tok.Expiry).Seconds())
 						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Request URL: %s", req.URL.String())
 						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Request method: %s", req.Method)
-						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Authorization header present: %v", req.Header.Get("Authorization") != "")
-						if req.Header.Get("Authorization") != "" {
-							authHeader := req.Header.Get("Authorization")
-							c.oauth2Credentials.Logger.Printf("[DEBUG]   - Authorization header present: %v", authHeader != "")
-						}
+						c.oauth2Credentials.Logger.Printf("[DEBUG]   - Authorization header present: %v", staleToken != "")
 					}
 
 					// Force token refresh regardless of client-side validation
-					// Session invalidation means the server considers the token invalid
-					c.Lock() // Prevent concurrent token refresh
-					authToken, err := Authenticate(c.oauth2Credentials.Context, c.oauth2Credentials, c.oauth2Credentials.Logger)
-					if err != nil {
-						c.Unlock()
+					// Session invalidation means the server considers the token invalid.
+					// Requests failing with the same token share one refresh.
+					c.emitTokenEvent(TokenEvent{Type: TokenEventInvalidated, Reason: TokenRefreshSessionInvalid})
+					if _, err := c.refreshToken(ctx, TokenRefreshSessionInvalid, staleToken); err != nil {
 						return nil, resp, req, fmt.Errorf("token refresh failed after session invalidation: %w", err)
 					}
-					c.oauth2Credentials.Zscaler.Client.AuthToken = authToken
-					c.Unlock()
 					c.oauth2Credentials.Logger.Printf("[INFO] Token refreshed successfully, retrying request...")
 
 					// Add a small delay before retrying to avoid overwhelming the server
@@ -599,7 +710,10 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 				// If Retry-After is very long (> 5 minutes), fail fast with clear message
 				if retryAfter > 5*time.Minute {
 					c.oauth2Credentials.Logger.Printf("[ERROR] Rate limit exceeded with very long Retry-After: %v. This typically indicates hourly rate limits have been reached.", retryAfter)
-					return nil, resp, nil, fmt.Errorf("rate limit exceeded: API requires waiting %v before retrying. This typically means hourly rate limits (GET: 1000/hr, POST/PUT: 1000/hr, DELETE: 400/hr) have been reached. Please wait before retrying", retryAfter)
+					return nil, resp, nil, &errorx.RateLimitError{
+						RetryAfter: retryAfter,
+						Message:    fmt.Sprintf("rate limit exceeded: API requires waiting %v before retrying. This typically means hourly rate limits (GET: 1000/hr, POST/PUT: 1000/hr, DELETE: 400/hr) have been reached. Please wait before retrying", retryAfter),
+					}
 				}
 
 				// Check if context is still valid before sleeping
@@ -618,6 +732,14 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 			// If no Retry-After header, fall through to exponential backoff
 		}
 
+		// A 304 confirms that the stale cache entry is still current
+		if resp.StatusCode == http.StatusNotModified && stale != nil {
+			_ = tryDrainBody(resp.Body)
+			c.oauth2Credentials.Logger.Printf("[INFO] cache entry revalidated, key:%s\n", key)
+			resp = cache.Revalidated(stale, resp)
+			break
+		}
+
 		// Handle success
 		if resp.StatusCode < 300 {
 			break
@@ -681,6 +803,10 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 		}
 	}
 
+	if streamed(ctx) {
+		return nil, resp, req, nil
+	}
+
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, resp, nil, err
@@ -695,7 +821,7 @@ func (c *Client) ExecuteRequest(ctx context.Context, method, endpoint string, bo
 	}
 
 	// Cache logic for successful GET requests
-	if !isSandboxRequest && c.oauth2Credentials.Zscaler.Client.Cache.Enabled && method == http.MethodGet {
+	if !isSandboxRequest && c.oauth2Credentials.Zscaler.Client.Cache.Enabled && method == http.MethodGet && !bypassCache {
 		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		c.oauth2Credentials.Logger.Printf("[INFO] saving to cache, key:%s\n", key)
 		c.oauth2Credentials.CacheManager.Set(key, cache.CopyResponse(resp))
@@ -726,49 +852,16 @@ func (c *Client) GetSandboxToken() string {
 	return c.oauth2Credentials.Zscaler.Client.SandboxToken
 }
 
-func (c *Client) authValid() bool {
-	tok := c.oauth2Credentials.Zscaler.Client.AuthToken
-
-	if tok == nil || tok.AccessToken == "" || tok.Expiry.IsZero() {
-		if c.oauth2Credentials.Logger != nil {
-			c.oauth2Credentials.Logger.Printf("[DEBUG] authValid: token is nil or expiry not set")
-		}
-		return false
-	}
-
-	expiresIn := time.Until(tok.Expiry).Seconds()
-	valid := time.Now().Before(tok.Expiry.Add(-30 * time.Second))
-
-	if c.oauth2Credentials.Logger != nil {
-		c.oauth2Credentials.Logger.Printf("[DEBUG] authValid: token exists=%v, expires_in=%.2f, valid=%v",
-			true,
-			expiresIn,
-			valid,
-		)
-	}
-
-	return valid
-}
-
-// Unified authentication function to refresh OAuth2 tokens
-func (c *Client) authenticate() error {
+// authenticate makes sure the client holds a token that is not about to expire.
+func (c *Client) authenticate(ctx context.Context) error {
 	if c.oauth2Credentials.UseLegacyClient {
 		return nil // skip authentication for legacy client
 	}
-	c.Lock()
-	defer c.Unlock()
-
-	// Check if the AuthToken is nil, empty, or expired
-	if !c.authValid() {
-		// Pass the context from the Configuration along with the other arguments
-		authToken, err := Authenticate(c.oauth2Credentials.Context, c.oauth2Credentials, c.oauth2Credentials.Logger)
-		if err != nil {
-			return err
-		}
-		c.oauth2Credentials.Zscaler.Client.AuthToken = authToken
-		return nil
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	return nil
+	_, err := c.token(ctx)
+	return err
 }
 
 func containsInt(codes []int, code int) bool {
@@ -0,0 +1,314 @@
+package zscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	vcr "gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
+)
+
+// RecorderMode selects how the HTTP record/replay transport behaves.
+type RecorderMode string
+
+const (
+	// RecorderModeDisabled sends every request to the network without recording.
+	RecorderModeDisabled RecorderMode = ""
+	// RecorderModeRecord always sends requests to the network and records them, replacing the cassette.
+	RecorderModeRecord RecorderMode = "record"
+	// RecorderModeRecordOnce records when the cassette does not exist yet and replays it otherwise.
+	RecorderModeRecordOnce RecorderMode = "record_once"
+	// RecorderModeReplay only replays recorded interactions; unmatched requests fail without touching the network.
+	RecorderModeReplay RecorderMode = "replay"
+	// RecorderModeReplayWithNewEpisodes replays recorded interactions and records the ones that are missing.
+	RecorderModeReplayWithNewEpisodes RecorderMode = "replay_with_new_episodes"
+	// RecorderModePassthrough sends every request to the network and never writes the cassette.
+	RecorderModePassthrough RecorderMode = "passthrough"
+
+	defaultCassetteName = "zscaler"
+	redactedValue       = "REDACTED"
+)
+
+// sensitiveHeaders are removed from recorded requests and responses.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// sensitiveParams are form, query and JSON keys whose values are replaced before a cassette is saved.
+var sensitiveParams = []string{
+	"client_secret",
+	"client_assertion",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"api_token",
+	"apiKey",
+	"password",
+}
+
+func (m RecorderMode) vcrMode() (vcr.Mode, error) {
+	switch m {
+	case RecorderModeRecord:
+		return vcr.ModeRecordOnly, nil
+	case RecorderModeRecordOnce:
+		return vcr.ModeRecordOnce, nil
+	case RecorderModeReplay:
+		return vcr.ModeReplayOnly, nil
+	case RecorderModeReplayWithNewEpisodes:
+		return vcr.ModeReplayWithNewEpisodes, nil
+	case RecorderModePassthrough:
+		return vcr.ModePassthrough, nil
+	}
+	return 0, fmt.Errorf("unsupported recorder mode %q", m)
+}
+
+// cassettePath returns the cassette location (without the .yaml extension go-vcr adds).
+func (c *Configuration) cassettePath() string {
+	rec := c.Zscaler.Testing.Recorder
+	name := rec.Cassette
+	if name == "" {
+		name = defaultCassetteName
+	}
+	return filepath.Join(rec.CassetteDir, strings.TrimSuffix(name, ".yaml"))
+}
+
+// initRecorder creates the shared record/replay transport when a recorder mode is configured.
+// The recorder is reused as long as the mode and cassette do not change.
+func (c *Configuration) initRecorder() error {
+	mode := RecorderMode(strings.ToLower(c.Zscaler.Testing.Recorder.Mode))
+	if mode == RecorderModeDisabled {
+		c.recorder = nil
+		c.recorderErr = nil
+		return nil
+	}
+	path := c.cassettePath()
+	if c.recorder != nil && c.recorderMode == mode && c.recorderCassette == path {
+		return nil
+	}
+	c.recorderMode = mode
+	c.recorderCassette = path
+	rec, err := newRecorder(mode, path, newBaseTransport(c.Logger, c))
+	if err != nil {
+		// Fail closed: requests must not silently reach the network when a recorder was asked for.
+		c.recorder = nil
+		c.recorderErr = fmt.Errorf("recorder unavailable for cassette %s: %w", path, err)
+		return c.recorderErr
+	}
+	c.recorder = rec
+	c.recorderErr = nil
+	return nil
+}
+
+// recorderTransport returns the record/replay transport wrapping base, if one is configured.
+func (c *Configuration) recorderTransport(base http.RoundTripper) http.RoundTripper {
+	if c.recorderErr != nil {
+		return failingTransport{err: c.recorderErr}
+	}
+	if c.recorder != nil {
+		return c.recorder
+	}
+	return base
+}
+
+// failingTransport rejects every request with a fixed error.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// newRecorder builds a go-vcr recorder that scrubs credentials before saving and
+// matches requests on method, path, normalized query and body.
+func newRecorder(mode RecorderMode, path string, realTransport http.RoundTripper) (*vcr.Recorder, error) {
+	vcrMode, err := mode.vcrMode()
+	if err != nil {
+		return nil, err
+	}
+	return vcr.New(path,
+		vcr.WithMode(vcrMode),
+		vcr.WithRealTransport(realTransport),
+		vcr.WithSkipRequestLatency(true),
+		vcr.WithMatcher(matchRecordedRequest),
+		vcr.WithHook(scrubInteraction, vcr.BeforeSaveHook),
+	)
+}
+
+// isReplaying reports whether requests are served from the cassette without network access.
+func (c *Configuration) isReplaying() bool {
+	return (c.recorder != nil || c.recorderErr != nil) && c.recorderMode == RecorderModeReplay
+}
+
+// StopRecorder flushes recorded interactions to the cassette. It is a no-op when recording is disabled.
+func (c *Configuration) StopRecorder() error {
+	if c.recorder == nil {
+		return nil
+	}
+	return c.recorder.Stop()
+}
+
+// scrubInteraction removes credentials and tokens from an interaction before it is written to disk.
+func scrubInteraction(i *cassette.Interaction) error {
+	for _, h := range sensitiveHeaders {
+		if i.Request.Headers.Get(h) != "" {
+			i.Request.Headers.Set(h, redactedValue)
+		}
+		if i.Response.Headers.Get(h) != "" {
+			i.Response.Headers.Set(h, redactedValue)
+		}
+	}
+
+	if u, err := url.Parse(i.Request.URL); err == nil {
+		q := u.Query()
+		if redactValues(q) {
+			u.RawQuery = q.Encode()
+			i.Request.URL = u.String()
+		}
+	}
+	redactValues(i.Request.Form)
+	i.Request.Body = scrubBody(i.Request.Body)
+	i.Response.Body = scrubBody(i.Response.Body)
+	return nil
+}
+
+// redactValues replaces sensitive entries in place and reports whether anything changed.
+func redactValues(v url.Values) bool {
+	changed := false
+	for _, key := range sensitiveParams {
+		if _, ok := v[key]; ok {
+			v.Set(key, redactedValue)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// scrubBody redacts sensitive keys in a JSON or form-encoded body.
+func scrubBody(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return body
+	}
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+			return body
+		}
+		if !redactJSON(doc) {
+			return body
+		}
+		out, err := json.Marshal(doc)
+		if err != nil {
+			return body
+		}
+		return string(out)
+	}
+	if strings.Contains(trimmed, "=") && !strings.ContainsAny(trimmed, " \n") {
+		form, err := url.ParseQuery(trimmed)
+		if err == nil && redactValues(form) {
+			return form.Encode()
+		}
+	}
+	return body
+}
+
+// redactJSON walks a decoded JSON document and replaces sensitive string values.
+func redactJSON(doc interface{}) bool {
+	changed := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if isSensitiveParam(k) {
+				if _, ok := val.(string); ok {
+					v[k] = redactedValue
+					changed = true
+					continue
+				}
+			}
+			if redactJSON(val) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if redactJSON(val) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func isSensitiveParam(key string) bool {
+	for _, p := range sensitiveParams {
+		if strings.EqualFold(p, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRecordedRequest matches a live request against a recorded one on method,
+// path, normalized query and body. Host and credentials are ignored so that
+// cassettes recorded against one tenant replay against another.
+func matchRecordedRequest(r *http.Request, i cassette.Request) bool {
+	if r.Method != i.Method {
+		return false
+	}
+	recorded, err := url.Parse(i.URL)
+	if err != nil {
+		return false
+	}
+	if r.URL.Path != recorded.Path {
+		return false
+	}
+	if !reflect.DeepEqual(normalizeQuery(r.URL.Query()), normalizeQuery(recorded.Query())) {
+		return false
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return false
+		}
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+	}
+	return bodiesMatch(string(body), i.Body)
+}
+
+// normalizeQuery drops sensitive parameters so that redacted recordings still match.
+func normalizeQuery(v url.Values) url.Values {
+	out := url.Values{}
+	for k, vals := range v {
+		if isSensitiveParam(k) {
+			continue
+		}
+		out[k] = vals
+	}
+	return out
+}
+
+func bodiesMatch(live, recorded string) bool {
+	live, recorded = strings.TrimSpace(live), strings.TrimSpace(recorded)
+	if live == recorded {
+		return true
+	}
+	var a, b interface{}
+	if json.Unmarshal([]byte(live), &a) == nil && json.Unmarshal([]byte(recorded), &b) == nil {
+		redactJSON(a)
+		redactJSON(b)
+		return reflect.DeepEqual(a, b)
+	}
+	fa, errA := url.ParseQuery(live)
+	fb, errB := url.ParseQuery(recorded)
+	if errA == nil && errB == nil {
+		return reflect.DeepEqual(normalizeQuery(fa), normalizeQuery(fb))
+	}
+	return false
+}
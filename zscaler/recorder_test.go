@@ -0,0 +1,146 @@
+package zscaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends every request to the test server, keeping path and query.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newRecorderTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/v1/token":
+			_ = r.ParseForm()
+			if r.Form.Get("client_secret") != "super-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token_type":"Bearer","access_token":"live-access-token","expires_in":3600}`))
+		case "/zia/api/v1/ruleLabels/1":
+			if r.Header.Get("Authorization") != "Bearer live-access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":1,"name":"label-one"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	server := newRecorderTestServer(t)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	// Record against the test server.
+	cfg, err := NewConfiguration(
+		WithClientID("client-id"),
+		WithClientSecret("super-secret"),
+		WithVanityDomain("acme"),
+		WithRecorder(RecorderModeRecord, dir),
+		WithRecorderCassette("rule_labels"),
+	)
+	require.NoError(t, err)
+	cfg.recorder, err = newRecorder(RecorderModeRecord, cfg.cassettePath(), &redirectTransport{target: target})
+	require.NoError(t, err)
+	setHttpClients(cfg)
+
+	svc, err := NewOneAPIClient(cfg)
+	require.NoError(t, err)
+	var label map[string]interface{}
+	require.NoError(t, svc.Client.Read(context.Background(), "/zia/api/v1/ruleLabels/1", &label))
+	assert.Equal(t, "label-one", label["name"])
+	svc.Client.Close()
+	server.Close()
+
+	raw, err := os.ReadFile(filepath.Join(dir, "rule_labels.yaml"))
+	require.NoError(t, err)
+	cassetteContent := string(raw)
+	assert.NotContains(t, cassetteContent, "super-secret")
+	assert.NotContains(t, cassetteContent, "live-access-token")
+	assert.Contains(t, cassetteContent, redactedValue)
+
+	// Replay without network access and with different credentials.
+	replayCfg, err := NewConfiguration(
+		WithClientID("client-id"),
+		WithClientSecret("another-secret"),
+		WithVanityDomain("other-tenant"),
+		WithRecorder(RecorderModeReplay, dir),
+		WithRecorderCassette("rule_labels"),
+	)
+	require.NoError(t, err)
+
+	replaySvc, err := NewOneAPIClient(replayCfg)
+	require.NoError(t, err)
+	defer replaySvc.Client.Close()
+
+	var replayed map[string]interface{}
+	require.NoError(t, replaySvc.Client.Read(context.Background(), "/zia/api/v1/ruleLabels/1", &replayed))
+	assert.Equal(t, label, replayed)
+}
+
+func TestRecorderReplayUnmatchedRequest(t *testing.T) {
+	cfg, err := NewConfiguration(
+		WithRecorder(RecorderModeReplay, t.TempDir()),
+		WithRateLimitMaxRetries(1),
+	)
+	require.NoError(t, err)
+	cfg.Zscaler.Client.AuthToken = &AuthToken{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}
+
+	svc, err := NewOneAPIClient(cfg)
+	require.NoError(t, err)
+	defer svc.Client.Close()
+
+	var out map[string]interface{}
+	err = svc.Client.Read(context.Background(), "/zia/api/v1/ruleLabels/1", &out)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "recorder unavailable"), err.Error())
+}
+
+func TestScrubBody(t *testing.T) {
+	form := scrubBody("grant_type=client_credentials&client_id=abc&client_secret=s3cr3t")
+	values, err := url.ParseQuery(form)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", values.Get("client_id"))
+	assert.Equal(t, redactedValue, values.Get("client_secret"))
+
+	body := scrubBody(`{"access_token":"tok","nested":{"password":"p"},"name":"keep"}`)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &doc))
+	assert.Equal(t, redactedValue, doc["access_token"])
+	assert.Equal(t, redactedValue, doc["nested"].(map[string]interface{})["password"])
+	assert.Equal(t, "keep", doc["name"])
+}
+
+func TestBodiesMatch(t *testing.T) {
+	assert.True(t, bodiesMatch(`{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1}`))
+	assert.False(t, bodiesMatch(`{"a":1}`, `{"a":2}`))
+	assert.True(t, bodiesMatch("client_id=x&client_secret=live", "client_secret=REDACTED&client_id=x"))
+	assert.False(t, bodiesMatch("client_id=x", "client_id=y"))
+}
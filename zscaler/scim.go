@@ -0,0 +1,85 @@
+package zscaler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// scimTarget marks a client sending SCIM requests, which authenticate with a
+// static bearer token instead of OAuth2 and address absolute URLs.
+type scimTarget struct {
+	product   string
+	token     string
+	userAgent string
+}
+
+// newScimClient builds a client sending SCIM requests for product with token.
+// The setters tune its transport the same way they tune a OneAPI client.
+func newScimClient(product, token, userAgent string, setters ...ConfigSetter) (*Client, error) {
+	cfg, err := NewConfiguration(setters...)
+	if err != nil {
+		return nil, err
+	}
+	cli := &Client{
+		oauth2Credentials: cfg,
+		scim:              &scimTarget{product: product, token: token, userAgent: userAgent},
+	}
+	if cfg.Zscaler.Client.DryRun {
+		cli.plan = NewPlan(cfg.RedactionPolicy)
+	}
+	return cli, nil
+}
+
+// buildScimRequest builds a SCIM request for the absolute URL endpoint.
+func (c *Client) buildScimRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) (*http.Request, error) {
+	fullURL := endpoint
+	if params := urlParams.Encode(); params != "" {
+		sep := "?"
+		if strings.Contains(fullURL, "?") {
+			sep = "&"
+		}
+		fullURL += sep + params
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.scim.token))
+	userAgent := c.scim.userAgent
+	if userAgent == "" {
+		userAgent = c.oauth2Credentials.UserAgent
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return req, nil
+}
+
+// productOf returns the product an endpoint belongs to, which selects its rate
+// limiter and labels its telemetry.
+func (c *Client) productOf(endpoint string) string {
+	if c.scim != nil {
+		return c.scim.product
+	}
+	product, _ := detectServiceType(endpoint)
+	return product
+}
+
+// isMutating reports whether a request changes state. SCIM searches are sent
+// as POSTs to a .search endpoint but only read, as are the lookups in
+// readOnlyPosts.
+func (c *Client) isMutating(method, endpoint string) bool {
+	path, _, _ := strings.Cut(endpoint, "?")
+	if c.scim != nil && strings.HasSuffix(path, "/.search") {
+		return false
+	}
+	if strings.EqualFold(method, http.MethodPost) && isReadOnlyPost(path) {
+		return false
+	}
+	return isMutatingMethod(method)
+}
@@ -0,0 +1,114 @@
+// Package scim builds SCIM 2.0 PATCH requests (RFC 7644 section 3.5.2) for the
+// ZIA and ZPA SCIM user and group APIs.
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PatchOpSchema is the schema URI of a PATCH request message.
+const PatchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+
+// Patch operation types.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Operation is one operation of a PATCH request. Path is an attribute path,
+// optionally with a value filter such as members[value eq "123"].
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchRequest is a SCIM PATCH request message. Build one with NewPatch and
+// chain the operations:
+//
+//	patch := scim.NewPatch().
+//		Replace("active", false).
+//		AddMembers("1234").
+//		Remove(`emails[type eq "work"]`)
+type PatchRequest struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// Member references a user or group in a group's members attribute.
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// NewPatch returns an empty PATCH request.
+func NewPatch() *PatchRequest {
+	return &PatchRequest{Schemas: []string{PatchOpSchema}}
+}
+
+// Add adds value to the attribute at path. Without a path, value is a map of
+// attributes to add to the resource.
+func (p *PatchRequest) Add(path string, value interface{}) *PatchRequest {
+	p.Operations = append(p.Operations, Operation{Op: OpAdd, Path: path, Value: value})
+	return p
+}
+
+// Replace replaces the attribute at path with value. Without a path, value is
+// a map of attributes to replace.
+func (p *PatchRequest) Replace(path string, value interface{}) *PatchRequest {
+	p.Operations = append(p.Operations, Operation{Op: OpReplace, Path: path, Value: value})
+	return p
+}
+
+// Remove removes the attribute, or the values matching the filter, at path.
+func (p *PatchRequest) Remove(path string) *PatchRequest {
+	p.Operations = append(p.Operations, Operation{Op: OpRemove, Path: path})
+	return p
+}
+
+// AddMembers adds the users or groups with the given IDs to a group.
+func (p *PatchRequest) AddMembers(ids ...string) *PatchRequest {
+	if len(ids) == 0 {
+		return p
+	}
+	members := make([]Member, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, Member{Value: id})
+	}
+	return p.Add("members", members)
+}
+
+// RemoveMembers removes the users or groups with the given IDs from a group.
+func (p *PatchRequest) RemoveMembers(ids ...string) *PatchRequest {
+	for _, id := range ids {
+		p.Remove(fmt.Sprintf("members[value eq %q]", id))
+	}
+	return p
+}
+
+// Validate checks the request against RFC 7644: it holds at least one
+// operation, every operation has a known type, add and replace carry a value
+// and remove names a path.
+func (p *PatchRequest) Validate() error {
+	if p == nil || len(p.Operations) == 0 {
+		return errors.New("scim: a PATCH request needs at least one operation")
+	}
+	for i, op := range p.Operations {
+		switch strings.ToLower(op.Op) {
+		case OpAdd, OpReplace:
+			if op.Value == nil {
+				return fmt.Errorf("scim: operation %d (%s %s) has no value", i, op.Op, op.Path)
+			}
+		case OpRemove:
+			if op.Path == "" {
+				return fmt.Errorf("scim: operation %d (remove) has no path", i)
+			}
+		default:
+			return fmt.Errorf("scim: operation %d has unknown type %q", i, op.Op)
+		}
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package zscaler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/logger"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zcc"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia"
@@ -187,24 +190,46 @@ type ScimZPAService struct {
 	Client *zpa.ScimZpaClient
 }
 
+// NewZPAScimService initializes a ZPA SCIM service whose requests share the
+// OneAPI transport. If that transport cannot be set up, the error is logged
+// and requests are sent directly; use NewZPAScimServiceWithSetters to tune the
+// transport and get the error instead.
 func NewZPAScimService(cfg *zpa.ScimConfiguration) *ScimZPAService {
 	if cfg == nil {
 		return nil
 	}
+	service, err := NewZPAScimServiceWithSetters(cfg)
+	if err != nil {
+		cfg.Logger.Printf("[ERROR] Failed to set up the SCIM transport, sending requests directly: %v", err)
+		return &ScimZPAService{Config: cfg, Client: &zpa.ScimZpaClient{ScimConfig: zpaScimConfig(cfg)}}
+	}
+	return service
+}
+
+// NewZPAScimServiceWithSetters initializes a ZPA SCIM service. Its requests
+// share the OneAPI transport: rate limiting, retries, caching, logging and
+// structured errors, tuned with the same setters as a OneAPI client.
+func NewZPAScimServiceWithSetters(cfg *zpa.ScimConfiguration, setters ...ConfigSetter) (*ScimZPAService, error) {
+	if cfg == nil {
+		return nil, errors.New("a ZPA SCIM configuration is required")
+	}
+	client := &zpa.ScimZpaClient{ScimConfig: zpaScimConfig(cfg)}
+	transport, err := newScimClient("zpa", client.ScimConfig.AuthToken, cfg.UserAgent, scimSetters(cfg.Debug, cfg.Logger, setters)...)
+	if err != nil {
+		return nil, fmt.Errorf("setting up the ZPA SCIM transport: %w", err)
+	}
+	client.Transport = transport
+	return &ScimZPAService{Config: cfg, Client: client}, nil
+}
 
-	return &ScimZPAService{
-		Config: cfg,
-		Client: &zpa.ScimZpaClient{
-			ScimConfig: &zpa.ZPAScimConfig{
-				BaseURL:     cfg.BaseURL,
-				HTTPClient:  cfg.HTTPClient,
-				AuthToken:   cfg.ZPAScim.Client.ZPAScimToken,
-				IDPId:       cfg.ZPAScim.Client.ZPAIdPID,
-				Logger:      cfg.Logger,
-				UserAgent:   cfg.UserAgent,
-				RateLimiter: nil,
-			},
-		},
+func zpaScimConfig(cfg *zpa.ScimConfiguration) *zpa.ZPAScimConfig {
+	return &zpa.ZPAScimConfig{
+		BaseURL:    cfg.BaseURL,
+		HTTPClient: cfg.HTTPClient,
+		AuthToken:  cfg.ZPAScim.Client.ZPAScimToken,
+		IDPId:      cfg.ZPAScim.Client.ZPAIdPID,
+		Logger:     cfg.Logger,
+		UserAgent:  cfg.UserAgent,
 	}
 }
 
@@ -213,23 +238,68 @@ type ScimZIAService struct {
 	Client *zia.ScimZiaClient
 }
 
+// NewZIAScimService initializes a ZIA SCIM service sharing the OneAPI
+// transport, like NewZPAScimService.
 func NewZIAScimService(cfg *zia.ScimConfiguration) *ScimZIAService {
 	if cfg == nil {
 		return nil
 	}
+	service, err := NewZIAScimServiceWithSetters(cfg)
+	if err != nil {
+		cfg.Logger.Printf("[ERROR] Failed to set up the SCIM transport, sending requests directly: %v", err)
+		return &ScimZIAService{Config: cfg, Client: &zia.ScimZiaClient{ScimConfig: ziaScimConfig(cfg)}}
+	}
+	return service
+}
+
+// NewZIAScimServiceWithSetters initializes a ZIA SCIM service sharing the
+// OneAPI transport, like NewZPAScimServiceWithSetters.
+func NewZIAScimServiceWithSetters(cfg *zia.ScimConfiguration, setters ...ConfigSetter) (*ScimZIAService, error) {
+	if cfg == nil {
+		return nil, errors.New("a ZIA SCIM configuration is required")
+	}
+	client := &zia.ScimZiaClient{ScimConfig: ziaScimConfig(cfg)}
+	transport, err := newScimClient("zia", client.ScimConfig.AuthToken, cfg.UserAgent, scimSetters(cfg.Debug, cfg.Logger, setters)...)
+	if err != nil {
+		return nil, fmt.Errorf("setting up the ZIA SCIM transport: %w", err)
+	}
+	client.Transport = transport
+	return &ScimZIAService{Config: cfg, Client: client}, nil
+}
+
+func ziaScimConfig(cfg *zia.ScimConfiguration) *zia.ZIAScimConfig {
+	return &zia.ZIAScimConfig{
+		BaseURL:    cfg.BaseURL,
+		HTTPClient: cfg.HTTPClient,
+		AuthToken:  cfg.ZIAScim.Client.ZIAScimApiToken,
+		TenantID:   cfg.ZIAScim.Client.ZIAScimTenantID,
+		Logger:     cfg.Logger,
+		UserAgent:  cfg.UserAgent,
+	}
+}
+
+// Plan returns the plan of a SCIM service configured with WithDryRun, or nil.
+func (service *ScimZPAService) Plan() *Plan {
+	if t, ok := service.Client.Transport.(*Client); ok {
+		return t.Plan()
+	}
+	return nil
+}
+
+// Plan returns the plan of a SCIM service configured with WithDryRun, or nil.
+func (service *ScimZIAService) Plan() *Plan {
+	if t, ok := service.Client.Transport.(*Client); ok {
+		return t.Plan()
+	}
+	return nil
+}
 
-	return &ScimZIAService{
-		Config: cfg,
-		Client: &zia.ScimZiaClient{
-			ScimConfig: &zia.ZIAScimConfig{
-				BaseURL:     cfg.BaseURL,
-				HTTPClient:  cfg.HTTPClient,
-				AuthToken:   cfg.ZIAScim.Client.ZIAScimApiToken,
-				TenantID:    cfg.ZIAScim.Client.ZIAScimTenantID,
-				Logger:      cfg.Logger,
-				UserAgent:   cfg.UserAgent,
-				RateLimiter: nil,
-			},
-		},
+// scimSetters carries the debug flag and logger of a SCIM configuration over
+// to its transport, ahead of the caller's setters.
+func scimSetters(debug bool, l logger.Logger, setters []ConfigSetter) []ConfigSetter {
+	base := []ConfigSetter{WithDebug(debug)}
+	if l != nil {
+		base = append(base, WithLogger(l))
 	}
+	return append(base, setters...)
 }
@@ -0,0 +1,350 @@
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the SDK to tracer and meter providers.
+const instrumentationName = "github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+
+// Attribute keys recorded on SDK spans and metrics.
+const (
+	attrProduct     = attribute.Key("zscaler.product")
+	attrMethod      = attribute.Key("http.request.method")
+	attrEndpoint    = attribute.Key("url.template")
+	attrStatus      = attribute.Key("http.response.status_code")
+	attrErrorType   = attribute.Key("error.type")
+	attrAttempt     = attribute.Key("zscaler.attempt")
+	attrRetryReason = attribute.Key("zscaler.retry.reason")
+	attrLimiterWait = attribute.Key("zscaler.rate_limiter.wait")
+	attrTotalWait   = attribute.Key("zscaler.wait.total")
+	attrCacheHit    = attribute.Key("zscaler.cache.hit")
+	attrOutcome     = attribute.Key("zscaler.outcome")
+)
+
+// Values of the zscaler.retry.reason attribute.
+const (
+	retryReasonRateLimited    = "rate_limited"
+	retryReasonSessionInvalid = "session_invalid"
+	retryReasonEditLock       = "edit_lock"
+	retryReasonServerError    = "server_error"
+	retryReasonTransport      = "transport_error"
+)
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used for SDK spans.
+// The global provider is used when none is set.
+func WithTracerProvider(tp trace.TracerProvider) ConfigSetter {
+	return func(c *Configuration) {
+		c.TracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the OpenTelemetry meter provider used for SDK metrics.
+// The global provider is used when none is set.
+func WithMeterProvider(mp metric.MeterProvider) ConfigSetter {
+	return func(c *Configuration) {
+		c.MeterProvider = mp
+	}
+}
+
+// telemetry holds the tracer and instruments created from the configured providers.
+type telemetry struct {
+	tracer        trace.Tracer
+	requests      metric.Int64Counter
+	duration      metric.Float64Histogram
+	attempts      metric.Int64Counter
+	retries       metric.Int64Counter
+	limiterWait   metric.Float64Histogram
+	cacheHits     metric.Int64Counter
+	cacheMisses   metric.Int64Counter
+	deduplicated  metric.Int64Counter
+	tokenRenewals metric.Int64Counter
+}
+
+func newTelemetry(cfg *Configuration) *telemetry {
+	tp := cfg.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	mp := cfg.MeterProvider
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName, metric.WithInstrumentationVersion(VERSION))
+
+	var errs []error
+	counter := func(name, desc, unit string) metric.Int64Counter {
+		c, err := meter.Int64Counter(name, metric.WithDescription(desc), metric.WithUnit(unit))
+		if err != nil {
+			errs = append(errs, err)
+			return noop.Int64Counter{}
+		}
+		return c
+	}
+	histogram := func(name, desc string) metric.Float64Histogram {
+		h, err := meter.Float64Histogram(name, metric.WithDescription(desc), metric.WithUnit("s"))
+		if err != nil {
+			errs = append(errs, err)
+			return noop.Float64Histogram{}
+		}
+		return h
+	}
+
+	t := &telemetry{
+		tracer:        tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(VERSION)),
+		requests:      counter("zscaler.sdk.requests", "Number of SDK requests.", "{request}"),
+		duration:      histogram("zscaler.sdk.request.duration", "Duration of SDK requests, including retries and waits."),
+		attempts:      counter("zscaler.sdk.attempts", "Number of HTTP attempts sent.", "{attempt}"),
+		retries:       counter("zscaler.sdk.retries", "Number of HTTP attempts that retried a failed attempt.", "{retry}"),
+		limiterWait:   histogram("zscaler.sdk.rate_limiter.wait", "Time attempts waited on the client-side rate limiter."),
+		cacheHits:     counter("zscaler.sdk.cache.hits", "Number of GET requests served from the response cache.", "{request}"),
+		cacheMisses:   counter("zscaler.sdk.cache.misses", "Number of cacheable GET requests not found in the response cache.", "{request}"),
+		deduplicated:  counter("zscaler.sdk.inflight.deduplicated", "Number of GET requests that waited on an identical in-flight request.", "{request}"),
+		tokenRenewals: counter("zscaler.sdk.token.renewals", "Number of OAuth2 token fetches.", "{renewal}"),
+	}
+	if err := errors.Join(errs...); err != nil && cfg.Logger != nil {
+		cfg.Logger.Printf("[ERROR] Failed to create telemetry instruments: %v", err)
+	}
+	return t
+}
+
+// callTrace tracks a single ExecuteRequest call across its HTTP attempts.
+type callTrace struct {
+	tel        *telemetry
+	span       trace.Span
+	start      time.Time
+	attrs      []attribute.KeyValue
+	attempts   int
+	lastReason string
+	totalWait  time.Duration
+}
+
+type callTraceKey struct{}
+
+// startCall starts the span for a logical SDK call and stores it in the returned context.
+func (t *telemetry) startCall(ctx context.Context, product, method, endpoint string) (context.Context, *callTrace) {
+	if t == nil {
+		return ctx, nil
+	}
+	template := endpointTemplate(endpoint)
+	ct := &callTrace{
+		tel:   t,
+		start: time.Now(),
+		attrs: []attribute.KeyValue{attrProduct.String(product), attrMethod.String(method), attrEndpoint.String(template)},
+	}
+	ctx, ct.span = t.tracer.Start(ctx, strings.TrimSpace(product+" "+method+" "+template),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(ct.attrs...),
+	)
+	return context.WithValue(ctx, callTraceKey{}, ct), ct
+}
+
+func callTraceFromContext(ctx context.Context) *callTrace {
+	ct, _ := ctx.Value(callTraceKey{}).(*callTrace)
+	return ct
+}
+
+// cacheHit records a GET served from the response cache.
+func (ct *callTrace) cacheHit(ctx context.Context) {
+	if ct == nil {
+		return
+	}
+	ct.span.SetAttributes(attrCacheHit.Bool(true))
+	ct.tel.cacheHits.Add(ctx, 1, metric.WithAttributes(ct.attrs...))
+}
+
+// deduplicated records a GET that waited on an identical in-flight request.
+func (ct *callTrace) deduplicated(ctx context.Context) {
+	if ct == nil {
+		return
+	}
+	ct.span.AddEvent("waited for in-flight request")
+	ct.tel.deduplicated.Add(ctx, 1, metric.WithAttributes(ct.attrs...))
+}
+
+// cacheMiss records a cacheable GET that has to go to the API.
+func (ct *callTrace) cacheMiss(ctx context.Context) {
+	if ct == nil {
+		return
+	}
+	ct.span.SetAttributes(attrCacheHit.Bool(false))
+	ct.tel.cacheMisses.Add(ctx, 1, metric.WithAttributes(ct.attrs...))
+}
+
+// end finishes the call span and records the request metrics.
+func (ct *callTrace) end(ctx context.Context, resp *http.Response, err error) {
+	if ct == nil {
+		return
+	}
+	attrs := append([]attribute.KeyValue{}, ct.attrs...)
+	if resp != nil {
+		attrs = append(attrs, attrStatus.Int(resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, attrErrorType.String(errorType(resp, err)))
+		ct.span.RecordError(err)
+		ct.span.SetStatus(codes.Error, err.Error())
+	}
+	ct.span.SetAttributes(attrs[len(ct.attrs):]...)
+	ct.span.SetAttributes(attrAttempt.Int(ct.attempts), attrTotalWait.Float64(ct.totalWait.Seconds()))
+	ct.span.End()
+
+	ct.tel.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+	ct.tel.duration.Record(ctx, time.Since(ct.start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// limiterWaitKey carries the accumulated client-side rate limiter wait of an attempt.
+type limiterWaitKey struct{}
+
+// recordLimiterWait adds d to the limiter wait of the attempt that owns ctx.
+func recordLimiterWait(ctx context.Context, d time.Duration) {
+	if w, ok := ctx.Value(limiterWaitKey{}).(*atomic.Int64); ok {
+		w.Add(int64(d))
+	}
+}
+
+// telemetryTransport starts a span for every HTTP attempt, including the ones
+// retried inside the retryable HTTP client.
+type telemetryTransport struct {
+	base http.RoundTripper
+	cfg  *Configuration
+}
+
+func (t *telemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tel := t.cfg.telemetry
+	if tel == nil {
+		return t.base.RoundTrip(req)
+	}
+	ctx := req.Context()
+
+	ct := callTraceFromContext(ctx)
+	attempt, reason := 1, ""
+	var attrs []attribute.KeyValue
+	if ct != nil {
+		ct.attempts++
+		attempt, reason = ct.attempts, ct.lastReason
+		attrs = append(attrs, ct.attrs...)
+	} else {
+		attrs = append(attrs, attrMethod.String(req.Method), attrEndpoint.String(endpointTemplate(req.URL.Path)))
+	}
+
+	spanAttrs := append([]attribute.KeyValue{attrAttempt.Int(attempt)}, attrs...)
+	if attempt > 1 && reason != "" {
+		spanAttrs = append(spanAttrs, attrRetryReason.String(reason))
+		tel.retries.Add(ctx, 1, metric.WithAttributes(append(attrs, attrRetryReason.String(reason))...))
+	}
+	ctx, span := tel.tracer.Start(ctx, req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttrs...))
+	defer span.End()
+
+	var waited atomic.Int64
+	ctx = context.WithValue(ctx, limiterWaitKey{}, &waited)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+
+	if wait := time.Duration(waited.Load()); wait > 0 {
+		span.SetAttributes(attrLimiterWait.Float64(wait.Seconds()))
+		tel.limiterWait.Record(ctx, wait.Seconds(), metric.WithAttributes(attrs...))
+	}
+	if resp != nil {
+		attrs = append(attrs, attrStatus.Int(resp.StatusCode))
+		span.SetAttributes(attrStatus.Int(resp.StatusCode))
+		if resp.StatusCode >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, attrErrorType.String(errorType(resp, err)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	tel.attempts.Add(ctx, 1, metric.WithAttributes(attrs...))
+	if ct != nil {
+		ct.lastReason = retryReason(resp, err)
+	}
+	return resp, err
+}
+
+// retryReason classifies a failed attempt by the retry it triggers.
+func retryReason(resp *http.Response, err error) string {
+	switch {
+	case err != nil:
+		return retryReasonTransport
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return retryReasonRateLimited
+	case resp.StatusCode == http.StatusUnauthorized && errorx.IsSessionInvalidError(resp):
+		return retryReasonSessionInvalid
+	case resp.StatusCode == http.StatusConflict && errorx.IsEditLockError(resp):
+		return retryReasonEditLock
+	case resp.StatusCode >= 500:
+		return retryReasonServerError
+	}
+	return ""
+}
+
+// errorType returns the error.type attribute value for a failed call.
+func errorType(resp *http.Response, err error) string {
+	if resp != nil && resp.StatusCode >= 400 {
+		return http.StatusText(resp.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	return "error"
+}
+
+var (
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericSegment = regexp.MustCompile(`^-?[0-9]+$`)
+)
+
+// endpointTemplate strips the query string from endpoint and replaces numeric
+// and UUID path segments with "{id}", keeping span names and metric
+// attributes low-cardinality.
+func endpointTemplate(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	segments := strings.Split(endpoint, "/")
+	for i, s := range segments {
+		if numericSegment.MatchString(s) || uuidSegment.MatchString(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// traceToken runs fetch in a span and counts the token renewal by outcome.
+func (t *telemetry) traceToken(ctx context.Context, fetch func(context.Context) (*AuthToken, error)) (*AuthToken, error) {
+	if t == nil {
+		return fetch(ctx)
+	}
+	ctx, span := t.tracer.Start(ctx, "oauth2 token", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	tok, err := fetch(ctx)
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attrOutcome.String(outcome))
+	t.tokenRenewals.Add(ctx, 1, metric.WithAttributes(attrOutcome.String(outcome)))
+	return tok, err
+}
@@ -0,0 +1,258 @@
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Tenant describes one tenant managed by a TenantPool. Empty fields keep the
+// value of the pool-wide settings.
+type Tenant struct {
+	// Name identifies the tenant within the pool.
+	Name          string
+	VanityDomain  string
+	Cloud         string
+	ClientID      string
+	ClientSecret  string
+	PrivateKey    string
+	CustomerID    string
+	MicrotenantID string
+	// Setters are applied after the pool-wide setters and the fields above.
+	Setters []ConfigSetter
+}
+
+func (t Tenant) configSetters() []ConfigSetter {
+	var setters []ConfigSetter
+	for _, field := range []struct {
+		value  string
+		setter func(string) ConfigSetter
+	}{
+		{t.VanityDomain, WithVanityDomain},
+		{t.Cloud, WithZscalerCloud},
+		{t.ClientID, WithClientID},
+		{t.ClientSecret, WithClientSecret},
+		{t.PrivateKey, WithPrivateKey},
+		{t.CustomerID, WithZPACustomerID},
+		{t.MicrotenantID, WithZPAMicrotenantID},
+	} {
+		if field.value != "" {
+			setters = append(setters, field.setter(field.value))
+		}
+	}
+	return append(setters, t.Setters...)
+}
+
+// TenantPool holds the clients of many tenants. A tenant's client, with its
+// token renewal and rate-limited HTTP clients, is created the first time the
+// tenant is used and reused afterwards.
+type TenantPool struct {
+	mu      sync.Mutex
+	setters []ConfigSetter
+	tenants map[string]*pooledTenant
+	closed  bool
+}
+
+type pooledTenant struct {
+	tenant  Tenant
+	mu      sync.Mutex
+	service *Service
+}
+
+// NewTenantPool returns an empty pool. The setters apply to every tenant,
+// before the tenant's own settings.
+func NewTenantPool(setters ...ConfigSetter) *TenantPool {
+	return &TenantPool{
+		setters: setters,
+		tenants: make(map[string]*pooledTenant),
+	}
+}
+
+// Add registers tenants. Names must be unique within the pool.
+func (p *TenantPool) Add(tenants ...Tenant) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errors.New("tenant pool is closed")
+	}
+	for _, t := range tenants {
+		if t.Name == "" {
+			return errors.New("tenant name is required")
+		}
+		if _, ok := p.tenants[t.Name]; ok {
+			return fmt.Errorf("tenant %q is already registered", t.Name)
+		}
+	}
+	for _, t := range tenants {
+		p.tenants[t.Name] = &pooledTenant{tenant: t}
+	}
+	return nil
+}
+
+// Remove unregisters a tenant and closes its client, if one was created.
+func (p *TenantPool) Remove(name string) bool {
+	p.mu.Lock()
+	pt, ok := p.tenants[name]
+	delete(p.tenants, name)
+	p.mu.Unlock()
+	if ok {
+		pt.close()
+	}
+	return ok
+}
+
+// Tenants returns the names of the registered tenants, sorted.
+func (p *TenantPool) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.tenants))
+	for name := range p.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Service returns the service of the named tenant, authenticating it on first
+// use. A failed creation is not remembered and is attempted again next time.
+func (p *TenantPool) Service(name string) (*Service, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errors.New("tenant pool is closed")
+	}
+	pt, ok := p.tenants[name]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("tenant %q is not registered", name)
+	}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.service != nil {
+		return pt.service, nil
+	}
+	cfg, err := NewConfiguration(append(append([]ConfigSetter{}, p.setters...), pt.tenant.configSetters()...)...)
+	if err != nil {
+		return nil, err
+	}
+	service, err := NewOneAPIClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pt.service = service
+	return service, nil
+}
+
+// Close closes the clients of every tenant. The pool cannot be used afterwards.
+func (p *TenantPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	tenants := p.tenants
+	p.tenants = make(map[string]*pooledTenant)
+	p.mu.Unlock()
+	for _, pt := range tenants {
+		pt.close()
+	}
+}
+
+func (pt *pooledTenant) close() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.service != nil && pt.service.Client != nil {
+		pt.service.Client.Close()
+	}
+	pt.service = nil
+}
+
+// TenantResult is the outcome of a function run against one tenant.
+type TenantResult[T any] struct {
+	Tenant string
+	Value  T
+	Err    error
+}
+
+// TenantResults holds one result per tenant, sorted by tenant name.
+type TenantResults[T any] []TenantResult[T]
+
+// Err joins the errors of the failed tenants into one error, or returns nil if
+// every tenant succeeded. Each joined error is a *TenantError.
+func (r TenantResults[T]) Err() error {
+	var errs []error
+	for _, res := range r {
+		if res.Err != nil {
+			errs = append(errs, &TenantError{Tenant: res.Tenant, Err: res.Err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failed returns the names of the tenants whose run returned an error.
+func (r TenantResults[T]) Failed() []string {
+	var names []string
+	for _, res := range r {
+		if res.Err != nil {
+			names = append(names, res.Tenant)
+		}
+	}
+	return names
+}
+
+// TenantError is the error of a single tenant.
+type TenantError struct {
+	Tenant string
+	Err    error
+}
+
+func (e *TenantError) Error() string { return fmt.Sprintf("tenant %q: %v", e.Tenant, e.Err) }
+func (e *TenantError) Unwrap() error { return e.Err }
+
+// RunTenants calls fn for every tenant of the pool, or only the named ones, with
+// at most concurrency calls running at once. A tenant whose client cannot be
+// created, or that is still waiting when ctx ends, gets the error in its result
+// without fn being called.
+func RunTenants[T any](ctx context.Context, pool *TenantPool, concurrency int, fn func(ctx context.Context, tenant string, service *Service) (T, error), tenants ...string) TenantResults[T] {
+	if len(tenants) == 0 {
+		tenants = pool.Tenants()
+	} else {
+		tenants = append([]string(nil), tenants...)
+		sort.Strings(tenants)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(TenantResults[T], len(tenants))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range tenants {
+		results[i].Tenant = name
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(res *TenantResult[T]) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := ctx.Err(); err != nil {
+				res.Err = err
+				return
+			}
+			service, err := pool.Service(res.Tenant)
+			if err != nil {
+				res.Err = err
+				return
+			}
+			res.Value, res.Err = fn(ctx, res.Tenant, service)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
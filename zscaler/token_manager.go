@@ -0,0 +1,216 @@
+package zscaler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+)
+
+const (
+	defaultTokenRefreshSkew       = time.Minute
+	defaultTokenRefreshMaxRetries = 3
+	defaultTokenRefreshBackoff    = 500 * time.Millisecond
+	maxTokenRefreshBackoff        = 30 * time.Second
+)
+
+// TokenEventType identifies a change in the lifecycle of the OAuth2 token.
+type TokenEventType string
+
+const (
+	// TokenEventRefreshed is emitted when a new token was obtained.
+	TokenEventRefreshed TokenEventType = "refreshed"
+	// TokenEventRefreshFailed is emitted for every failed attempt to obtain a token.
+	TokenEventRefreshFailed TokenEventType = "refresh_failed"
+	// TokenEventInvalidated is emitted when the API rejects the current token
+	// with SESSION_NOT_VALID before it expired.
+	TokenEventInvalidated TokenEventType = "invalidated"
+)
+
+// TokenRefreshReason tells why a token was requested.
+type TokenRefreshReason string
+
+const (
+	TokenRefreshInitial        TokenRefreshReason = "initial"
+	TokenRefreshExpiring       TokenRefreshReason = "expiring"
+	TokenRefreshSessionInvalid TokenRefreshReason = "session_invalid"
+)
+
+// TokenEvent describes a token lifecycle change. Expiry is set for
+// TokenEventRefreshed, Attempt and Err for TokenEventRefreshFailed.
+type TokenEvent struct {
+	Type    TokenEventType
+	Reason  TokenRefreshReason
+	Expiry  time.Time
+	Attempt int
+	Err     error
+}
+
+// WithTokenEventHandler calls handler for every token lifecycle event. The
+// handler runs synchronously on the goroutine refreshing the token and must not
+// block.
+func WithTokenEventHandler(handler func(TokenEvent)) ConfigSetter {
+	return func(c *Configuration) {
+		c.tokenEvents = handler
+	}
+}
+
+// WithTokenRefreshSkew refreshes the token once it is within skew of expiring
+// (1 minute by default).
+func WithTokenRefreshSkew(skew time.Duration) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.TokenRefresh.Skew = skew
+	}
+}
+
+// WithTokenRefreshMaxRetries sets how often a failed token request is retried
+// before the error is returned (3 by default). Rejected credentials are never retried.
+func WithTokenRefreshMaxRetries(maxRetries int32) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.TokenRefresh.MaxRetries = maxRetries
+	}
+}
+
+// WithTokenRefreshBackoff sets the initial wait between token request retries.
+// It doubles after each attempt, up to 30 seconds, with full jitter.
+func WithTokenRefreshBackoff(backoff time.Duration) ConfigSetter {
+	return func(c *Configuration) {
+		c.Zscaler.Client.TokenRefresh.Backoff = backoff
+	}
+}
+
+// tokenRefresh is a token request shared by every caller that needs a new token
+// while it is in flight.
+type tokenRefresh struct {
+	done  chan struct{}
+	token *AuthToken
+	err   error
+}
+
+// token returns the current token, refreshing it first when it is missing or
+// about to expire.
+func (c *Client) token(ctx context.Context) (*AuthToken, error) {
+	c.Lock()
+	tok := c.oauth2Credentials.Zscaler.Client.AuthToken
+	valid := c.tokenValid(tok)
+	c.Unlock()
+	if valid {
+		return tok, nil
+	}
+	reason, stale := TokenRefreshExpiring, ""
+	if tok == nil || tok.AccessToken == "" {
+		reason = TokenRefreshInitial
+	} else {
+		stale = tok.AccessToken
+	}
+	return c.refreshToken(ctx, reason, stale)
+}
+
+// refreshToken obtains a new token to replace stale. Concurrent callers share a
+// single request, and a token that already replaced stale is returned as is.
+// The client mutex is never held while the token endpoint is called.
+func (c *Client) refreshToken(ctx context.Context, reason TokenRefreshReason, stale string) (*AuthToken, error) {
+	c.Lock()
+	if current := c.oauth2Credentials.Zscaler.Client.AuthToken; current != nil && current.AccessToken != stale && c.tokenValid(current) {
+		c.Unlock()
+		return current, nil
+	}
+	r := c.refresh
+	if r == nil {
+		r = &tokenRefresh{done: make(chan struct{})}
+		c.refresh = r
+		// The request outlives the caller that started it so the callers
+		// waiting on it are not failed by someone else's cancellation.
+		go c.runRefresh(context.WithoutCancel(ctx), r, reason, stale)
+	}
+	c.Unlock()
+
+	select {
+	case <-r.done:
+		return r.token, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) runRefresh(ctx context.Context, r *tokenRefresh, reason TokenRefreshReason, stale string) {
+	// A token the API rejected must not come back from a token cache.
+	if reason == TokenRefreshSessionInvalid && stale != "" {
+		if inv, ok := c.oauth2Credentials.tokenSource().(tokenInvalidator); ok {
+			if err := inv.invalidate(stale); err != nil {
+				c.oauth2Credentials.Logger.Printf("[WARN] Failed to drop the rejected token from the token cache: %v", err)
+			}
+		}
+	}
+	tok, err := c.fetchTokenWithBackoff(ctx, reason)
+	c.Lock()
+	if err == nil {
+		c.oauth2Credentials.Zscaler.Client.AuthToken = tok
+	}
+	c.refresh = nil
+	r.token, r.err = tok, err
+	c.Unlock()
+
+	if err == nil {
+		c.oauth2Credentials.Logger.Printf("[INFO] OAuth2 token obtained (%s), expires at %s", reason, tok.Expiry.Format(time.RFC3339))
+		c.emitTokenEvent(TokenEvent{Type: TokenEventRefreshed, Reason: reason, Expiry: tok.Expiry})
+	}
+	close(r.done)
+}
+
+// fetchTokenWithBackoff requests a token, retrying failures other than missing
+// or rejected credentials with jittered exponential backoff.
+func (c *Client) fetchTokenWithBackoff(ctx context.Context, reason TokenRefreshReason) (*AuthToken, error) {
+	settings := c.oauth2Credentials.Zscaler.Client.TokenRefresh
+	for attempt := 1; ; attempt++ {
+		tok, err := c.fetchToken(ctx)
+		if err == nil && tok.Expiry.IsZero() && tok.ExpiresIn != "" {
+			err = tok.setExpiry()
+		}
+		if err == nil {
+			return tok, nil
+		}
+		c.emitTokenEvent(TokenEvent{Type: TokenEventRefreshFailed, Reason: reason, Attempt: attempt, Err: err})
+		if errors.Is(err, errorx.ErrUnauthorized) || errors.Is(err, errorx.ErrValidation) || attempt > int(settings.MaxRetries) {
+			c.oauth2Credentials.Logger.Printf("[ERROR] Failed to obtain OAuth2 token after %d attempt(s): %v", attempt, err)
+			return nil, err
+		}
+		wait := tokenRefreshBackoff(settings.Backoff, attempt)
+		c.oauth2Credentials.Logger.Printf("[WARN] Failed to obtain OAuth2 token (attempt %d), retrying in %v: %v", attempt, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// tokenRefreshBackoff returns a random wait of up to base*2^(attempt-1), capped
+// at maxTokenRefreshBackoff.
+func tokenRefreshBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = defaultTokenRefreshBackoff
+	}
+	limit := base << (attempt - 1)
+	if limit <= 0 || limit > maxTokenRefreshBackoff {
+		limit = maxTokenRefreshBackoff
+	}
+	return time.Duration(rand.Int63n(int64(limit)) + 1)
+}
+
+// tokenValid reports whether tok can still be used for longer than the refresh
+// skew. The caller must hold the client mutex.
+func (c *Client) tokenValid(tok *AuthToken) bool {
+	if tok == nil || tok.AccessToken == "" || tok.Expiry.IsZero() {
+		return false
+	}
+	skew := c.oauth2Credentials.Zscaler.Client.TokenRefresh.Skew
+	if skew < 0 {
+		skew = 0
+	}
+	return time.Until(tok.Expiry) > skew
+}
+
+func (c *Client) emitTokenEvent(event TokenEvent) {
+	if handler := c.oauth2Credentials.tokenEvents; handler != nil {
+		handler(event)
+	}
+}
@@ -0,0 +1,332 @@
+package zscaler
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/filelock"
+)
+
+// TokenSource supplies OAuth2 access tokens to the OneAPI client.
+// Implementations must be safe for concurrent use by multiple goroutines.
+type TokenSource interface {
+	Token(ctx context.Context) (*AuthToken, error)
+}
+
+// TokenSourceFunc adapts an ordinary function to the TokenSource interface.
+type TokenSourceFunc func(ctx context.Context) (*AuthToken, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (*AuthToken, error) {
+	return f(ctx)
+}
+
+// clientSecretTokenSource performs the OAuth2 client-credentials exchange using a client secret.
+type clientSecretTokenSource struct {
+	cfg *Configuration
+}
+
+// NewClientSecretTokenSource returns a TokenSource that exchanges the configured
+// client ID and client secret for an access token.
+func NewClientSecretTokenSource(cfg *Configuration) TokenSource {
+	return &clientSecretTokenSource{cfg: cfg}
+}
+
+func (s *clientSecretTokenSource) Token(ctx context.Context) (*AuthToken, error) {
+	return authenticateWithSecret(ctx, s.cfg, s.cfg.Logger)
+}
+
+// jwtTokenSource performs the OAuth2 client-credentials exchange using a signed JWT client assertion.
+type jwtTokenSource struct {
+	cfg *Configuration
+}
+
+// NewJWTTokenSource returns a TokenSource that authenticates with a JWT client
+// assertion signed by the configured private key.
+func NewJWTTokenSource(cfg *Configuration) TokenSource {
+	return &jwtTokenSource{cfg: cfg}
+}
+
+func (s *jwtTokenSource) Token(ctx context.Context) (*AuthToken, error) {
+	return authenticateWithCert(s.cfg)
+}
+
+// DefaultTokenSource returns the built-in TokenSource for the configuration:
+// the JWT flow when a private key or signer is configured, the client secret flow otherwise.
+func DefaultTokenSource(cfg *Configuration) TokenSource {
+	if cfg.usesClientAssertion() {
+		return NewJWTTokenSource(cfg)
+	}
+	return NewClientSecretTokenSource(cfg)
+}
+
+// tokenSource returns the TokenSource set on the configuration, or the default one.
+func (c *Configuration) tokenSource() TokenSource {
+	if c.TokenSource != nil {
+		return c.TokenSource
+	}
+	return DefaultTokenSource(c)
+}
+
+// resolveTokenSource wraps the effective TokenSource with the persistent token
+// cache when it is enabled in the configuration.
+func (c *Configuration) resolveTokenSource() error {
+	if !c.Zscaler.Client.TokenCache.Enabled {
+		return nil
+	}
+	if _, ok := c.TokenSource.(*fileTokenSource); ok {
+		return nil
+	}
+	path := c.Zscaler.Client.TokenCache.Path
+	if path == "" {
+		var err error
+		path, err = defaultTokenCachePath(c)
+		if err != nil {
+			return err
+		}
+	}
+	key, err := tokenCacheKey(c)
+	if err != nil {
+		return err
+	}
+	ts, err := NewFileTokenSource(c.tokenSource(), path, key)
+	if err != nil {
+		return err
+	}
+	c.TokenSource = ts
+	return nil
+}
+
+// fetchToken retrieves a new token from the configured TokenSource.
+func (c *Client) fetchToken(ctx context.Context) (*AuthToken, error) {
+	return c.oauth2Credentials.telemetry.traceToken(ctx, c.oauth2Credentials.tokenSource().Token)
+}
+
+const (
+	// defaultTokenCacheMinTTL is the minimum remaining lifetime a cached token must have to be reused.
+	defaultTokenCacheMinTTL = 2 * time.Minute
+	tokenCacheVersion       = 1
+)
+
+// FileTokenSourceOption configures a file-backed TokenSource.
+type FileTokenSourceOption func(*fileTokenSource)
+
+// WithTokenCacheMinTTL sets the minimum remaining lifetime a cached token must
+// have to be handed out instead of fetching a new one.
+func WithTokenCacheMinTTL(d time.Duration) FileTokenSourceOption {
+	return func(s *fileTokenSource) {
+		s.minTTL = d
+	}
+}
+
+// fileTokenSource shares a still-valid token across processes through an
+// encrypted file guarded by an advisory lock.
+type fileTokenSource struct {
+	base   TokenSource
+	path   string
+	aead   cipher.AEAD
+	minTTL time.Duration
+}
+
+// cachedToken is the plaintext layout of a cached token before encryption.
+type cachedToken struct {
+	TokenType   string    `json:"token_type"`
+	AccessToken string    `json:"access_token"`
+	ExpiresIn   string    `json:"expires_in"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// tokenCacheFile is the on-disk layout of the token cache.
+type tokenCacheFile struct {
+	Version int    `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// NewFileTokenSource wraps base with a persistent token cache stored at path.
+// The token is encrypted at rest with AES-256-GCM using a key derived from key,
+// so every process sharing the cache must use the same key. Concurrent
+// processes serialize on a lock file next to path, so at most one of them
+// contacts the token endpoint when the cached token is missing or expired.
+func NewFileTokenSource(base TokenSource, path string, key []byte, opts ...FileTokenSourceOption) (TokenSource, error) {
+	if base == nil {
+		return nil, errors.New("base token source is required")
+	}
+	if path == "" {
+		return nil, errors.New("token cache path is required")
+	}
+	if len(key) == 0 {
+		return nil, errors.New("token cache encryption key is required")
+	}
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &fileTokenSource{
+		base:   base,
+		path:   path,
+		aead:   aead,
+		minTTL: defaultTokenCacheMinTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *fileTokenSource) Token(ctx context.Context) (*AuthToken, error) {
+	lock, err := filelock.Acquire(s.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock token cache: %w", err)
+	}
+	defer lock.Release()
+
+	if tok, err := s.load(); err == nil && tok != nil && time.Until(tok.Expiry) > s.minTTL {
+		return tok, nil
+	}
+
+	tok, err := s.base.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens without a known expiry cannot be safely shared.
+	if tok != nil && !tok.Expiry.IsZero() {
+		_ = s.store(tok)
+	}
+	return tok, nil
+}
+
+// tokenInvalidator is implemented by token sources that keep tokens, so that a
+// token rejected by the API is not handed out again.
+type tokenInvalidator interface {
+	// invalidate drops the kept token if its access token is stale.
+	invalidate(stale string) error
+}
+
+func (s *fileTokenSource) invalidate(stale string) error {
+	lock, err := filelock.Acquire(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock token cache: %w", err)
+	}
+	defer lock.Release()
+
+	// Another process may already have replaced the rejected token.
+	if tok, err := s.load(); err != nil || tok == nil || tok.AccessToken != stale {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// load reads and decrypts the cached token. A missing file yields a nil token.
+func (s *fileTokenSource) load() (*AuthToken, error) {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var f tokenCacheFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != tokenCacheVersion {
+		return nil, fmt.Errorf("unsupported token cache version %d", f.Version)
+	}
+	plain, err := s.aead.Open(nil, f.Nonce, f.Data, []byte(s.path))
+	if err != nil {
+		return nil, err
+	}
+	var ct cachedToken
+	if err := json.Unmarshal(plain, &ct); err != nil {
+		return nil, err
+	}
+	return &AuthToken{
+		TokenType:   ct.TokenType,
+		AccessToken: ct.AccessToken,
+		ExpiresIn:   json.Number(ct.ExpiresIn),
+		Expiry:      ct.Expiry,
+	}, nil
+}
+
+// store encrypts the token and atomically replaces the cache file.
+func (s *fileTokenSource) store(tok *AuthToken) error {
+	plain, err := json.Marshal(cachedToken{
+		TokenType:   tok.TokenType,
+		AccessToken: tok.AccessToken,
+		ExpiresIn:   tok.ExpiresIn.String(),
+		Expiry:      tok.Expiry,
+	})
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	out, err := json.Marshal(tokenCacheFile{
+		Version: tokenCacheVersion,
+		Nonce:   nonce,
+		Data:    s.aead.Seal(nil, nonce, plain, []byte(s.path)),
+	})
+	if err != nil {
+		return err
+	}
+	return filelock.WriteFileAtomic(s.path, out, 0o600)
+}
+
+// defaultTokenCachePath returns ~/.zscaler/cache/token-<hash>.json, where the hash
+// identifies the tenant, cloud and client ID the token was issued for.
+func defaultTokenCachePath(cfg *Configuration) (string, error) {
+	currUser, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	if currUser.HomeDir == "" {
+		return "", errors.New("unable to determine home directory for token cache")
+	}
+	return filepath.Join(currUser.HomeDir, ".zscaler", "cache", "token-"+credentialsID(cfg)+".json"), nil
+}
+
+// credentialsID identifies the tenant, cloud and client ID of the credentials.
+func credentialsID(cfg *Configuration) string {
+	creds := cfg.Zscaler.Client
+	id := sha256.Sum256([]byte(strings.Join([]string{creds.VanityDomain, strings.ToLower(creds.Cloud), creds.ClientID}, "|")))
+	return hex.EncodeToString(id[:8])
+}
+
+// tokenCacheKey returns the key set with WithTokenCacheKey, or derives one from
+// the client credentials so only processes holding the same credentials can
+// read the cached token. A custom TokenSource, or a signer whose private key is
+// never loaded, leaves no secret to derive it from, so the key must be set.
+func tokenCacheKey(cfg *Configuration) ([]byte, error) {
+	if len(cfg.TokenCacheKey) > 0 {
+		return cfg.TokenCacheKey, nil
+	}
+	creds := cfg.Zscaler.Client
+	if cfg.TokenSource != nil || (creds.ClientSecret == "" && len(creds.PrivateKey) == 0) {
+		return nil, errors.New("a cache key set with WithTokenCacheKey is required when no client secret or private key is configured, e.g. with a custom token source")
+	}
+	return []byte(strings.Join([]string{"zscaler-token-cache", creds.VanityDomain, creds.ClientID, creds.ClientSecret, string(creds.PrivateKey)}, "|")), nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/url"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
 )
 
@@ -108,11 +110,35 @@ func queryParamsToURLValues(params interface{}) (url.Values, error) {
 // }
 
 func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams interface{}, pageSize int) ([]T, error) {
-	pagination := NewPagination(pageSize)
 	var allResults []T
+	err := readPages(ctx, client, endpoint, queryParams, pageSize, func(pageResults []T) bool {
+		allResults = append(allResults, pageResults...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allResults, nil
+}
+
+// IterAllPages is the lazy form of ReadAllPages.
+func IterAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams interface{}, pageSize int) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPages(ctx, client, endpoint, queryParams, pageSize, yield)
+	})
+}
+
+// readPages fetches pages in order and passes each to yield until the last
+// page or until yield returns false.
+func readPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams interface{}, pageSize int, yield func([]T) bool) error {
+	pagination := NewPagination(pageSize)
 	page := 1
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		var pageResults []T
 
 		q := url.Values{}
@@ -120,7 +146,7 @@ func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint s
 			// Safely convert struct -> url.Values
 			queryString, err := queryParamsToURLValues(queryParams)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse query params: %w", err)
+				return fmt.Errorf("failed to parse query params: %w", err)
 			}
 			q = queryString
 		}
@@ -135,16 +161,12 @@ func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint s
 
 		_, err := client.NewZccRequestDo(ctx, "GET", fullURL, nil, nil, &pageResults)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		allResults = append(allResults, pageResults...)
-
-		if len(pageResults) < pagination.PageSize {
-			break
+		if !yield(pageResults) || len(pageResults) < pagination.PageSize {
+			return nil
 		}
 		page++
 	}
-
-	return allResults, nil
 }
@@ -0,0 +1,113 @@
+package downloaddevices
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/url"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+)
+
+// Device is a row of the device inventory report. Times the report leaves
+// empty or marks as N/A are zero. Columns without a field are kept in Extra.
+type Device struct {
+	User               string            `csv:"User|User Name|Username"`
+	DeviceType         string            `csv:"Device Type|OS Type|Platform"`
+	DeviceModel        string            `csv:"Device Model|Model"`
+	Manufacturer       string            `csv:"Manufacturer"`
+	Hostname           string            `csv:"Hostname|Machine Hostname|Host Name"`
+	UDID               string            `csv:"UDID|Device ID"`
+	MacAddress         string            `csv:"Mac Address"`
+	CompanyName        string            `csv:"Company Name|Company"`
+	OSVersion          string            `csv:"OS Version"`
+	AgentVersion       string            `csv:"Zscaler Client Connector Version|Client Connector Version|App Version|Agent Version"`
+	PolicyName         string            `csv:"Policy Name|App Profile|Policy"`
+	DeviceState        string            `csv:"Device State|State"`
+	RegistrationState  string            `csv:"Registration State|Registration Status"`
+	DeviceTrustLevel   string            `csv:"Device Trust Level|Trust Level"`
+	RegistrationTime   time.Time         `csv:"Registration Time|Registered On"`
+	DeregistrationTime time.Time         `csv:"Last Deregistration Time|Deregistration Time"`
+	ConfigDownloadTime time.Time         `csv:"Config Download Time|Last Config Download"`
+	KeepAliveTime      time.Time         `csv:"Keep Alive Time|Last Keep Alive"`
+	LastSeen           time.Time         `csv:"Last Seen Connected to ZIA|Last Seen|Last Connected"`
+	Extra              map[string]string `csv:"*"`
+}
+
+// ServiceStatus is a row of the service status report, the state of each
+// Zscaler service on a device. Columns without a field are kept in Extra.
+type ServiceStatus struct {
+	User          string            `csv:"User|User Name|Username"`
+	DeviceType    string            `csv:"Device Type|OS Type|Platform"`
+	Hostname      string            `csv:"Hostname|Machine Hostname|Host Name"`
+	UDID          string            `csv:"UDID|Device ID"`
+	AgentVersion  string            `csv:"Zscaler Client Connector Version|Client Connector Version|App Version|Agent Version"`
+	DeviceState   string            `csv:"Device State|State"`
+	ZIAStatus     string            `csv:"ZIA Status|Internet Security Status|ZIA"`
+	ZPAStatus     string            `csv:"ZPA Status|Private Access Status|ZPA"`
+	ZDXStatus     string            `csv:"ZDX Status|Digital Experience Status|ZDX"`
+	TunnelVersion string            `csv:"Tunnel Version|ZIA Tunnel Version"`
+	LastSeen      time.Time         `csv:"Last Seen Connected to ZIA|Last Seen|Last Connected"`
+	Extra         map[string]string `csv:"*"`
+}
+
+// NewDeviceDecoder returns a decoder reading the rows of a device inventory
+// report, as written by DownloadDevices, from r.
+func NewDeviceDecoder(r io.Reader, opts ...csvx.Option) *csvx.Decoder[Device] {
+	return csvx.NewDecoder[Device](r, opts...)
+}
+
+// NewServiceStatusDecoder returns a decoder reading the rows of a service
+// status report, as written by DownloadServiceStatus, from r.
+func NewServiceStatusDecoder(r io.Reader, opts ...csvx.Option) *csvx.Decoder[ServiceStatus] {
+	return csvx.NewDecoder[ServiceStatus](r, opts...)
+}
+
+// IterDevices streams the device inventory report and iterates over its rows.
+// osTypes and registrationTypes filter the report as in DownloadDevices.
+func IterDevices(ctx context.Context, service *zscaler.Service, osTypes, registrationTypes string, opts ...csvx.Option) iter.Seq2[Device, error] {
+	return iterReport[Device](ctx, service, downloadDevicesEndpoint, "devices", osTypes, registrationTypes, opts)
+}
+
+// IterServiceStatus streams the service status report and iterates over its
+// rows.
+func IterServiceStatus(ctx context.Context, service *zscaler.Service, osTypes, registrationTypes string, opts ...csvx.Option) iter.Seq2[ServiceStatus, error] {
+	return iterReport[ServiceStatus](ctx, service, downloadServiceStatusEndpoint, "service status", osTypes, registrationTypes, opts)
+}
+
+func iterReport[T any](ctx context.Context, service *zscaler.Service, endpoint, report, osTypes, registrationTypes string, opts []csvx.Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		body, err := stream(ctx, service, endpoint, report, osTypes, registrationTypes)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer body.Close()
+		for record, err := range csvx.NewDecoder[T](body, opts...).All() {
+			if !yield(record, err) {
+				return
+			}
+		}
+	}
+}
+
+// stream requests a report and returns its body unread, so it is decoded as
+// it arrives.
+func stream(ctx context.Context, service *zscaler.Service, endpoint, report, osTypes, registrationTypes string) (io.ReadCloser, error) {
+	params := url.Values{}
+	if osTypes != "" {
+		params.Set("osTypes", osTypes)
+	}
+	if registrationTypes != "" {
+		params.Set("registrationTypes", registrationTypes)
+	}
+	resp, err := service.Client.ExecuteStreamRequest(ctx, "GET", endpoint, nil, params, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", report, err)
+	}
+	return resp.Body, nil
+}
@@ -20,22 +20,21 @@ type DownloadDevicesQueryParams struct {
 }
 
 func DownloadDevices(ctx context.Context, service *zscaler.Service, osTypes, registrationTypes string, writer io.Writer) error {
-	queryParams := DownloadDevicesQueryParams{
-		OSTypes:           osTypes,
-		RegistrationTypes: registrationTypes,
-	}
+	return download(ctx, service, downloadDevicesEndpoint, "devices", osTypes, registrationTypes, writer)
+}
 
-	resp, err := service.Client.NewZccRequestDo(ctx, "GET", downloadDevicesEndpoint, queryParams, nil, nil)
+func DownloadServiceStatus(ctx context.Context, service *zscaler.Service, osTypes, registrationTypes string, writer io.Writer) error {
+	return download(ctx, service, downloadServiceStatusEndpoint, "service status", osTypes, registrationTypes, writer)
+}
+
+func download(ctx context.Context, service *zscaler.Service, endpoint, report, osTypes, registrationTypes string, writer io.Writer) error {
+	body, err := open(ctx, service, endpoint, report, osTypes, registrationTypes)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download devices: %s", resp.Status)
-	}
+	defer body.Close()
 
-	_, err = io.Copy(writer, resp.Body)
+	_, err = io.Copy(writer, body)
 	if err != nil {
 		return fmt.Errorf("failed to write response to writer: %v", err)
 	}
@@ -43,26 +42,23 @@ func DownloadDevices(ctx context.Context, service *zscaler.Service, osTypes, reg
 	return nil
 }
 
-func DownloadServiceStatus(ctx context.Context, service *zscaler.Service, osTypes, registrationTypes string, writer io.Writer) error {
+// open requests a report. Reports are never served from the cache, since they
+// reflect the current device state.
+func open(ctx context.Context, service *zscaler.Service, endpoint, report, osTypes, registrationTypes string) (io.ReadCloser, error) {
 	queryParams := DownloadDevicesQueryParams{
 		OSTypes:           osTypes,
 		RegistrationTypes: registrationTypes,
 	}
 
-	resp, err := service.Client.NewZccRequestDo(ctx, "GET", downloadServiceStatusEndpoint, queryParams, nil, nil)
+	resp, err := service.Client.NewZccRequestDo(zscaler.WithoutCache(ctx), "GET", endpoint, queryParams, nil, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download devices: %s", resp.Status)
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", report, resp.Status)
 	}
 
-	_, err = io.Copy(writer, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write response to writer: %v", err)
-	}
-
-	return nil
+	return resp.Body, nil
 }
@@ -243,7 +243,7 @@ func Authenticate(ctx context.Context, cfg *Configuration, logger logger.Logger)
 		// Handle non-2xx responses
 		if resp.StatusCode >= 300 {
 			logger.Printf("[ERROR] Authentication failed with status %d. Response: %s", resp.StatusCode, respBody)
-			return nil, fmt.Errorf("authentication failed: HTTP %d, response: %s", resp.StatusCode, respBody)
+			return nil, errorx.Errorf(errorx.AuthFailureKind(resp), "authentication failed: HTTP %d, response: %s", resp.StatusCode, respBody)
 		}
 
 		// Parse the authentication response
@@ -3,6 +3,7 @@ package alerts
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
@@ -126,3 +127,42 @@ func GetAffectedDevices(ctx context.Context, service *zscaler.Service, alertID s
 	}
 	return &response, resp, nil
 }
+
+// IterOngoingAlerts is the lazy form of GetOngoingAlerts that follows
+// next_offset through every page, starting at filters.Offset.
+func IterOngoingAlerts(ctx context.Context, service *zscaler.Service, filters common.GetFromToFilters) iter.Seq2[Alert, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]Alert, string, error) {
+		filters.Offset = offset
+		response, _, err := GetOngoingAlerts(ctx, service, filters)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Alerts, response.NextOffset, nil
+	})
+}
+
+// IterHistoricalAlerts is the lazy form of GetHistoricalAlerts that follows
+// next_offset through every page, starting at filters.Offset.
+func IterHistoricalAlerts(ctx context.Context, service *zscaler.Service, filters common.GetFromToFilters) iter.Seq2[Alert, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]Alert, string, error) {
+		filters.Offset = offset
+		response, _, err := GetHistoricalAlerts(ctx, service, filters)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Alerts, response.NextOffset, nil
+	})
+}
+
+// IterAffectedDevices is the lazy form of GetAffectedDevices that follows
+// next_offset through every page, starting at filters.Offset.
+func IterAffectedDevices(ctx context.Context, service *zscaler.Service, alertID string, filters common.GetFromToFilters) iter.Seq2[Device, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]Device, string, error) {
+		filters.Offset = offset
+		response, _, err := GetAffectedDevices(ctx, service, alertID, filters)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Devices, response.NextOffset, nil
+	})
+}
@@ -1,6 +1,13 @@
 package common
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
+)
 
 type Metric struct {
 	Metric     string      `json:"metric,omitempty"`
@@ -30,6 +37,41 @@ type GetFromToFilters struct {
 	Q string `json:"q,omitempty" url:"q,omitempty"`
 }
 
+// IterOffsetPages returns an iterator over a list paged with next_offset,
+// starting at offset. readPage fetches one page and returns its items and the
+// next offset, which is empty after the last page.
+func IterOffsetPages[T any](ctx context.Context, offset string, readPage func(ctx context.Context, offset string) ([]T, string, error)) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			items, next, err := readPage(ctx, offset)
+			if err != nil {
+				return err
+			}
+			if !yield(items) || next == "" || next == offset {
+				return nil
+			}
+			offset = next
+		}
+	})
+}
+
+// NextOffset returns a next_offset decoded into an interface{} as a string.
+// A null offset, which ends the list, is empty.
+func NextOffset(v interface{}) string {
+	switch offset := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return offset
+	case float64:
+		return strconv.FormatFloat(offset, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
 // Centralized safe conversion function
 func SafeCastToInt(value int64) (int, error) {
 	minInt := int64(-1 << 31)      // Minimum value of int32
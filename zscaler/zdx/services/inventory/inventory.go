@@ -3,9 +3,11 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx/services/common"
 )
 
 const (
@@ -66,3 +68,23 @@ func GetSoftwareKey(ctx context.Context, service *zscaler.Service, softwareKey s
 	}
 	return response.Software, response.NextOffset, resp, nil
 }
+
+// IterSoftware is the lazy form of GetSoftware that follows next_offset
+// through every page, starting at filters.Offset.
+func IterSoftware(ctx context.Context, service *zscaler.Service, filters GetSoftwareFilters) iter.Seq2[SoftwareOverview, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]SoftwareOverview, string, error) {
+		filters.Offset = offset
+		software, next, _, err := GetSoftware(ctx, service, filters)
+		return software, next, err
+	})
+}
+
+// IterSoftwareKey is the lazy form of GetSoftwareKey that follows
+// next_offset through every page, starting at filters.Offset.
+func IterSoftwareKey(ctx context.Context, service *zscaler.Service, softwareKey string, filters GetSoftwareFilters) iter.Seq2[SoftwareUserList, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]SoftwareUserList, string, error) {
+		filters.Offset = offset
+		software, next, _, err := GetSoftwareKey(ctx, service, softwareKey, filters)
+		return software, next, err
+	})
+}
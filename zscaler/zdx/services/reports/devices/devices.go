@@ -3,9 +3,11 @@ package devices
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx/services/common"
 )
 
 const (
@@ -77,10 +79,7 @@ func GetDevice(ctx context.Context, service *zscaler.Service, deviceID string) (
 
 // Gets the list of all active devices and its basic details. The JSON must contain the user's ID and email address to associate the device to the user. If the time range is not specified, the endpoint defaults to the last 2 hours.
 func GetAllDevices(ctx context.Context, service *zscaler.Service, filters GetDevicesFilters) ([]DeviceDetail, *http.Response, error) {
-	var v struct {
-		NextOffSet interface{}    `json:"next_offset"`
-		List       []DeviceDetail `json:"devices"`
-	}
+	var v devicesPage
 
 	relativeURL := devicesEndpoint
 	resp, err := service.Client.NewRequestDo(ctx, "GET", relativeURL, filters, nil, &v)
@@ -89,3 +88,21 @@ func GetAllDevices(ctx context.Context, service *zscaler.Service, filters GetDev
 	}
 	return v.List, resp, nil
 }
+
+// IterAllDevices is the lazy form of GetAllDevices that follows next_offset
+// through every page, starting at filters.Offset.
+func IterAllDevices(ctx context.Context, service *zscaler.Service, filters GetDevicesFilters) iter.Seq2[DeviceDetail, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]DeviceDetail, string, error) {
+		filters.Offset = offset
+		var v devicesPage
+		if _, err := service.Client.NewRequestDo(ctx, "GET", devicesEndpoint, filters, nil, &v); err != nil {
+			return nil, "", err
+		}
+		return v.List, common.NextOffset(v.NextOffSet), nil
+	})
+}
+
+type devicesPage struct {
+	NextOffSet interface{}    `json:"next_offset"`
+	List       []DeviceDetail `json:"devices"`
+}
@@ -3,9 +3,11 @@ package users
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zdx/services/common"
 )
 
 const (
@@ -54,10 +56,7 @@ func GetUser(ctx context.Context, service *zscaler.Service, userID string) (*Use
 
 // Gets the list of all active users, their devices, active geolocations, and Zscaler locations. If the time range is not specified, the endpoint defaults to the last 2 hours.
 func GetAllUsers(ctx context.Context, service *zscaler.Service, filters GetUsersFilters) ([]User, *http.Response, error) {
-	var v struct {
-		NextOffSet interface{} `json:"next_offset"`
-		List       []User      `json:"users"`
-	}
+	var v usersPage
 
 	relativeURL := usersEndpoint
 	resp, err := service.Client.NewRequestDo(ctx, "GET", relativeURL, filters, nil, &v)
@@ -66,3 +65,21 @@ func GetAllUsers(ctx context.Context, service *zscaler.Service, filters GetUsers
 	}
 	return v.List, resp, nil
 }
+
+// IterAllUsers is the lazy form of GetAllUsers that follows next_offset
+// through every page, starting at filters.Offset.
+func IterAllUsers(ctx context.Context, service *zscaler.Service, filters GetUsersFilters) iter.Seq2[User, error] {
+	return common.IterOffsetPages(ctx, filters.Offset, func(ctx context.Context, offset string) ([]User, string, error) {
+		filters.Offset = offset
+		var v usersPage
+		if _, err := service.Client.NewRequestDo(ctx, "GET", usersEndpoint, filters, nil, &v); err != nil {
+			return nil, "", err
+		}
+		return v.List, common.NextOffset(v.NextOffSet), nil
+	})
+}
+
+type usersPage struct {
+	NextOffSet interface{} `json:"next_offset"`
+	List       []User      `json:"users"`
+}
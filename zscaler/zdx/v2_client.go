@@ -292,7 +292,7 @@ func Authenticate(ctx context.Context, cfg *Configuration, logger logger.Logger)
 		}
 
 		logger.Printf("[ERROR] Rate limit retries exceeded for user %s=%s", ZDX_API_KEY_ID, maskedAPIKeyID)
-		return nil, fmt.Errorf("[ERROR] Rate limit retries exceeded for user %s=%s", ZDX_API_KEY_ID, maskedAPIKeyID)
+		return nil, errorx.Errorf(errorx.ErrRateLimited, "[ERROR] Rate limit retries exceeded for user %s=%s", ZDX_API_KEY_ID, maskedAPIKeyID)
 	}
 
 	return cfg.ZDX.Client.AuthToken, nil
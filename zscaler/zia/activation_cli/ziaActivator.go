@@ -44,15 +44,16 @@ func main() {
 		log.Fatalf("[ERROR] Failed Initializing ZIA client: %v\n", err)
 	}
 
-	// Call the activation API using the instantiated client
-	resp, err := activation.CreateActivation(context.Background(), cli, activation.Activation{
-		Status: "ACTIVE",
-	})
-	if err != nil {
+	// Activate and wait until the tenant settles, accepting a pending EUSA
+	// when ZIA_ACCEPT_EUSA is set
+	backend := &activation.Backend{Service: cli, AcceptEusa: os.Getenv("ZIA_ACCEPT_EUSA") == "true"}
+	manager := zscaler.NewActivationManager(cli, backend)
+	if err := manager.Activate(context.Background()); err != nil {
 		log.Printf("[ERROR] Activation Failed: %v\n", err)
 	} else {
-		log.Printf("[INFO] Activation succeeded: %#v\n", resp)
+		log.Printf("[INFO] Activation succeeded\n")
 	}
+	_ = manager.Close(context.Background())
 
 	os.Exit(0)
 }
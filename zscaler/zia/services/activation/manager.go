@@ -0,0 +1,93 @@
+package activation
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+)
+
+// ZIA activation statuses.
+const (
+	StatusActive     = "ACTIVE"
+	StatusPending    = "PENDING"
+	StatusInProgress = "INPROGRESS"
+)
+
+// ErrEusaNotAccepted is returned by an activation when the latest End User
+// Subscription Agreement was not accepted and Backend.AcceptEusa is unset.
+var ErrEusaNotAccepted = errors.New("zia: the latest EUSA must be accepted before activating")
+
+// Backend is the zscaler.ActivationBackend of ZIA.
+type Backend struct {
+	Service *zscaler.Service
+	// AcceptEusa accepts a pending EUSA before activating instead of failing.
+	AcceptEusa bool
+}
+
+// NewManager returns an activation manager for the ZIA changes made through
+// service.
+func NewManager(service *zscaler.Service, opts ...zscaler.ActivationOption) *zscaler.ActivationManager {
+	return zscaler.NewActivationManager(service, &Backend{Service: service}, opts...)
+}
+
+func (b *Backend) Product() string {
+	return "zia"
+}
+
+// Tracks reports whether endpoint changes the ZIA configuration. Activations
+// and EUSA acceptance do not.
+func (b *Backend) Tracks(endpoint string) bool {
+	path, _, _ := strings.Cut(endpoint, "?")
+	return !strings.HasPrefix(path, activationStatusEndpoint) && !strings.HasPrefix(path, eusaStatusEndpoint)
+}
+
+// Preflight checks the EUSA status. A tenant without an EUSA can activate.
+func (b *Backend) Preflight(ctx context.Context) error {
+	status, err := GetEusaStatus(zscaler.WithoutCache(ctx), b.Service)
+	if errors.Is(err, errorx.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status.AcceptedStatus || status.ID == 0 {
+		return nil
+	}
+	if !b.AcceptEusa {
+		return ErrEusaNotAccepted
+	}
+	status.AcceptedStatus = true
+	_, _, err = UpdateEusaStatus(ctx, b.Service, status.ID, status)
+	return err
+}
+
+func (b *Backend) Status(ctx context.Context) (zscaler.ActivationStatus, error) {
+	activation, err := GetActivationStatus(zscaler.WithoutCache(ctx), b.Service)
+	if err != nil {
+		return zscaler.ActivationStatus{}, err
+	}
+	return activationStatus(activation.Status), nil
+}
+
+func (b *Backend) Activate(ctx context.Context) (zscaler.ActivationStatus, error) {
+	activation, err := CreateActivation(ctx, b.Service, Activation{Status: StatusActive})
+	if err != nil {
+		return zscaler.ActivationStatus{}, err
+	}
+	return activationStatus(activation.Status), nil
+}
+
+// activationStatus maps a ZIA status. PENDING after an activation means other
+// admins still have changes to activate.
+func activationStatus(status string) zscaler.ActivationStatus {
+	switch strings.ToUpper(status) {
+	case StatusInProgress:
+		return zscaler.ActivationStatus{Status: status, InProgress: true}
+	case StatusPending:
+		return zscaler.ActivationStatus{Status: status, OthersPending: true}
+	}
+	return zscaler.ActivationStatus{Status: status}
+}
@@ -3,6 +3,7 @@ package adminauditlogs
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
@@ -78,10 +79,8 @@ func GetAll(ctx context.Context, service *zscaler.Service) (AuditLogEntryReportT
 }
 
 func GetAdminAuditLogsDownload(ctx context.Context, service *zscaler.Service) ([]byte, error) {
-	var csvData []byte
-
-	// Perform a GET request to download the CSV file
-	err := service.Client.Read(ctx, auditLogEntryReportEndpoint+"/download", &csvData)
+	// The report is CSV, so the body is returned as is instead of being JSON decoded
+	csvData, _, _, err := service.Client.ExecuteRequest(zscaler.WithoutCache(ctx), "GET", auditLogEntryReportEndpoint+"/download", nil, nil, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to download audit log report: %w", err)
 	}
@@ -89,6 +88,16 @@ func GetAdminAuditLogsDownload(ctx context.Context, service *zscaler.Service) ([
 	return csvData, nil
 }
 
+// DownloadAdminAuditLogs streams the CSV of the completed audit log report.
+// The caller must close the returned reader.
+func DownloadAdminAuditLogs(ctx context.Context, service *zscaler.Service) (io.ReadCloser, error) {
+	resp, err := service.Client.ExecuteStreamRequest(ctx, "GET", auditLogEntryReportEndpoint+"/download", nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download audit log report: %w", err)
+	}
+	return resp.Body, nil
+}
+
 func CreateAdminAuditLogsExport(ctx context.Context, service *zscaler.Service, exportRequest AuditLogEntryRequest) (*http.Response, error) {
 	// Call CreateWithNoContent and directly assign the *http.Response
 	httpResp, err := service.Client.CreateWithNoContent(ctx, auditLogEntryReportEndpoint, exportRequest)
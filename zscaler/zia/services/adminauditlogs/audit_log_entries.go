@@ -0,0 +1,53 @@
+package adminauditlogs
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+)
+
+// AuditLogEntry is a row of the admin audit log report. Columns without a
+// field are kept in Extra.
+type AuditLogEntry struct {
+	Time            time.Time         `csv:"Time|Timestamp|Date|Date Time"`
+	Action          string            `csv:"Action|Action Type"`
+	Category        string            `csv:"Category"`
+	SubCategory     string            `csv:"Sub-Category"`
+	Resource        string            `csv:"Resource|Object Name|Object"`
+	Admin           string            `csv:"Admin|Admin Login Name|Admin Name|Login Name"`
+	ClientIP        string            `csv:"Client IP|Source IP|IP Address"`
+	ActionInterface string            `csv:"Interface|Action Interface"`
+	ActionResult    string            `csv:"Result|Action Result"`
+	TraceID         string            `csv:"Trace ID|Audit Log ID"`
+	PreAction       string            `csv:"Pre-Action"`
+	PostAction      string            `csv:"Post-Action"`
+	Extra           map[string]string `csv:"*"`
+}
+
+// NewAuditLogDecoder returns a decoder reading the rows of an admin audit
+// log report, as downloaded by GetAdminAuditLogsDownload, from r.
+func NewAuditLogDecoder(r io.Reader, opts ...csvx.Option) *csvx.Decoder[AuditLogEntry] {
+	return csvx.NewDecoder[AuditLogEntry](r, opts...)
+}
+
+// IterAdminAuditLogEntries downloads the report of the completed audit log
+// task and iterates over its rows.
+func IterAdminAuditLogEntries(ctx context.Context, service *zscaler.Service, opts ...csvx.Option) iter.Seq2[AuditLogEntry, error] {
+	return func(yield func(AuditLogEntry, error) bool) {
+		report, err := DownloadAdminAuditLogs(ctx, service)
+		if err != nil {
+			yield(AuditLogEntry{}, err)
+			return
+		}
+		defer report.Close()
+		for entry, err := range NewAuditLogDecoder(report, opts...).All() {
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
@@ -3,10 +3,12 @@ package common
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia"
 )
@@ -142,7 +144,22 @@ func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint s
 	if list == nil {
 		return nil
 	}
+	return readPages(ctx, client, endpoint, func(items []T) bool {
+		*list = append(*list, items...)
+		return true
+	}, customPageSize...)
+}
+
+// IterAllPages is the lazy form of ReadAllPages.
+func IterAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, customPageSize ...int) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPages(ctx, client, endpoint, yield, customPageSize...)
+	})
+}
 
+// readPages fetches pages in order and passes each to yield until the last
+// page or until yield returns false.
+func readPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, yield func([]T) bool, customPageSize ...int) error {
 	pageSize := defaultPageSize
 	if len(customPageSize) > 0 && customPageSize[0] > 0 {
 		pageSize = customPageSize[0]
@@ -154,18 +171,19 @@ func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint s
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		pageItems := []T{}
 		err := client.Read(ctx, fmt.Sprintf("%s&pageSize=%d&page=%d", endpoint, pageSize, page), &pageItems)
 		if err != nil {
 			return err
 		}
-		*list = append(*list, pageItems...)
-		if len(pageItems) < pageSize {
-			break
+		if !yield(pageItems) || len(pageItems) < pageSize {
+			return nil
 		}
 		page++
 	}
-	return nil
 }
 
 func ReadPage[T any](ctx context.Context, client *zscaler.Client, endpoint string, page int, list *[]T, customPageSize ...int) error {
@@ -255,15 +273,50 @@ func GetAllPagesScimPostWithSearch[T any](
 	itemsPerPage int,
 	searchFunc func(T) bool,
 ) ([]T, *http.Response, error) {
+	var all []T
+	resp, err := readScimSearchPages(ctx, client, searchEndpoint, itemsPerPage, func(resources []T) bool {
+		// Filter and short-circuit if searchFunc is used
+		if searchFunc != nil {
+			for _, item := range resources {
+				if searchFunc(item) {
+					all = []T{item}
+					return false
+				}
+			}
+		}
+		all = append(all, resources...)
+		return true
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return all, resp, nil
+}
+
+// IterAllPagesScimPost returns an iterator over every resource matched by a
+// SCIM search endpoint, requested in pages of itemsPerPage resources (at most
+// 100).
+func IterAllPagesScimPost[T any](ctx context.Context, client *zia.ScimZiaClient, searchEndpoint string, itemsPerPage int) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		_, err := readScimSearchPages(ctx, client, searchEndpoint, itemsPerPage, yield)
+		return err
+	})
+}
+
+// readScimSearchPages POSTs SCIM search requests page by page, passing each
+// page to yield until the last page or until yield returns false.
+func readScimSearchPages[T any](ctx context.Context, client *zia.ScimZiaClient, searchEndpoint string, itemsPerPage int, yield func([]T) bool) (*http.Response, error) {
 	if itemsPerPage <= 0 || itemsPerPage > 100 {
 		itemsPerPage = 100
 	}
 
 	startIndex := 1
-	var all []T
 	var lastResp *http.Response
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return lastResp, err
+		}
 		// Construct POST body with SCIM pagination
 		body := map[string]interface{}{
 			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:SearchRequest"},
@@ -278,26 +331,13 @@ func GetAllPagesScimPostWithSearch[T any](
 
 		resp, err := client.DoRequest(ctx, http.MethodPost, searchEndpoint, body, &result)
 		if err != nil {
-			return nil, resp, fmt.Errorf("SCIM POST pagination failed: %w", err)
+			return resp, fmt.Errorf("SCIM POST pagination failed: %w", err)
 		}
 		lastResp = resp
 
-		// Filter and short-circuit if searchFunc is used
-		if searchFunc != nil {
-			for _, item := range result.Resources {
-				if searchFunc(item) {
-					return []T{item}, resp, nil
-				}
-			}
-		}
-
-		all = append(all, result.Resources...)
-
-		if startIndex+itemsPerPage > result.TotalResults || len(result.Resources) == 0 {
-			break
+		if !yield(result.Resources) || startIndex+itemsPerPage > result.TotalResults || len(result.Resources) == 0 {
+			return lastResp, nil
 		}
 		startIndex += itemsPerPage
 	}
-
-	return all, lastResp, nil
 }
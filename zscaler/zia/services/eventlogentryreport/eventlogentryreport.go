@@ -3,6 +3,8 @@ package eventlogentryreport
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
@@ -61,6 +63,36 @@ func GetAll(ctx context.Context, service *zscaler.Service) ([]EventLogEntryRepor
 	return eventLogEntryReport, err
 }
 
+// GetStatus returns the status of the event log report task.
+func GetStatus(ctx context.Context, service *zscaler.Service) (*EventLogEntryReportTaskInfo, error) {
+	var status EventLogEntryReportTaskInfo
+	err := service.Client.Read(ctx, eventlogEntryReportEndpoint, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CreateExport starts an event log report task. The API answers 204 No Content.
+func CreateExport(ctx context.Context, service *zscaler.Service, eventLog *EventLogEntryReport) (*http.Response, error) {
+	httpResp, err := service.Client.CreateWithNoContent(ctx, eventlogEntryReportEndpoint, *eventLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export event log entry report: %w", err)
+	}
+	service.Client.GetLogger().Printf("[DEBUG] Successfully triggered event log entry report export with payload: %+v", *eventLog)
+	return httpResp, nil
+}
+
+// GetDownload streams the CSV of the completed event log report. The caller
+// must close the returned reader.
+func GetDownload(ctx context.Context, service *zscaler.Service) (io.ReadCloser, error) {
+	resp, err := service.Client.ExecuteStreamRequest(ctx, "GET", eventlogEntryReportEndpoint+"/download", nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download event log report: %w", err)
+	}
+	return resp.Body, nil
+}
+
 func Create(ctx context.Context, service *zscaler.Service, eventLog *EventLogEntryReport) (*EventLogEntryReport, error) {
 	resp, err := service.Client.Create(ctx, eventlogEntryReportEndpoint, eventLog)
 	if err != nil {
@@ -0,0 +1,195 @@
+// Package evaluator answers which ZIA firewall filtering rule would match a
+// flow, without sending traffic. It loads the rule set and the objects the rules
+// reference into memory and walks the rules the way the firewall does.
+//
+// Disabled rules are skipped and default rules are evaluated last. Within a
+// rule every criterion must match, except that users, groups and departments
+// match together, as do locations and location groups, and all destination
+// criteria. Rules using criteria that cannot be evaluated offline, such as
+// devices or workload groups, never match.
+//
+//	model, err := evaluator.Load(ctx, service)
+//	result := model.Evaluate(evaluator.Flow{
+//		UserID:   1234,
+//		SrcIP:    netip.MustParseAddr("10.1.2.3"),
+//		DestIP:   netip.MustParseAddr("203.0.113.10"),
+//		Protocol: evaluator.TCP,
+//		DestPort: 443,
+//	})
+//	for _, miss := range result.Misses {
+//		fmt.Println(miss.Rule.Name, miss.Reasons)
+//	}
+package evaluator
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipdestinationgroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipsourcegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservicegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservices"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/timewindow"
+)
+
+// Rule states.
+const (
+	StateEnabled  = "ENABLED"
+	StateDisabled = "DISABLED"
+)
+
+// Model is an in-memory copy of a firewall filtering policy. Rules are kept in
+// evaluation order: by Order, with default rules last.
+type Model struct {
+	Rules         []filteringrules.FirewallFilteringRules
+	SourceGroups  map[int]ipsourcegroups.IPSourceGroups
+	DestGroups    map[int]ipdestinationgroups.IPDestinationGroups
+	Services      map[int]networkservices.NetworkServices
+	ServiceGroups map[int]networkservicegroups.NetworkServiceGroups
+	TimeWindows   map[int]timewindow.TimeWindow
+}
+
+// Load reads the firewall filtering rules and the IP groups, network services,
+// network service groups and time windows they can reference.
+func Load(ctx context.Context, service *zscaler.Service) (*Model, error) {
+	rules, err := filteringrules.GetAll(ctx, service, nil)
+	if err != nil {
+		return nil, err
+	}
+	sourceGroups, err := ipsourcegroups.GetAll(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	destGroups, err := ipdestinationgroups.GetAll(ctx, service, "")
+	if err != nil {
+		return nil, err
+	}
+	services, err := networkservices.GetAllNetworkServices(ctx, service, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	serviceGroups, err := networkservicegroups.GetAllNetworkServiceGroups(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	timeWindows, err := timewindow.GetAll(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	service.Client.GetLogger().Printf("[DEBUG] Loaded %d firewall filtering rules for evaluation", len(rules))
+	return NewModel(rules, sourceGroups, destGroups, services, serviceGroups, timeWindows), nil
+}
+
+// NewModel builds a model from objects already at hand, for example read from
+// a configuration backup.
+func NewModel(
+	rules []filteringrules.FirewallFilteringRules,
+	sourceGroups []ipsourcegroups.IPSourceGroups,
+	destGroups []ipdestinationgroups.IPDestinationGroups,
+	services []networkservices.NetworkServices,
+	serviceGroups []networkservicegroups.NetworkServiceGroups,
+	timeWindows []timewindow.TimeWindow,
+) *Model {
+	m := &Model{
+		Rules:         append([]filteringrules.FirewallFilteringRules(nil), rules...),
+		SourceGroups:  make(map[int]ipsourcegroups.IPSourceGroups, len(sourceGroups)),
+		DestGroups:    make(map[int]ipdestinationgroups.IPDestinationGroups, len(destGroups)),
+		Services:      make(map[int]networkservices.NetworkServices, len(services)),
+		ServiceGroups: make(map[int]networkservicegroups.NetworkServiceGroups, len(serviceGroups)),
+		TimeWindows:   make(map[int]timewindow.TimeWindow, len(timeWindows)),
+	}
+	sort.SliceStable(m.Rules, func(i, j int) bool {
+		a, b := m.Rules[i], m.Rules[j]
+		if a.DefaultRule != b.DefaultRule {
+			return b.DefaultRule
+		}
+		return a.Order < b.Order
+	})
+	for _, g := range sourceGroups {
+		m.SourceGroups[g.ID] = g
+	}
+	for _, g := range destGroups {
+		m.DestGroups[g.ID] = g
+	}
+	for _, s := range services {
+		m.Services[s.ID] = s
+	}
+	for _, g := range serviceGroups {
+		m.ServiceGroups[g.ID] = g
+	}
+	for _, w := range timeWindows {
+		m.TimeWindows[w.ID] = w
+	}
+	return m
+}
+
+// Miss explains why a rule did not match a flow.
+type Miss struct {
+	Rule    *filteringrules.FirewallFilteringRules
+	Reasons []string
+}
+
+// Result is the outcome of evaluating a flow. Rule is nil when no rule
+// matched. Misses lists, in evaluation order, every rule walked before it.
+type Result struct {
+	Rule   *filteringrules.FirewallFilteringRules
+	Misses []Miss
+}
+
+// Action returns the action of the matched rule, or "" when none matched.
+func (r *Result) Action() string {
+	if r.Rule == nil {
+		return ""
+	}
+	return r.Rule.Action
+}
+
+// Evaluate walks the rules in order and returns the first one matching flow.
+// A flow without a time is evaluated at the current time.
+func (m *Model) Evaluate(flow Flow) *Result {
+	if flow.Time.IsZero() {
+		flow.Time = time.Now()
+	}
+	result := &Result{}
+	for i := range m.Rules {
+		rule := &m.Rules[i]
+		reasons := m.Explain(rule, flow)
+		if len(reasons) == 0 {
+			result.Rule = rule
+			return result
+		}
+		result.Misses = append(result.Misses, Miss{Rule: rule, Reasons: reasons})
+	}
+	return result
+}
+
+// Explain returns the reasons rule does not match flow, or nil when it
+// matches. The state of the rule is checked first; every other criterion is
+// reported.
+func (m *Model) Explain(rule *filteringrules.FirewallFilteringRules, flow Flow) []string {
+	if strings.EqualFold(rule.State, StateDisabled) {
+		return []string{"rule is disabled"}
+	}
+	var reasons []string
+	for _, check := range []func(*filteringrules.FirewallFilteringRules, Flow) string{
+		m.matchWho,
+		m.matchLocation,
+		m.matchSource,
+		m.matchSourceCountry,
+		m.matchDestination,
+		m.matchService,
+		m.matchApplication,
+		m.matchDeviceTrust,
+		m.matchTimeWindow,
+		m.matchUnsupported,
+	} {
+		if reason := check(rule, flow); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
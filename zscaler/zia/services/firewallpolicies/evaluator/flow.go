@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Protocols of a flow.
+const (
+	TCP = "TCP"
+	UDP = "UDP"
+)
+
+// Flow is a synthetic connection. IDs refer to ZIA users, groups, departments,
+// locations and location groups. Countries use ISO 3166 alpha-2 codes, with or
+// without the COUNTRY_ prefix of the API. Criteria a rule sets but the flow
+// leaves empty do not match.
+type Flow struct {
+	UserID           int
+	GroupIDs         []int
+	DepartmentID     int
+	LocationID       int
+	LocationGroupIDs []int
+
+	SrcIP      netip.Addr
+	SrcPort    int
+	SrcCountry string
+
+	DestIP           netip.Addr
+	DestHost         string
+	DestPort         int
+	DestCountry      string
+	DestIPCategories []string
+
+	// Protocol is TCP or UDP.
+	Protocol string
+	// Application is the network application, e.g. "SKYPE".
+	Application      string
+	DeviceTrustLevel string
+
+	// Time is compared with the rule time windows in its own location.
+	Time time.Time
+}
@@ -0,0 +1,362 @@
+package evaluator
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservices"
+)
+
+// matchWho matches users, groups and departments. A flow matches when it
+// matches any of them.
+func (m *Model) matchWho(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.Users) == 0 && len(rule.Groups) == 0 && len(rule.Departments) == 0 {
+		return ""
+	}
+	if hasID(rule.Users, flow.UserID) || hasID(rule.Departments, flow.DepartmentID) {
+		return ""
+	}
+	for _, id := range flow.GroupIDs {
+		if hasID(rule.Groups, id) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("user %d, groups %v and department %d are not among the rule users, groups or departments", flow.UserID, flow.GroupIDs, flow.DepartmentID)
+}
+
+// matchLocation matches locations and location groups.
+func (m *Model) matchLocation(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.Locations) == 0 && len(rule.LocationsGroups) == 0 {
+		return ""
+	}
+	if hasID(rule.Locations, flow.LocationID) {
+		return ""
+	}
+	for _, id := range flow.LocationGroupIDs {
+		if hasID(rule.LocationsGroups, id) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("location %d and location groups %v are not among the rule locations or location groups", flow.LocationID, flow.LocationGroupIDs)
+}
+
+// matchSource matches source IP addresses and source IP groups.
+func (m *Model) matchSource(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.SrcIps) == 0 && len(rule.SrcIpGroups) == 0 {
+		return ""
+	}
+	if !flow.SrcIP.IsValid() {
+		return "the flow has no source IP"
+	}
+	if matchAddresses(rule.SrcIps, flow.SrcIP, "") {
+		return ""
+	}
+	var missing []int
+	for _, ref := range rule.SrcIpGroups {
+		group, ok := m.SourceGroups[ref.ID]
+		if !ok {
+			missing = append(missing, ref.ID)
+			continue
+		}
+		if matchAddresses(group.IPAddresses, flow.SrcIP, "") {
+			return ""
+		}
+	}
+	return withMissing(fmt.Sprintf("source IP %s is not in the rule source IPs or source IP groups", flow.SrcIP), "source IP groups", missing)
+}
+
+// matchSourceCountry matches source countries, or their complement when the
+// rule excludes them.
+func (m *Model) matchSourceCountry(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.SourceCountries) == 0 {
+		return ""
+	}
+	if flow.SrcCountry == "" {
+		return "the flow has no source country"
+	}
+	found := hasCountry(rule.SourceCountries, flow.SrcCountry)
+	switch {
+	case rule.ExcludeSrcCountries && found:
+		return fmt.Sprintf("source country %s is excluded", flow.SrcCountry)
+	case !rule.ExcludeSrcCountries && !found:
+		return fmt.Sprintf("source country %s is not among the rule source countries", flow.SrcCountry)
+	}
+	return ""
+}
+
+// matchDestination matches destination addresses, IP groups, countries and IP
+// categories. A flow matches when it matches any of them.
+func (m *Model) matchDestination(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.DestAddresses) == 0 && len(rule.DestIpGroups) == 0 && len(rule.DestCountries) == 0 && len(rule.DestIpCategories) == 0 {
+		return ""
+	}
+	if matchAddresses(rule.DestAddresses, flow.DestIP, flow.DestHost) ||
+		(flow.DestCountry != "" && hasCountry(rule.DestCountries, flow.DestCountry)) ||
+		hasAny(rule.DestIpCategories, flow.DestIPCategories) {
+		return ""
+	}
+	var missing []int
+	for _, ref := range rule.DestIpGroups {
+		group, ok := m.DestGroups[ref.ID]
+		if !ok {
+			missing = append(missing, ref.ID)
+			continue
+		}
+		if matchAddresses(group.Addresses, flow.DestIP, flow.DestHost) ||
+			(flow.DestCountry != "" && hasCountry(group.Countries, flow.DestCountry)) ||
+			hasAny(group.IPCategories, flow.DestIPCategories) {
+			return ""
+		}
+	}
+	dest := flow.DestHost
+	if flow.DestIP.IsValid() {
+		dest = strings.TrimSpace(flow.DestIP.String() + " " + flow.DestHost)
+	}
+	return withMissing(fmt.Sprintf("destination %q is not among the rule destination addresses, groups, countries or categories", dest), "destination IP groups", missing)
+}
+
+// matchService matches network services and network service groups.
+func (m *Model) matchService(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.NwServices) == 0 && len(rule.NwServiceGroups) == 0 {
+		return ""
+	}
+	if flow.Protocol == "" {
+		return "the flow has no protocol"
+	}
+	var missing []int
+	for _, ref := range rule.NwServices {
+		svc, ok := m.Services[ref.ID]
+		if !ok {
+			missing = append(missing, ref.ID)
+			continue
+		}
+		if matchPorts(flow, svc.SrcTCPPorts, svc.DestTCPPorts, svc.SrcUDPPorts, svc.DestUDPPorts) {
+			return ""
+		}
+	}
+	for _, ref := range rule.NwServiceGroups {
+		group, ok := m.ServiceGroups[ref.ID]
+		if !ok {
+			missing = append(missing, ref.ID)
+			continue
+		}
+		for _, s := range group.Services {
+			src, dest, srcUDP, destUDP := s.SrcTCPPorts, s.DestTCPPorts, s.SrcUDPPorts, s.DestUDPPorts
+			if full, ok := m.Services[s.ID]; ok && len(src)+len(dest)+len(srcUDP)+len(destUDP) == 0 {
+				src, dest, srcUDP, destUDP = full.SrcTCPPorts, full.DestTCPPorts, full.SrcUDPPorts, full.DestUDPPorts
+			}
+			if matchPorts(flow, src, dest, srcUDP, destUDP) {
+				return ""
+			}
+		}
+	}
+	return withMissing(fmt.Sprintf("%s port %d is not among the rule network services or service groups", strings.ToUpper(flow.Protocol), flow.DestPort), "network services or groups", missing)
+}
+
+// matchApplication matches network applications.
+func (m *Model) matchApplication(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.NwApplications) == 0 {
+		return ""
+	}
+	if flow.Application == "" {
+		return "the flow has no network application"
+	}
+	if !hasFold(rule.NwApplications, flow.Application) {
+		return fmt.Sprintf("network application %s is not among the rule network applications", flow.Application)
+	}
+	return ""
+}
+
+// matchDeviceTrust matches device trust levels.
+func (m *Model) matchDeviceTrust(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.DeviceTrustLevels) == 0 {
+		return ""
+	}
+	if !hasFold(rule.DeviceTrustLevels, flow.DeviceTrustLevel) {
+		return fmt.Sprintf("device trust level %q is not among the rule trust levels", flow.DeviceTrustLevel)
+	}
+	return ""
+}
+
+// matchTimeWindow matches time windows. Start and end times are minutes after
+// midnight; a window ending before it starts runs past midnight.
+func (m *Model) matchTimeWindow(rule *filteringrules.FirewallFilteringRules, flow Flow) string {
+	if len(rule.TimeWindows) == 0 {
+		return ""
+	}
+	day := strings.ToUpper(flow.Time.Weekday().String()[:3])
+	minute := int32(flow.Time.Hour()*60 + flow.Time.Minute())
+	var missing []int
+	for _, ref := range rule.TimeWindows {
+		w, ok := m.TimeWindows[ref.ID]
+		if !ok {
+			missing = append(missing, ref.ID)
+			continue
+		}
+		if !hasFold(w.DayOfWeek, "EVERYDAY") && !hasFold(w.DayOfWeek, day) {
+			continue
+		}
+		if w.StartTime <= w.EndTime && minute >= w.StartTime && minute <= w.EndTime ||
+			w.StartTime > w.EndTime && (minute >= w.StartTime || minute <= w.EndTime) {
+			return ""
+		}
+	}
+	return withMissing(fmt.Sprintf("%s is outside the rule time windows", flow.Time.Format(time.RFC1123)), "time windows", missing)
+}
+
+// matchUnsupported reports criteria the model cannot evaluate. Rules using
+// them never match.
+func (m *Model) matchUnsupported(rule *filteringrules.FirewallFilteringRules, _ Flow) string {
+	var criteria []string
+	for name, set := range map[string]bool{
+		"devices":                    len(rule.Devices) > 0,
+		"device groups":              len(rule.DeviceGroups) > 0,
+		"workload groups":            len(rule.WorkloadGroups) > 0,
+		"ZPA application segments":   len(rule.ZPAAppSegments) > 0,
+		"application services":       len(rule.AppServices) > 0,
+		"application service groups": len(rule.AppServiceGroups) > 0,
+		"network application groups": len(rule.NwApplicationGroups) > 0,
+	} {
+		if set {
+			criteria = append(criteria, name)
+		}
+	}
+	if len(criteria) == 0 {
+		return ""
+	}
+	slices.Sort(criteria)
+	return "the rule uses criteria that are not evaluated: " + strings.Join(criteria, ", ")
+}
+
+// matchAddresses reports whether ip or host matches an entry. Entries are IP
+// addresses, CIDR blocks, ranges like 10.0.0.1-10.0.0.9, or FQDNs where a
+// leading "." or "*." matches subdomains.
+func matchAddresses(entries []string, ip netip.Addr, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if ip.IsValid() {
+			if prefix, err := netip.ParsePrefix(entry); err == nil {
+				if prefix.Contains(ip) {
+					return true
+				}
+				continue
+			}
+			if addr, err := netip.ParseAddr(entry); err == nil {
+				if addr == ip {
+					return true
+				}
+				continue
+			}
+			if from, to, ok := strings.Cut(entry, "-"); ok {
+				first, err1 := netip.ParseAddr(strings.TrimSpace(from))
+				last, err2 := netip.ParseAddr(strings.TrimSpace(to))
+				if err1 == nil && err2 == nil {
+					if first.Compare(ip) <= 0 && ip.Compare(last) <= 0 {
+						return true
+					}
+					continue
+				}
+			}
+		}
+		if host != "" && matchHost(strings.ToLower(entry), host) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		pattern = suffix
+	}
+	if strings.HasPrefix(pattern, ".") {
+		return host == pattern[1:] || strings.HasSuffix(host, pattern)
+	}
+	return host == pattern
+}
+
+// matchPorts reports whether the flow uses one of the service ports. A
+// service without ports matches every port.
+func matchPorts(flow Flow, srcTCP, destTCP, srcUDP, destUDP []networkservices.NetworkPorts) bool {
+	if len(srcTCP)+len(destTCP)+len(srcUDP)+len(destUDP) == 0 {
+		return true
+	}
+	src, dest := srcTCP, destTCP
+	if strings.EqualFold(flow.Protocol, UDP) {
+		src, dest = srcUDP, destUDP
+	}
+	if len(src) == 0 && len(dest) == 0 {
+		return false
+	}
+	if len(dest) > 0 && !inPorts(dest, flow.DestPort) {
+		return false
+	}
+	return len(src) == 0 || flow.SrcPort == 0 || inPorts(src, flow.SrcPort)
+}
+
+func inPorts(ports []networkservices.NetworkPorts, port int) bool {
+	for _, p := range ports {
+		end := p.End
+		if end == 0 {
+			end = p.Start
+		}
+		if port >= p.Start && port <= end {
+			return true
+		}
+	}
+	return false
+}
+
+func hasID(refs []common.IDNameExtensions, id int) bool {
+	if id == 0 {
+		return false
+	}
+	for _, ref := range refs {
+		if ref.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCountry(countries []string, country string) bool {
+	country = strings.TrimPrefix(strings.ToUpper(country), "COUNTRY_")
+	for _, c := range countries {
+		if strings.TrimPrefix(strings.ToUpper(c), "COUNTRY_") == country {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAny(values, candidates []string) bool {
+	for _, c := range candidates {
+		if hasFold(values, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// withMissing appends the IDs of referenced objects absent from the model.
+func withMissing(reason, kind string, missing []int) string {
+	if len(missing) == 0 {
+		return reason
+	}
+	return fmt.Sprintf("%s (%s %v are not loaded)", reason, kind, missing)
+}
@@ -0,0 +1,362 @@
+package policylint
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/dlp/dlp_web_rules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewalldnscontrolpolicies"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/sandbox/sandbox_rules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/sslinspection"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/traffic_capture"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/urlfilteringpolicies"
+)
+
+// Policies that can be linted.
+const (
+	PolicyURLFiltering   = "url_filtering"
+	PolicyFirewall       = "firewall"
+	PolicyFirewallDNS    = "firewall_dns"
+	PolicyDLPWeb         = "dlp_web"
+	PolicySSLInspection  = "ssl_inspection"
+	PolicySandbox        = "sandbox"
+	PolicyTrafficCapture = "traffic_capture"
+)
+
+// AllPolicies lists every policy Load reads by default.
+var AllPolicies = []string{
+	PolicyURLFiltering,
+	PolicyFirewall,
+	PolicyFirewallDNS,
+	PolicyDLPWeb,
+	PolicySSLInspection,
+	PolicySandbox,
+	PolicyTrafficCapture,
+}
+
+// Inventory kinds referenced by rules.
+const (
+	KindSourceIPGroups       = "ipSourceGroups"
+	KindDestinationIPGroups  = "ipDestinationGroups"
+	KindNetworkServices      = "networkServices"
+	KindNetworkServiceGroups = "networkServiceGroups"
+	KindTimeWindows          = "timeWindows"
+	KindRuleLabels           = "ruleLabels"
+)
+
+// FromURLFilteringRules converts URL filtering rules.
+func FromURLFilteringRules(rules []urlfilteringpolicies.URLFilteringRule) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicyURLFiltering,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action,
+			Permissive:   allows(r.Action),
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationGroups))
+		rule.criterion("source", "sourceIpGroups", idStrings(r.SourceIPGroups))
+		rule.criterion("sourceCountries", "sourceCountries", r.SourceCountries)
+		rule.criterion("urlCategories", "urlCategories", r.URLCategories)
+		rule.criterion("protocols", "protocols", r.Protocols)
+		rule.criterion("requestMethods", "requestMethods", r.RequestMethods)
+		rule.criterion("userAgentTypes", "userAgentTypes", r.UserAgentTypes)
+		rule.criterion("userRiskScoreLevels", "userRiskScoreLevels", r.UserRiskScoreLevels)
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("device", "deviceTrustLevels", r.DeviceTrustLevels)
+		rule.criterion("workload", "workloadGroups", idNameStrings(r.WorkloadGroups))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		if r.EnforceTimeValidity {
+			rule.criterion("validity", "validity", []string{fmt.Sprintf("%d-%d %s", r.ValidityStartTime, r.ValidityEndTime, r.ValidityTimeZoneID)})
+		}
+		rule.reference(KindSourceIPGroups, ids(r.SourceIPGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromFirewallRules converts firewall filtering rules.
+func FromFirewallRules(rules []filteringrules.FirewallFilteringRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicyFirewall,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action,
+			DefaultRule:  r.DefaultRule,
+			Permissive:   allows(r.Action),
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationsGroups))
+		rule.criterion("source", "srcIps", r.SrcIps)
+		rule.criterion("source", "srcIpGroups", idStrings(r.SrcIpGroups))
+		rule.criterion("sourceCountries", countriesCriterion(r.ExcludeSrcCountries), r.SourceCountries)
+		rule.criterion("destination", "destAddresses", r.DestAddresses)
+		rule.criterion("destination", "destIpGroups", idStrings(r.DestIpGroups))
+		rule.criterion("destination", "destCountries", r.DestCountries)
+		rule.criterion("destination", "destIpCategories", r.DestIpCategories)
+		rule.criterion("service", "nwServices", idStrings(r.NwServices))
+		rule.criterion("service", "nwServiceGroups", idStrings(r.NwServiceGroups))
+		rule.criterion("application", "nwApplications", r.NwApplications)
+		rule.criterion("application", "nwApplicationGroups", idStrings(r.NwApplicationGroups))
+		rule.criterion("application", "appServices", idStrings(r.AppServices))
+		rule.criterion("application", "appServiceGroups", idStrings(r.AppServiceGroups))
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("device", "deviceTrustLevels", r.DeviceTrustLevels)
+		rule.criterion("workload", "workloadGroups", idNameStrings(r.WorkloadGroups))
+		rule.criterion("zpa", "zpaAppSegments", segmentStrings(r.ZPAAppSegments))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		rule.reference(KindSourceIPGroups, ids(r.SrcIpGroups))
+		rule.reference(KindDestinationIPGroups, ids(r.DestIpGroups))
+		rule.reference(KindNetworkServices, ids(r.NwServices))
+		rule.reference(KindNetworkServiceGroups, ids(r.NwServiceGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromFirewallDNSRules converts firewall DNS control rules.
+func FromFirewallDNSRules(rules []firewalldnscontrolpolicies.FirewallDNSRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicyFirewallDNS,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action,
+			DefaultRule:  r.DefaultRule,
+			Permissive:   allows(r.Action),
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationsGroups))
+		rule.criterion("source", "srcIps", r.SrcIps)
+		rule.criterion("source", "srcIpGroups", idStrings(r.SrcIpGroups))
+		rule.criterion("sourceCountries", "sourceCountries", r.SourceCountries)
+		rule.criterion("destination", "destAddresses", r.DestAddresses)
+		rule.criterion("destination", "destIpGroups", idStrings(r.DestIpGroups))
+		rule.criterion("destination", "destCountries", r.DestCountries)
+		rule.criterion("destination", "destIpCategories", r.DestIpCategories)
+		rule.criterion("resCategories", "resCategories", r.ResCategories)
+		rule.criterion("application", "applications", r.Applications)
+		rule.criterion("application", "applicationGroups", idStrings(r.ApplicationGroups))
+		rule.criterion("requestTypes", "dnsRuleRequestTypes", r.DNSRuleRequestTypes)
+		rule.criterion("protocols", "protocols", r.Protocols)
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		rule.reference(KindSourceIPGroups, ids(r.SrcIpGroups))
+		rule.reference(KindDestinationIPGroups, ids(r.DestIpGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromDLPWebRules converts DLP web rules.
+func FromDLPWebRules(rules []dlp_web_rules.WebDLPRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicyDLPWeb,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action,
+			ParentID:     r.ParentRule,
+			Permissive:   allows(r.Action),
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("who", "excludedUsers", idStrings(r.ExcludedUsers))
+		rule.criterion("who", "excludedGroups", idStrings(r.ExcludedGroups))
+		rule.criterion("who", "excludedDepartments", idStrings(r.ExcludedDepartments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationGroups))
+		rule.criterion("source", "sourceIpGroups", idStrings(r.SourceIpGroups))
+		rule.criterion("urlCategories", "urlCategories", idStrings(r.URLCategories))
+		rule.criterion("cloudApplications", "cloudApplications", r.CloudApplications)
+		rule.criterion("fileTypes", "fileTypes", r.FileTypes)
+		rule.criterion("fileTypes", "fileTypeCategories", idNameStrings(r.FileTypeCategories))
+		rule.criterion("protocols", "protocols", r.Protocols)
+		rule.criterion("dlpEngines", "dlpEngines", idStrings(r.DLPEngines))
+		rule.criterion("domainProfiles", "includedDomainProfiles", idStrings(r.IncludedDomainProfiles))
+		rule.criterion("domainProfiles", "excludedDomainProfiles", idStrings(r.ExcludedDomainProfiles))
+		rule.criterion("userRiskScoreLevels", "userRiskScoreLevels", r.UserRiskScoreLevels)
+		rule.criterion("contentLocations", "dlpContentLocationsScopes", r.DlpContentLocationsScopes)
+		rule.criterion("workload", "workloadGroups", idNameStrings(r.WorkloadGroups))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		if r.MinSize > 0 {
+			rule.criterion("minSize", "minSize", []string{strconv.Itoa(r.MinSize)})
+		}
+		rule.reference(KindSourceIPGroups, ids(r.SourceIpGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromSSLInspectionRules converts SSL inspection rules. Do-not-decrypt rules
+// count as permissive.
+func FromSSLInspectionRules(rules []sslinspection.SSLInspectionRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicySSLInspection,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action.Type,
+			DefaultRule:  r.DefaultRule,
+			Permissive:   r.Action.Type == "DO_NOT_DECRYPT",
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationGroups))
+		rule.criterion("source", "sourceIpGroups", idStrings(r.SourceIPGroups))
+		rule.criterion("destination", "destIpGroups", idStrings(r.DestIpGroups))
+		rule.criterion("urlCategories", "urlCategories", r.URLCategories)
+		rule.criterion("cloudApplications", "cloudApplications", r.CloudApplications)
+		rule.criterion("userAgentTypes", "userAgentTypes", r.UserAgentTypes)
+		rule.criterion("platforms", "platforms", r.Platforms)
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("device", "deviceTrustLevels", r.DeviceTrustLevels)
+		rule.criterion("proxyGateways", "proxyGateways", idStrings(r.ProxyGateways))
+		rule.criterion("zpa", "zpaAppSegments", segmentStrings(r.ZPAAppSegments))
+		rule.criterion("workload", "workloadGroups", idNameStrings(r.WorkloadGroups))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		rule.reference(KindSourceIPGroups, ids(r.SourceIPGroups))
+		rule.reference(KindDestinationIPGroups, ids(r.DestIpGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromSandboxRules converts sandbox rules.
+func FromSandboxRules(rules []sandbox_rules.SandboxRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicySandbox,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.BaRuleAction,
+			DefaultRule:  r.DefaultRule,
+			Permissive:   allows(r.BaRuleAction),
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationGroups))
+		rule.criterion("urlCategories", "urlCategories", r.URLCategories)
+		rule.criterion("protocols", "protocols", r.Protocols)
+		rule.criterion("fileTypes", "fileTypes", r.FileTypes)
+		rule.criterion("policyCategories", "baPolicyCategories", r.BaPolicyCategories)
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("zpa", "zpaAppSegments", segmentStrings(r.ZPAAppSegments))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+// FromTrafficCaptureRules converts traffic capture rules. Their actions are
+// never permissive.
+func FromTrafficCaptureRules(rules []traffic_capture.TrafficCaptureRules) []Rule {
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		rule := Rule{
+			Policy:       PolicyTrafficCapture,
+			ID:           r.ID,
+			Name:         r.Name,
+			Order:        r.Order,
+			Enabled:      enabled(r.State),
+			Action:       r.Action,
+			DefaultRule:  r.DefaultRule,
+			LastModified: modified(r.LastModifiedTime),
+		}
+		rule.criterion("who", "users", idStrings(r.Users))
+		rule.criterion("who", "groups", idStrings(r.Groups))
+		rule.criterion("who", "departments", idStrings(r.Departments))
+		rule.criterion("where", "locations", idStrings(r.Locations))
+		rule.criterion("where", "locationGroups", idStrings(r.LocationsGroups))
+		rule.criterion("source", "srcIps", r.SrcIps)
+		rule.criterion("source", "srcIpGroups", idStrings(r.SrcIpGroups))
+		rule.criterion("sourceCountries", countriesCriterion(r.ExcludeSrcCountries), r.SourceCountries)
+		rule.criterion("destination", "destAddresses", r.DestAddresses)
+		rule.criterion("destination", "destIpGroups", idStrings(r.DestIpGroups))
+		rule.criterion("destination", "destCountries", r.DestCountries)
+		rule.criterion("destination", "destIpCategories", r.DestIpCategories)
+		rule.criterion("service", "nwServices", idStrings(r.NwServices))
+		rule.criterion("service", "nwServiceGroups", idStrings(r.NwServiceGroups))
+		rule.criterion("application", "nwApplications", r.NwApplications)
+		rule.criterion("application", "nwApplicationGroups", idStrings(r.NwApplicationGroups))
+		rule.criterion("application", "appServiceGroups", idStrings(r.AppServiceGroups))
+		rule.criterion("device", "devices", idStrings(r.Devices))
+		rule.criterion("device", "deviceGroups", idStrings(r.DeviceGroups))
+		rule.criterion("device", "deviceTrustLevels", r.DeviceTrustLevels)
+		rule.criterion("workload", "workloadGroups", idNameStrings(r.WorkloadGroups))
+		rule.criterion("time", "timeWindows", idStrings(r.TimeWindows))
+		rule.reference(KindSourceIPGroups, ids(r.SrcIpGroups))
+		rule.reference(KindDestinationIPGroups, ids(r.DestIpGroups))
+		rule.reference(KindNetworkServices, ids(r.NwServices))
+		rule.reference(KindNetworkServiceGroups, ids(r.NwServiceGroups))
+		rule.reference(KindTimeWindows, ids(r.TimeWindows))
+		rule.reference(KindRuleLabels, ids(r.Labels))
+		out = append(out, rule)
+	}
+	return out
+}
+
+func countriesCriterion(exclude bool) string {
+	if exclude {
+		return "excludedSourceCountries"
+	}
+	return "sourceCountries"
+}
@@ -0,0 +1,403 @@
+// Package policylint reports problems in ZIA rule-based policies: rules
+// shadowed by earlier rules, exact duplicates, references to deleted or empty
+// objects, disabled rules left untouched for a long time and overly broad
+// allow rules. It reads URL filtering, firewall filtering, firewall DNS, DLP
+// web, SSL inspection, sandbox and traffic capture rules.
+//
+//	snapshot, err := policylint.Load(ctx, service)
+//	report := policylint.Lint(snapshot, policylint.WithDisabledAge(180*24*time.Hour))
+//	json.NewEncoder(os.Stdout).Encode(report)
+//	if report.HasFindings(policylint.SeverityWarning) {
+//		os.Exit(1)
+//	}
+//
+// The shadowing checks compare the criteria of each rule by value. They do not
+// resolve groups into their members, so a rule is only reported as shadowed
+// when an earlier rule provably matches everything it matches.
+package policylint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/dlp/dlp_web_rules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewalldnscontrolpolicies"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipdestinationgroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/ipsourcegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservicegroups"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/networkservices"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/timewindow"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/rule_labels"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/sandbox/sandbox_rules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/sslinspection"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/traffic_capture"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/urlfilteringpolicies"
+)
+
+const defaultDisabledAge = 90 * 24 * time.Hour
+
+// Finding kinds.
+const (
+	KindShadowed   = "shadowed"
+	KindDuplicate  = "duplicate"
+	KindDeletedRef = "deleted_reference"
+	KindEmptyRef   = "empty_reference"
+	KindStale      = "stale_disabled"
+	KindBroadAllow = "broad_allow"
+)
+
+// Severity ranks findings.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// RuleRef identifies a rule in a finding.
+type RuleRef struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Order int    `json:"order"`
+}
+
+// Finding is one problem found in a policy.
+type Finding struct {
+	Kind     string   `json:"kind"`
+	Severity Severity `json:"severity"`
+	Policy   string   `json:"policy"`
+	Rule     RuleRef  `json:"rule"`
+	// Related is the earlier rule of a shadowed or duplicate rule.
+	Related *RuleRef `json:"related,omitempty"`
+	Message string   `json:"message"`
+}
+
+// Report is the result of Lint. It is meant to be JSON encoded.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Rules       int       `json:"rules"`
+	Findings    []Finding `json:"findings"`
+}
+
+// HasFindings reports whether the report holds a finding of at least min severity.
+func (r *Report) HasFindings(min Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity.rank() >= min.rank() {
+			return true
+		}
+	}
+	return false
+}
+
+// Inventory maps an inventory kind, such as KindSourceIPGroups, to the IDs of
+// the existing objects and their number of members. A negative count means
+// the object cannot be empty. References to kinds absent from the inventory
+// are not checked.
+type Inventory map[string]map[int]int
+
+// Snapshot holds the rules to lint and the objects they can reference.
+type Snapshot struct {
+	Rules     []Rule
+	Inventory Inventory
+}
+
+// Option configures Lint.
+type Option func(*options)
+
+type options struct {
+	disabledAge time.Duration
+	now         time.Time
+}
+
+// WithDisabledAge reports disabled rules last modified more than d ago. The
+// default is 90 days; zero turns the check off.
+func WithDisabledAge(d time.Duration) Option {
+	return func(o *options) {
+		o.disabledAge = d
+	}
+}
+
+// WithNow sets the time the rule ages are measured at. The default is the
+// current time.
+func WithNow(now time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// Load reads the rules of the given policies, or of AllPolicies when none is
+// given, and the inventory of IP groups, network services, network service
+// groups, time windows and rule labels.
+func Load(ctx context.Context, service *zscaler.Service, policies ...string) (*Snapshot, error) {
+	if len(policies) == 0 {
+		policies = AllPolicies
+	}
+	snapshot := &Snapshot{}
+	for _, policy := range policies {
+		rules, err := loadPolicy(ctx, service, policy)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s rules: %w", policy, err)
+		}
+		snapshot.Rules = append(snapshot.Rules, rules...)
+	}
+	inventory, err := loadInventory(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Inventory = inventory
+	service.Client.GetLogger().Printf("[DEBUG] Loaded %d rules from %d policies for linting", len(snapshot.Rules), len(policies))
+	return snapshot, nil
+}
+
+func loadPolicy(ctx context.Context, service *zscaler.Service, policy string) ([]Rule, error) {
+	switch policy {
+	case PolicyURLFiltering:
+		rules, err := urlfilteringpolicies.GetAll(ctx, service)
+		return FromURLFilteringRules(rules), err
+	case PolicyFirewall:
+		rules, err := filteringrules.GetAll(ctx, service, nil)
+		return FromFirewallRules(rules), err
+	case PolicyFirewallDNS:
+		rules, err := firewalldnscontrolpolicies.GetAll(ctx, service)
+		return FromFirewallDNSRules(rules), err
+	case PolicyDLPWeb:
+		rules, err := dlp_web_rules.GetAll(ctx, service)
+		return FromDLPWebRules(rules), err
+	case PolicySSLInspection:
+		rules, err := sslinspection.GetAll(ctx, service)
+		return FromSSLInspectionRules(rules), err
+	case PolicySandbox:
+		rules, err := sandbox_rules.GetAll(ctx, service)
+		return FromSandboxRules(rules), err
+	case PolicyTrafficCapture:
+		rules, err := traffic_capture.GetAll(ctx, service, nil)
+		return FromTrafficCaptureRules(rules), err
+	}
+	return nil, fmt.Errorf("unknown policy %q", policy)
+}
+
+func loadInventory(ctx context.Context, service *zscaler.Service) (Inventory, error) {
+	inventory := Inventory{}
+	sourceGroups, err := ipsourcegroups.GetAll(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindSourceIPGroups] = map[int]int{}
+	for _, g := range sourceGroups {
+		inventory[KindSourceIPGroups][g.ID] = len(g.IPAddresses)
+	}
+	destGroups, err := ipdestinationgroups.GetAll(ctx, service, "")
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindDestinationIPGroups] = map[int]int{}
+	for _, g := range destGroups {
+		inventory[KindDestinationIPGroups][g.ID] = len(g.Addresses) + len(g.Countries) + len(g.IPCategories)
+	}
+	services, err := networkservices.GetAllNetworkServices(ctx, service, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindNetworkServices] = map[int]int{}
+	for _, s := range services {
+		inventory[KindNetworkServices][s.ID] = -1
+	}
+	serviceGroups, err := networkservicegroups.GetAllNetworkServiceGroups(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindNetworkServiceGroups] = map[int]int{}
+	for _, g := range serviceGroups {
+		inventory[KindNetworkServiceGroups][g.ID] = len(g.Services)
+	}
+	windows, err := timewindow.GetAll(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindTimeWindows] = map[int]int{}
+	for _, w := range windows {
+		inventory[KindTimeWindows][w.ID] = -1
+	}
+	labels, err := rule_labels.GetAll(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	inventory[KindRuleLabels] = map[int]int{}
+	for _, l := range labels {
+		inventory[KindRuleLabels][l.ID] = -1
+	}
+	return inventory, nil
+}
+
+// Lint checks the rules of snapshot. Findings are sorted by policy and rule order.
+func Lint(snapshot *Snapshot, opts ...Option) *Report {
+	o := options{disabledAge: defaultDisabledAge, now: time.Now()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	report := &Report{GeneratedAt: o.now.UTC(), Rules: len(snapshot.Rules), Findings: []Finding{}}
+
+	byPolicy := map[string][]*Rule{}
+	for i := range snapshot.Rules {
+		rule := &snapshot.Rules[i]
+		byPolicy[rule.Policy] = append(byPolicy[rule.Policy], rule)
+		report.Findings = append(report.Findings, checkReferences(rule, snapshot.Inventory)...)
+		if f, ok := checkStale(rule, o); ok {
+			report.Findings = append(report.Findings, f)
+		}
+		if f, ok := checkBroadAllow(rule); ok {
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	for _, rules := range byPolicy {
+		report.Findings = append(report.Findings, checkShadowing(rules)...)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Policy != b.Policy {
+			return a.Policy < b.Policy
+		}
+		if a.Rule.Order != b.Rule.Order {
+			return a.Rule.Order < b.Rule.Order
+		}
+		return a.Kind < b.Kind
+	})
+	return report
+}
+
+// checkShadowing reports enabled rules matched in full by an earlier enabled
+// rule of the same policy. Default rules and sub-rules are not checked.
+func checkShadowing(rules []*Rule) []Finding {
+	ordered := slices.Clone(rules)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].DefaultRule != ordered[j].DefaultRule {
+			return ordered[j].DefaultRule
+		}
+		return ordered[i].Order < ordered[j].Order
+	})
+	var findings []Finding
+	for i, rule := range ordered {
+		if !rule.Enabled || rule.DefaultRule || rule.ParentID != 0 {
+			continue
+		}
+		for _, earlier := range ordered[:i] {
+			if !earlier.Enabled || earlier.DefaultRule || earlier.ParentID != 0 {
+				continue
+			}
+			if earlier.sameMatch(rule) && earlier.Action == rule.Action {
+				findings = append(findings, newFinding(KindDuplicate, SeverityWarning, rule, earlier,
+					fmt.Sprintf("duplicates rule %q (order %d)", earlier.Name, earlier.Order)))
+				break
+			}
+			if earlier.covers(rule) {
+				severity, msg := SeverityWarning, fmt.Sprintf("never matches: rule %q (order %d) matches everything it matches", earlier.Name, earlier.Order)
+				if earlier.Action != rule.Action {
+					severity = SeverityError
+					msg += fmt.Sprintf(" and applies %s instead of %s", earlier.Action, rule.Action)
+				}
+				findings = append(findings, newFinding(KindShadowed, severity, rule, earlier, msg))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// checkReferences reports references to objects missing from, or empty in,
+// the inventory.
+func checkReferences(rule *Rule, inventory Inventory) []Finding {
+	var findings []Finding
+	kinds := make([]string, 0, len(rule.References))
+	for kind := range rule.References {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		known, ok := inventory[kind]
+		if !ok {
+			continue
+		}
+		for _, id := range rule.References[kind] {
+			members, exists := known[id]
+			switch {
+			case !exists:
+				findings = append(findings, newFinding(KindDeletedRef, SeverityError, rule, nil,
+					fmt.Sprintf("references %s %d, which does not exist", kind, id)))
+			case members == 0:
+				findings = append(findings, newFinding(KindEmptyRef, SeverityWarning, rule, nil,
+					fmt.Sprintf("references %s %d, which is empty", kind, id)))
+			}
+		}
+	}
+	return findings
+}
+
+// checkStale reports a disabled rule not modified within the disabled age.
+func checkStale(rule *Rule, o options) (Finding, bool) {
+	if rule.Enabled || o.disabledAge <= 0 || rule.LastModified.IsZero() {
+		return Finding{}, false
+	}
+	age := o.now.Sub(rule.LastModified)
+	if age <= o.disabledAge {
+		return Finding{}, false
+	}
+	return newFinding(KindStale, SeverityInfo, rule, nil,
+		fmt.Sprintf("disabled and unchanged for %d days", int(age.Hours()/24))), true
+}
+
+// narrowing are the dimensions that limit who or what a rule applies to.
+// Protocols, time windows and similar dimensions do not.
+var notNarrowing = map[string]bool{
+	"protocols":      true,
+	"time":           true,
+	"validity":       true,
+	"requestMethods": true,
+	"userAgentTypes": true,
+	"platforms":      true,
+}
+
+// checkBroadAllow reports enabled permissive rules that apply to every user,
+// location, source and destination.
+func checkBroadAllow(rule *Rule) (Finding, bool) {
+	if !rule.Enabled || !rule.Permissive || rule.DefaultRule {
+		return Finding{}, false
+	}
+	for dimension := range rule.Criteria {
+		if !notNarrowing[dimension] {
+			return Finding{}, false
+		}
+	}
+	return newFinding(KindBroadAllow, SeverityWarning, rule, nil,
+		fmt.Sprintf("%s applies to all users, locations, sources and destinations", rule.Action)), true
+}
+
+func newFinding(kind string, severity Severity, rule, related *Rule, message string) Finding {
+	f := Finding{
+		Kind:     kind,
+		Severity: severity,
+		Policy:   rule.Policy,
+		Rule:     RuleRef{ID: rule.ID, Name: rule.Name, Order: rule.Order},
+		Message:  message,
+	}
+	if related != nil {
+		f.Related = &RuleRef{ID: related.ID, Name: related.Name, Order: related.Order}
+	}
+	return f
+}
@@ -0,0 +1,191 @@
+package policylint
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
+)
+
+// Rule is a policy rule reduced to what the checks compare.
+//
+// Criteria maps a dimension, such as "who" or "destination", to its criteria
+// and their sorted values. A rule matches a dimension when it matches any of
+// its criteria, and matches when it matches every dimension; a dimension
+// without criteria matches everything. Criteria named "excluded..." remove
+// values from the dimension instead of adding them. Values "ANY" and
+// "ANY_RULE" are wildcards and are not stored.
+type Rule struct {
+	Policy      string `json:"policy"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Order       int    `json:"order"`
+	Enabled     bool   `json:"enabled"`
+	Action      string `json:"action"`
+	DefaultRule bool   `json:"defaultRule,omitempty"`
+	// ParentID is set on sub-rules, which are evaluated within their parent.
+	ParentID int `json:"parentId,omitempty"`
+	// Permissive is set when the action lets traffic through unchecked.
+	Permissive   bool                           `json:"permissive,omitempty"`
+	LastModified time.Time                      `json:"lastModified,omitempty"`
+	Criteria     map[string]map[string][]string `json:"criteria,omitempty"`
+	// References maps an inventory kind to the IDs of the objects the rule uses.
+	References map[string][]int `json:"references,omitempty"`
+}
+
+// criterion adds a criterion to a dimension of r.
+func (r *Rule) criterion(dimension, name string, values []string) {
+	values = normalize(values)
+	if len(values) == 0 {
+		return
+	}
+	if r.Criteria == nil {
+		r.Criteria = make(map[string]map[string][]string)
+	}
+	if r.Criteria[dimension] == nil {
+		r.Criteria[dimension] = make(map[string][]string)
+	}
+	r.Criteria[dimension][name] = values
+}
+
+// reference records the objects of kind r uses.
+func (r *Rule) reference(kind string, ids []int) {
+	if len(ids) == 0 {
+		return
+	}
+	if r.References == nil {
+		r.References = make(map[string][]int)
+	}
+	r.References[kind] = append(r.References[kind], ids...)
+}
+
+// covers reports whether every flow matching b also matches r.
+func (r *Rule) covers(b *Rule) bool {
+	for dimension, criteria := range r.Criteria {
+		other := b.Criteria[dimension]
+		include, exclude := split(criteria)
+		otherInclude, otherExclude := split(other)
+		if len(include) > 0 {
+			if len(otherInclude) == 0 {
+				return false
+			}
+			for name, values := range otherInclude {
+				if !subset(values, include[name]) {
+					return false
+				}
+			}
+		}
+		for name, values := range exclude {
+			if !subset(values, otherExclude[name]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sameMatch reports whether r and b match the same flows by the same criteria.
+func (r *Rule) sameMatch(b *Rule) bool {
+	if len(r.Criteria) != len(b.Criteria) {
+		return false
+	}
+	for dimension, criteria := range r.Criteria {
+		other, ok := b.Criteria[dimension]
+		if !ok || len(criteria) != len(other) {
+			return false
+		}
+		for name, values := range criteria {
+			if !slices.Equal(values, other[name]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func split(criteria map[string][]string) (include, exclude map[string][]string) {
+	include, exclude = map[string][]string{}, map[string][]string{}
+	for name, values := range criteria {
+		if strings.HasPrefix(name, "excluded") {
+			exclude[name] = values
+		} else {
+			include[name] = values
+		}
+	}
+	return include, exclude
+}
+
+// subset reports whether every value of a is in b. Both are sorted.
+func subset(a, b []string) bool {
+	for _, v := range a {
+		if _, found := slices.BinarySearch(b, v); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		switch strings.ToUpper(v) {
+		case "":
+			continue
+		case "ANY", "ANY_RULE":
+			return nil
+		}
+		out = append(out, v)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+func ids(refs []common.IDNameExtensions) []int {
+	out := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, ref.ID)
+	}
+	return out
+}
+
+func idStrings(refs []common.IDNameExtensions) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, strconv.Itoa(ref.ID))
+	}
+	return out
+}
+
+func idNameStrings(refs []common.IDName) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, strconv.Itoa(ref.ID))
+	}
+	return out
+}
+
+func segmentStrings(refs []common.ZPAAppSegments) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, strconv.Itoa(ref.ID))
+	}
+	return out
+}
+
+func modified(epoch int) time.Time {
+	if epoch <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(epoch), 0).UTC()
+}
+
+func enabled(state string) bool {
+	return !strings.EqualFold(state, "DISABLED")
+}
+
+func allows(action string) bool {
+	return strings.HasPrefix(strings.ToUpper(action), "ALLOW")
+}
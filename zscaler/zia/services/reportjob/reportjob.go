@@ -0,0 +1,237 @@
+// Package reportjob runs ZIA report tasks: it starts the task, polls its
+// status with backoff, streams the report to a handler and deletes the task,
+// also when the run fails or is canceled.
+//
+//	task := reportjob.AdminAuditLogs(service, adminauditlogs.AuditLogEntryRequest{})
+//	err := reportjob.Run(ctx, task, reportjob.Decode(func(e adminauditlogs.AuditLogEntry) error {
+//		return warehouse.Insert(e)
+//	}), reportjob.WithRange(from, to))
+//
+// Ranges longer than the maximum range are split into consecutive tasks run
+// one after the other, since ZIA runs a single report task of a kind at a time.
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+)
+
+// Task states reported by ZIA.
+const (
+	StateExecuting = "EXECUTING"
+	StateComplete  = "COMPLETE"
+	StateCancelled = "CANCELLED"
+)
+
+const (
+	defaultPollInterval    = 2 * time.Second
+	defaultMaxPollInterval = 30 * time.Second
+	defaultMaxRange        = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrTaskFailed is returned when ZIA reports an error for the task.
+	ErrTaskFailed = errors.New("reportjob: report task failed")
+	// ErrTaskCancelled is returned when the task was canceled on the ZIA side,
+	// for example by another admin.
+	ErrTaskCancelled = errors.New("reportjob: report task was cancelled")
+	// ErrCleanup is returned when the report was handled but the task could
+	// not be deleted afterwards.
+	ErrCleanup = errors.New("reportjob: report task was not deleted")
+)
+
+// Window is a time range. Both ends are included.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Status is the state of a running task.
+type Status struct {
+	State         string
+	ItemsComplete int
+	ErrorCode     string
+	ErrorMessage  string
+}
+
+// Task is a ZIA report task with a start, poll, download and delete lifecycle.
+type Task interface {
+	// Start starts the task for window, which is zero for tasks run without
+	// a range.
+	Start(ctx context.Context, window Window) error
+	Status(ctx context.Context) (*Status, error)
+	// Download returns the report as it is read from the response body.
+	Download(ctx context.Context) (io.ReadCloser, error)
+	// Delete removes the task, canceling it when still running.
+	Delete(ctx context.Context) error
+}
+
+// Handler consumes the report of one window.
+type Handler func(window Window, report io.Reader) error
+
+// Progress is passed to the progress callback after every status read.
+type Progress struct {
+	// Part counts from 1 to Parts, the number of tasks the range was split into.
+	Part   int
+	Parts  int
+	Window Window
+	Status Status
+}
+
+// Option configures Run.
+type Option func(*options)
+
+type options struct {
+	window          Window
+	maxRange        time.Duration
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	progress        func(Progress)
+}
+
+// WithRange sets the time range of the report.
+func WithRange(start, end time.Time) Option {
+	return func(o *options) {
+		o.window = Window{Start: start, End: end}
+	}
+}
+
+// WithMaxRange sets the longest range a single task covers. Longer ranges are
+// split. The default is 30 days.
+func WithMaxRange(d time.Duration) Option {
+	return func(o *options) {
+		o.maxRange = d
+	}
+}
+
+// WithPollInterval sets the first and the longest wait between status reads.
+// The wait doubles after every read. The defaults are 2 and 30 seconds.
+func WithPollInterval(initial, max time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = initial
+		o.maxPollInterval = max
+	}
+}
+
+// WithProgress sets a callback invoked after every status read.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// Run runs task over the configured range and passes each report to handle.
+// Tasks are deleted once handled, or when the run fails or ctx is canceled.
+func Run(ctx context.Context, task Task, handle Handler, opts ...Option) error {
+	o := options{
+		maxRange:        defaultMaxRange,
+		pollInterval:    defaultPollInterval,
+		maxPollInterval: defaultMaxPollInterval,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	windows := split(o.window, o.maxRange)
+	for i, window := range windows {
+		progress := Progress{Part: i + 1, Parts: len(windows), Window: window}
+		if err := runOne(ctx, task, handle, progress, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runOne(ctx context.Context, task Task, handle Handler, progress Progress, o options) (err error) {
+	if err := task.Start(ctx, progress.Window); err != nil {
+		return fmt.Errorf("starting report task: %w", err)
+	}
+	defer func() {
+		if derr := task.Delete(context.WithoutCancel(ctx)); derr != nil && err == nil {
+			err = fmt.Errorf("%w: %w", ErrCleanup, derr)
+		}
+	}()
+
+	interval := o.pollInterval
+	for {
+		status, err := task.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("reading report task status: %w", err)
+		}
+		if o.progress != nil {
+			progress.Status = *status
+			o.progress(progress)
+		}
+		if done, err := finished(status); done || err != nil {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, o.maxPollInterval)
+	}
+
+	report, err := task.Download(ctx)
+	if err != nil {
+		return fmt.Errorf("downloading report: %w", err)
+	}
+	defer report.Close()
+	return handle(progress.Window, report)
+}
+
+func finished(status *Status) (bool, error) {
+	if status.ErrorCode != "" || status.ErrorMessage != "" {
+		return true, fmt.Errorf("%w: %s %s", ErrTaskFailed, status.ErrorCode, status.ErrorMessage)
+	}
+	switch strings.ToUpper(status.State) {
+	case StateComplete:
+		return true, nil
+	case StateCancelled:
+		return true, ErrTaskCancelled
+	case "ERROR", "FAILED":
+		return true, fmt.Errorf("%w: state %s", ErrTaskFailed, status.State)
+	}
+	return false, nil
+}
+
+// split divides window into consecutive windows no longer than max.
+func split(window Window, max time.Duration) []Window {
+	if window.Start.IsZero() || window.End.IsZero() || max <= 0 || window.End.Sub(window.Start) <= max {
+		return []Window{window}
+	}
+	var windows []Window
+	for start := window.Start; !start.After(window.End); start = start.Add(max) {
+		end := start.Add(max - time.Millisecond)
+		if end.After(window.End) {
+			end = window.End
+		}
+		windows = append(windows, Window{Start: start, End: end})
+	}
+	return windows
+}
+
+// Decode returns a handler decoding each report into records of type T, a
+// struct with csv tags as described in package csvx, and passing them to fn.
+func Decode[T any](fn func(record T) error, opts ...csvx.Option) Handler {
+	return func(_ Window, report io.Reader) error {
+		for record, err := range csvx.NewDecoder[T](report, opts...).All() {
+			if err != nil {
+				return err
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
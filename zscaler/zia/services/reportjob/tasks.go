@@ -0,0 +1,143 @@
+package reportjob
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/adminauditlogs"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/eventlogentryreport"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/shadowitreport"
+)
+
+// AdminAuditLogs returns the admin audit log report task. The window of each
+// run replaces the start and end times of request.
+func AdminAuditLogs(service *zscaler.Service, request adminauditlogs.AuditLogEntryRequest) Task {
+	return &auditLogTask{service: service, request: request}
+}
+
+type auditLogTask struct {
+	service *zscaler.Service
+	request adminauditlogs.AuditLogEntryRequest
+}
+
+func (t *auditLogTask) Start(ctx context.Context, window Window) error {
+	request := t.request
+	if !window.Start.IsZero() {
+		request.StartTime = int(window.Start.UnixMilli())
+		request.EndTime = int(window.End.UnixMilli())
+	}
+	_, err := adminauditlogs.CreateAdminAuditLogsExport(ctx, t.service, request)
+	return err
+}
+
+func (t *auditLogTask) Status(ctx context.Context) (*Status, error) {
+	info, err := adminauditlogs.GetAll(zscaler.WithoutCache(ctx), t.service)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{State: info.Status, ItemsComplete: info.ProgressItemsComplete, ErrorCode: info.ErrorCode, ErrorMessage: info.ErrorMessage}, nil
+}
+
+func (t *auditLogTask) Download(ctx context.Context) (io.ReadCloser, error) {
+	return adminauditlogs.DownloadAdminAuditLogs(ctx, t.service)
+}
+
+func (t *auditLogTask) Delete(ctx context.Context) error {
+	_, err := adminauditlogs.Delete(ctx, t.service)
+	return ignoreNotFound(err)
+}
+
+// EventLogs returns the event log report task. The window of each run
+// replaces the start and end times of request.
+func EventLogs(service *zscaler.Service, request eventlogentryreport.EventLogEntryReport) Task {
+	return &eventLogTask{service: service, request: request}
+}
+
+type eventLogTask struct {
+	service *zscaler.Service
+	request eventlogentryreport.EventLogEntryReport
+}
+
+func (t *eventLogTask) Start(ctx context.Context, window Window) error {
+	request := t.request
+	if !window.Start.IsZero() {
+		request.StartTime = int(window.Start.UnixMilli())
+		request.EndTime = int(window.End.UnixMilli())
+	}
+	_, err := eventlogentryreport.CreateExport(ctx, t.service, &request)
+	return err
+}
+
+func (t *eventLogTask) Status(ctx context.Context) (*Status, error) {
+	info, err := eventlogentryreport.GetStatus(zscaler.WithoutCache(ctx), t.service)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{State: info.Status, ItemsComplete: info.ProgressItemsComplete, ErrorCode: info.ErrorCode, ErrorMessage: info.ErrorMessage}, nil
+}
+
+func (t *eventLogTask) Download(ctx context.Context) (io.ReadCloser, error) {
+	return eventlogentryreport.GetDownload(ctx, t.service)
+}
+
+func (t *eventLogTask) Delete(ctx context.Context) error {
+	_, err := eventlogentryreport.Delete(ctx, t.service)
+	return ignoreNotFound(err)
+}
+
+// ShadowITApplications returns the shadow IT cloud application export. ZIA
+// answers the export request with the report itself, so the task completes
+// as soon as it starts and has nothing to delete. It takes its range from the
+// Duration of request and ignores windows.
+func ShadowITApplications(service *zscaler.Service, request shadowitreport.CloudApplicationsExport) Task {
+	return &shadowITTask{service: service, request: request}
+}
+
+type shadowITTask struct {
+	service *zscaler.Service
+	request shadowitreport.CloudApplicationsExport
+	report  io.ReadCloser
+}
+
+func (t *shadowITTask) Start(ctx context.Context, _ Window) error {
+	report, err := shadowitreport.ExportCloudApplications(ctx, t.service, t.request)
+	if err != nil {
+		return err
+	}
+	t.report = report
+	return nil
+}
+
+func (t *shadowITTask) Status(context.Context) (*Status, error) {
+	return &Status{State: StateComplete}, nil
+}
+
+func (t *shadowITTask) Download(context.Context) (io.ReadCloser, error) {
+	report := t.report
+	t.report = nil
+	if report == nil {
+		return nil, errors.New("reportjob: shadow IT export was not started")
+	}
+	return report, nil
+}
+
+// Delete closes a report that was not downloaded.
+func (t *shadowITTask) Delete(context.Context) error {
+	if t.report == nil {
+		return nil
+	}
+	err := t.report.Close()
+	t.report = nil
+	return err
+}
+
+// ignoreNotFound treats a task that is already gone as deleted.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, errorx.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,155 @@
+package ruleorder
+
+import (
+	"context"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewalldnscontrolpolicies"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/firewallpolicies/filteringrules"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/sslinspection"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/traffic_capture"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/urlfilteringpolicies"
+)
+
+// Adapter is a Policy built from the service functions of a rule type, for
+// rule types without a constructor in this package.
+type Adapter[T any] struct {
+	Policy string
+	List   func(ctx context.Context) ([]T, error)
+	Get    func(ctx context.Context, id int) (*T, error)
+	// Update saves rule with its order set to order.
+	Update   func(ctx context.Context, rule *T, order int) error
+	Describe func(rule *T) Rule
+}
+
+func (a *Adapter[T]) Name() string {
+	return a.Policy
+}
+
+// Rules lists the rules past the response cache, so the order is current.
+func (a *Adapter[T]) Rules(ctx context.Context) ([]Rule, error) {
+	items, err := a.List(zscaler.WithoutCache(ctx))
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(items))
+	for i := range items {
+		rules = append(rules, a.Describe(&items[i]))
+	}
+	return rules, nil
+}
+
+// Move reads the rule again so the update does not overwrite other changes
+// with a stale copy.
+func (a *Adapter[T]) Move(ctx context.Context, id, order int) error {
+	rule, err := a.Get(zscaler.WithoutCache(ctx), id)
+	if err != nil {
+		return err
+	}
+	return a.Update(ctx, rule, order)
+}
+
+// URLFiltering returns the URL filtering policy.
+func URLFiltering(service *zscaler.Service) Policy {
+	return &Adapter[urlfilteringpolicies.URLFilteringRule]{
+		Policy: "url_filtering",
+		List: func(ctx context.Context) ([]urlfilteringpolicies.URLFilteringRule, error) {
+			return urlfilteringpolicies.GetAll(ctx, service)
+		},
+		Get: func(ctx context.Context, id int) (*urlfilteringpolicies.URLFilteringRule, error) {
+			return urlfilteringpolicies.Get(ctx, service, id)
+		},
+		Update: func(ctx context.Context, rule *urlfilteringpolicies.URLFilteringRule, order int) error {
+			rule.Order = order
+			_, _, err := urlfilteringpolicies.Update(ctx, service, rule.ID, rule)
+			return err
+		},
+		Describe: func(r *urlfilteringpolicies.URLFilteringRule) Rule {
+			return Rule{ID: r.ID, Name: r.Name, Order: r.Order, Rank: r.Rank}
+		},
+	}
+}
+
+// FirewallFiltering returns the firewall filtering policy.
+func FirewallFiltering(service *zscaler.Service) Policy {
+	return &Adapter[filteringrules.FirewallFilteringRules]{
+		Policy: "firewall",
+		List: func(ctx context.Context) ([]filteringrules.FirewallFilteringRules, error) {
+			return filteringrules.GetAll(ctx, service, nil)
+		},
+		Get: func(ctx context.Context, id int) (*filteringrules.FirewallFilteringRules, error) {
+			return filteringrules.Get(ctx, service, id)
+		},
+		Update: func(ctx context.Context, rule *filteringrules.FirewallFilteringRules, order int) error {
+			rule.Order = order
+			_, err := filteringrules.Update(ctx, service, rule.ID, rule)
+			return err
+		},
+		Describe: func(r *filteringrules.FirewallFilteringRules) Rule {
+			return Rule{ID: r.ID, Name: r.Name, Order: r.Order, Rank: r.Rank, Predefined: r.Predefined, DefaultRule: r.DefaultRule}
+		},
+	}
+}
+
+// FirewallDNS returns the firewall DNS control policy.
+func FirewallDNS(service *zscaler.Service) Policy {
+	return &Adapter[firewalldnscontrolpolicies.FirewallDNSRules]{
+		Policy: "firewall_dns",
+		List: func(ctx context.Context) ([]firewalldnscontrolpolicies.FirewallDNSRules, error) {
+			return firewalldnscontrolpolicies.GetAll(ctx, service)
+		},
+		Get: func(ctx context.Context, id int) (*firewalldnscontrolpolicies.FirewallDNSRules, error) {
+			return firewalldnscontrolpolicies.Get(ctx, service, id)
+		},
+		Update: func(ctx context.Context, rule *firewalldnscontrolpolicies.FirewallDNSRules, order int) error {
+			rule.Order = order
+			_, err := firewalldnscontrolpolicies.Update(ctx, service, rule.ID, rule)
+			return err
+		},
+		Describe: func(r *firewalldnscontrolpolicies.FirewallDNSRules) Rule {
+			return Rule{ID: r.ID, Name: r.Name, Order: r.Order, Rank: r.Rank, Predefined: r.Predefined, DefaultRule: r.DefaultRule}
+		},
+	}
+}
+
+// SSLInspection returns the SSL inspection policy.
+func SSLInspection(service *zscaler.Service) Policy {
+	return &Adapter[sslinspection.SSLInspectionRules]{
+		Policy: "ssl_inspection",
+		List: func(ctx context.Context) ([]sslinspection.SSLInspectionRules, error) {
+			return sslinspection.GetAll(ctx, service)
+		},
+		Get: func(ctx context.Context, id int) (*sslinspection.SSLInspectionRules, error) {
+			return sslinspection.Get(ctx, service, id)
+		},
+		Update: func(ctx context.Context, rule *sslinspection.SSLInspectionRules, order int) error {
+			rule.Order = order
+			_, err := sslinspection.Update(ctx, service, rule.ID, rule)
+			return err
+		},
+		Describe: func(r *sslinspection.SSLInspectionRules) Rule {
+			return Rule{ID: r.ID, Name: r.Name, Order: r.Order, Rank: r.Rank, Predefined: r.Predefined, DefaultRule: r.DefaultRule}
+		},
+	}
+}
+
+// TrafficCapture returns the traffic capture policy.
+func TrafficCapture(service *zscaler.Service) Policy {
+	return &Adapter[traffic_capture.TrafficCaptureRules]{
+		Policy: "traffic_capture",
+		List: func(ctx context.Context) ([]traffic_capture.TrafficCaptureRules, error) {
+			return traffic_capture.GetAll(ctx, service, nil)
+		},
+		Get: func(ctx context.Context, id int) (*traffic_capture.TrafficCaptureRules, error) {
+			return traffic_capture.Get(ctx, service, id)
+		},
+		Update: func(ctx context.Context, rule *traffic_capture.TrafficCaptureRules, order int) error {
+			rule.Order = order
+			_, err := traffic_capture.Update(ctx, service, rule.ID, rule)
+			return err
+		},
+		Describe: func(r *traffic_capture.TrafficCaptureRules) Rule {
+			return Rule{ID: r.ID, Name: r.Name, Order: r.Order, Rank: r.Rank, Predefined: r.Predefined, DefaultRule: r.DefaultRule}
+		},
+	}
+}
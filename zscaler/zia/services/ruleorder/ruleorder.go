@@ -0,0 +1,360 @@
+// Package ruleorder puts the rules of an ordered ZIA policy in a desired order.
+//
+// Apply reads the policy, computes the fewest rule moves that produce the
+// desired order, updates the moved rules and reads the policy back to verify
+// the result, retrying when it does not match. When the order cannot be
+// reached, the original order is restored so a failure does not leave the
+// policy half shuffled.
+//
+//	result, err := ruleorder.Apply(ctx, ruleorder.FirewallFiltering(service),
+//		ruleorder.ByName("Block malware", "Allow DNS", "Allow web"))
+//
+// Predefined and default rules keep their place. Rules missing from the
+// desired list follow the listed rules, in their current relative order.
+// Moves follow the ZIA semantics: updating the order of a rule inserts it at
+// that position and shifts the rules below it.
+package ruleorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+var (
+	// ErrUnknownRule is returned when a desired rule is not in the policy.
+	ErrUnknownRule = errors.New("ruleorder: unknown rule")
+	// ErrFixedRule is returned when a desired rule is a predefined or default rule.
+	ErrFixedRule = errors.New("ruleorder: predefined and default rules cannot be moved")
+	// ErrRankConflict is returned when the desired order puts a rule above a
+	// rule of a higher admin rank.
+	ErrRankConflict = errors.New("ruleorder: order conflicts with admin ranks")
+	// ErrNotConverged is returned when the policy did not reach the desired
+	// order within the allowed attempts.
+	ErrNotConverged = errors.New("ruleorder: policy did not reach the desired order")
+)
+
+// Rule is the part of a policy rule that ordering looks at.
+type Rule struct {
+	ID    int
+	Name  string
+	Order int
+	// Rank is the admin rank of the rule, 0 being the highest. A rule cannot
+	// be placed above a rule of a higher rank.
+	Rank        int
+	Predefined  bool
+	DefaultRule bool
+}
+
+func (r Rule) fixed() bool {
+	return r.Predefined || r.DefaultRule
+}
+
+// Policy is an ordered rule set.
+type Policy interface {
+	// Name names the policy in errors.
+	Name() string
+	// Rules returns every rule of the policy.
+	Rules(ctx context.Context) ([]Rule, error)
+	// Move updates the order of a rule.
+	Move(ctx context.Context, id, order int) error
+}
+
+// Ref identifies a rule by ID or, when ID is zero, by name.
+type Ref struct {
+	ID   int
+	Name string
+}
+
+func (r Ref) String() string {
+	if r.ID != 0 {
+		return strconv.Itoa(r.ID)
+	}
+	return strconv.Quote(r.Name)
+}
+
+// ByID returns references to the rules with the given IDs.
+func ByID(ids ...int) []Ref {
+	refs := make([]Ref, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, Ref{ID: id})
+	}
+	return refs
+}
+
+// ByName returns references to the rules with the given names.
+func ByName(names ...string) []Ref {
+	refs := make([]Ref, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, Ref{Name: name})
+	}
+	return refs
+}
+
+// Move is one rule order update.
+type Move struct {
+	ID   int
+	Name string
+	From int
+	To   int
+}
+
+// Plan is the set of moves that turns the current order into the desired one.
+type Plan struct {
+	Policy string
+	// Current and Desired list the movable rules in their current and
+	// desired order.
+	Current []Rule
+	Desired []Rule
+	// Moves must be applied in order.
+	Moves []Move
+}
+
+// Result describes an Apply.
+type Result struct {
+	Policy string
+	// Moves lists the moves applied, including moves of retries.
+	Moves []Move
+	// Attempts is the number of times moves were applied.
+	Attempts int
+}
+
+// Option configures planning and applying.
+type Option func(*options)
+
+type options struct {
+	attempts  int
+	rankCheck bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{attempts: 3, rankCheck: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAttempts sets how many times Apply applies moves before giving up and
+// restoring the original order. The default is 3.
+func WithAttempts(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.attempts = n
+		}
+	}
+}
+
+// WithRankCheck turns the admin rank check on or off. Turn it off for tenants
+// that do not enforce admin ranking.
+func WithRankCheck(enabled bool) Option {
+	return func(o *options) {
+		o.rankCheck = enabled
+	}
+}
+
+// NewPlan computes the moves that put rules in the desired order.
+func NewPlan(policy string, rules []Rule, desired []Ref, opts ...Option) (*Plan, error) {
+	return newPlan(policy, rules, desired, newOptions(opts))
+}
+
+// PlanFor reads policy and computes the moves that put it in the desired order.
+func PlanFor(ctx context.Context, policy Policy, desired []Ref, opts ...Option) (*Plan, error) {
+	rules, err := policy.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlan(policy.Name(), rules, desired, opts...)
+}
+
+// Apply puts policy in the desired order. When the order is not reached after
+// the allowed attempts, or the policy cannot be read back, the original order
+// is restored and the error is returned joined with any error of the restore.
+func Apply(ctx context.Context, policy Policy, desired []Ref, opts ...Option) (*Result, error) {
+	o := newOptions(opts)
+	plan, err := PlanFor(ctx, policy, desired, opts...)
+	if err != nil {
+		return nil, err
+	}
+	original, target := refsOf(plan.Current), refsOf(plan.Desired)
+	result := &Result{Policy: plan.Policy}
+
+	var lastErr error
+	for len(plan.Moves) > 0 {
+		if result.Attempts == o.attempts {
+			if lastErr == nil {
+				lastErr = ErrNotConverged
+			}
+			return result, restore(ctx, policy, original, fmt.Errorf("reordering %s: %w", result.Policy, lastErr))
+		}
+		result.Attempts++
+		lastErr = applyMoves(ctx, policy, plan.Moves, result)
+		if plan, err = PlanFor(ctx, policy, target, opts...); err != nil {
+			return result, restore(ctx, policy, original, fmt.Errorf("verifying the order of %s: %w", result.Policy, err))
+		}
+	}
+	return result, nil
+}
+
+func applyMoves(ctx context.Context, policy Policy, moves []Move, result *Result) error {
+	for _, m := range moves {
+		if err := policy.Move(ctx, m.ID, m.To); err != nil {
+			return fmt.Errorf("moving rule %q to order %d: %w", m.Name, m.To, err)
+		}
+		if result != nil {
+			result.Moves = append(result.Moves, m)
+		}
+	}
+	return nil
+}
+
+// restore puts policy back in its original order. It runs even when ctx was
+// canceled, since stopping half way is what it repairs.
+func restore(ctx context.Context, policy Policy, original []Ref, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	plan, err := PlanFor(ctx, policy, original, WithRankCheck(false))
+	if err == nil {
+		err = applyMoves(ctx, policy, plan.Moves, nil)
+	}
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("restoring the original order of %s: %w", policy.Name(), err))
+	}
+	return cause
+}
+
+func newPlan(policy string, rules []Rule, desired []Ref, o options) (*Plan, error) {
+	current := make([]Rule, 0, len(rules))
+	byID := make(map[int]Rule, len(rules))
+	byName := make(map[string][]Rule, len(rules))
+	for _, r := range rules {
+		byID[r.ID] = r
+		byName[r.Name] = append(byName[r.Name], r)
+		if !r.fixed() {
+			current = append(current, r)
+		}
+	}
+	sort.SliceStable(current, func(i, j int) bool {
+		if current[i].Order != current[j].Order {
+			return current[i].Order < current[j].Order
+		}
+		return current[i].ID < current[j].ID
+	})
+
+	plan := &Plan{Policy: policy, Current: current}
+	listed := make(map[int]bool, len(desired))
+	for _, ref := range desired {
+		rule, ok := byID[ref.ID]
+		if ref.ID == 0 {
+			matches := byName[ref.Name]
+			if len(matches) > 1 {
+				return nil, fmt.Errorf("ruleorder: %d rules of %s are named %s", len(matches), policy, ref)
+			}
+			if ok = len(matches) == 1; ok {
+				rule = matches[0]
+			}
+		}
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w %s in %s", ErrUnknownRule, ref, policy)
+		case rule.fixed():
+			return nil, fmt.Errorf("%w: %s", ErrFixedRule, ref)
+		case listed[rule.ID]:
+			return nil, fmt.Errorf("ruleorder: rule %s is listed twice", ref)
+		}
+		listed[rule.ID] = true
+		plan.Desired = append(plan.Desired, rule)
+	}
+	for _, r := range current {
+		if !listed[r.ID] {
+			plan.Desired = append(plan.Desired, r)
+		}
+	}
+
+	if o.rankCheck {
+		for i := 1; i < len(plan.Desired); i++ {
+			prev, r := plan.Desired[i-1], plan.Desired[i]
+			if r.Rank < prev.Rank {
+				return nil, fmt.Errorf("%w: rule %q of rank %d cannot follow rule %q of rank %d", ErrRankConflict, r.Name, r.Rank, prev.Name, prev.Rank)
+			}
+		}
+	}
+	plan.Moves = moves(current, plan.Desired)
+	return plan, nil
+}
+
+// moves returns the fewest insertions that turn current into desired. Rules
+// forming the longest run already in desired order stay put; every other rule
+// is inserted after its desired predecessor, in desired order. The orders of
+// the current rules are the slots the moved rules are inserted into.
+func moves(current, desired []Rule) []Move {
+	slots := make([]int, len(current))
+	position := make(map[int]int, len(current))
+	for i, r := range current {
+		slots[i] = r.Order
+		position[r.ID] = i
+	}
+	sequence := make([]int, len(desired))
+	for i, r := range desired {
+		sequence[i] = position[r.ID]
+	}
+	keep := longestIncreasing(sequence)
+
+	sim := make([]int, len(current))
+	for i, r := range current {
+		sim[i] = r.ID
+	}
+	var out []Move
+	for i, r := range desired {
+		if keep[i] {
+			continue
+		}
+		from := slices.Index(sim, r.ID)
+		sim = slices.Delete(sim, from, from+1)
+		at := 0
+		if i > 0 {
+			at = slices.Index(sim, desired[i-1].ID) + 1
+		}
+		sim = slices.Insert(sim, at, r.ID)
+		out = append(out, Move{ID: r.ID, Name: r.Name, From: r.Order, To: slots[at]})
+	}
+	return out
+}
+
+// longestIncreasing marks the elements of a longest strictly increasing
+// subsequence of seq.
+func longestIncreasing(seq []int) []bool {
+	var tails []int // indexes into seq of the smallest tail of each length
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		n := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		if n > 0 {
+			prev[i] = tails[n-1]
+		} else {
+			prev[i] = -1
+		}
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+	keep := make([]bool, len(seq))
+	if len(tails) == 0 {
+		return keep
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		keep[i] = true
+	}
+	return keep
+}
+
+func refsOf(rules []Rule) []Ref {
+	refs := make([]Ref, 0, len(rules))
+	for _, r := range rules {
+		refs = append(refs, Ref{ID: r.ID})
+	}
+	return refs
+}
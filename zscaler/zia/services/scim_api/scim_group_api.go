@@ -3,10 +3,12 @@ package scim_api
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"strings"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/scim"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
 )
 
@@ -86,6 +88,24 @@ func UpdateGroup(ctx context.Context, service *zscaler.ScimZIAService, groupID s
 	return resp, nil
 }
 
+// PatchGroupOperations applies the RFC 7644 operations of patch to a group, e.g.
+// scim.NewPatch().AddMembers(userID). The group is returned when the API answers with it and is nil after a 204.
+func PatchGroupOperations(ctx context.Context, service *zscaler.ScimZIAService, groupID string, patch *scim.PatchRequest) (*SCIMGroup, *http.Response, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, nil, err
+	}
+	v := new(SCIMGroup)
+	relativeURL := fmt.Sprintf("%s/%s", groupScimConfigEndpoint, groupID)
+	resp, err := service.Client.DoRequest(ctx, http.MethodPatch, relativeURL, patch, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp != nil && resp.StatusCode == http.StatusNoContent {
+		return nil, resp, nil
+	}
+	return v, resp, nil
+}
+
 func DeleteGroup(ctx context.Context, service *zscaler.ScimZIAService, groupID string) (*http.Response, error) {
 	relativeURL := fmt.Sprintf("%s/%s", groupScimConfigEndpoint, groupID)
 
@@ -105,3 +125,9 @@ func GetAllGroups(ctx context.Context, service *zscaler.ScimZIAService) ([]SCIMG
 		nil, // no filter
 	)
 }
+
+// IterAllGroups returns an iterator over every SCIM group, searching 100 groups per
+// page as the iteration reaches them.
+func IterAllGroups(ctx context.Context, service *zscaler.ScimZIAService) iter.Seq2[SCIMGroup, error] {
+	return common.IterAllPagesScimPost[SCIMGroup](ctx, service.Client, "/Groups/.search", 100)
+}
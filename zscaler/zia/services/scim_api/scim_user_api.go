@@ -3,10 +3,12 @@ package scim_api
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"strings"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/scim"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zia/services/common"
 )
 
@@ -91,6 +93,24 @@ func UpdateUser(ctx context.Context, service *zscaler.ScimZIAService, userID str
 	return resp, nil
 }
 
+// PatchUserOperations applies the RFC 7644 operations of patch to a user. The
+// user is returned when the API answers with it and is nil after a 204.
+func PatchUserOperations(ctx context.Context, service *zscaler.ScimZIAService, userID string, patch *scim.PatchRequest) (*SCIMUser, *http.Response, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, nil, err
+	}
+	v := new(SCIMUser)
+	relativeURL := fmt.Sprintf("%s/%s", userScimConfigEndpoint, userID)
+	resp, err := service.Client.DoRequest(ctx, http.MethodPatch, relativeURL, patch, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp != nil && resp.StatusCode == http.StatusNoContent {
+		return nil, resp, nil
+	}
+	return v, resp, nil
+}
+
 func DeleteUser(ctx context.Context, service *zscaler.ScimZIAService, userID string) (*http.Response, error) {
 	relativeURL := fmt.Sprintf("%s/%s", userScimConfigEndpoint, userID)
 
@@ -110,3 +130,9 @@ func GetAllUsers(ctx context.Context, service *zscaler.ScimZIAService) ([]SCIMGr
 		nil, // no filter
 	)
 }
+
+// IterAllUsers returns an iterator over every SCIM user, searching 100 users per
+// page as the iteration reaches them.
+func IterAllUsers(ctx context.Context, service *zscaler.ScimZIAService) iter.Seq2[SCIMUser, error] {
+	return common.IterAllPagesScimPost[SCIMUser](ctx, service.Client, "/Users/.search", 100)
+}
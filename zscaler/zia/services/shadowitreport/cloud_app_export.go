@@ -0,0 +1,53 @@
+package shadowitreport
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/csvx"
+)
+
+// CloudApplicationRecord is a row of the shadow IT cloud application export.
+// Byte counts are in bytes. Columns without a field are kept in Extra.
+type CloudApplicationRecord struct {
+	Application         string            `csv:"Application|Application Name|App Name"`
+	ApplicationCategory string            `csv:"Application Category|Category"`
+	SanctionedState     string            `csv:"Sanctioned State|Sanction State|Status"`
+	RiskIndex           int               `csv:"Risk Index|Risk Score|Risk"`
+	DataConsumed        int64             `csv:"Data Consumed|Total Bytes|Total Data"`
+	UploadBytes         int64             `csv:"Upload Bytes|Uploaded Bytes|Upload"`
+	DownloadBytes       int64             `csv:"Download Bytes|Downloaded Bytes|Download"`
+	Users               int               `csv:"Users|Number of Users|Total Users"`
+	Locations           int               `csv:"Locations|Number of Locations"`
+	Transactions        int64             `csv:"Transactions|Total Transactions"`
+	Employees           string            `csv:"Employees"`
+	Headquarters        string            `csv:"Headquarters|HQ"`
+	CustomTags          string            `csv:"Custom Tags|Tags"`
+	Extra               map[string]string `csv:"*"`
+}
+
+// NewCloudApplicationDecoder returns a decoder reading the rows of a cloud
+// application export, as returned by CreateCloudApplicationsExport, from r.
+func NewCloudApplicationDecoder(r io.Reader, opts ...csvx.Option) *csvx.Decoder[CloudApplicationRecord] {
+	return csvx.NewDecoder[CloudApplicationRecord](r, opts...)
+}
+
+// IterCloudApplicationsExport exports the cloud applications matching
+// exportRequest and iterates over the rows of the export as they are streamed.
+func IterCloudApplicationsExport(ctx context.Context, service *zscaler.Service, exportRequest CloudApplicationsExport, opts ...csvx.Option) iter.Seq2[CloudApplicationRecord, error] {
+	return func(yield func(CloudApplicationRecord, error) bool) {
+		report, err := ExportCloudApplications(ctx, service, exportRequest)
+		if err != nil {
+			yield(CloudApplicationRecord{}, err)
+			return
+		}
+		defer report.Close()
+		for record, err := range NewCloudApplicationDecoder(report, opts...).All() {
+			if !yield(record, err) {
+				return
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package shadowitreport
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -179,6 +182,20 @@ func CreateCloudApplicationsExport(ctx context.Context, service *zscaler.Service
 	return httpResp, nil
 }
 
+// ExportCloudApplications streams the CSV export of the cloud applications
+// matching exportRequest. The caller must close the returned reader.
+func ExportCloudApplications(ctx context.Context, service *zscaler.Service, exportRequest CloudApplicationsExport) (io.ReadCloser, error) {
+	data, err := json.Marshal(exportRequest)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Client.ExecuteStreamRequest(ctx, "POST", appExportEndpoint, bytes.NewReader(data), nil, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export cloud applications: %w", err)
+	}
+	return resp.Body, nil
+}
+
 // CreateCloudApplicationsExportCSV sends a POST request to create a new CloudApplicationsExportCSV
 func CreateCloudApplicationsExportCSV(ctx context.Context, service *zscaler.Service, entity string, appExport *CloudApplicationsExportCSV) (*CloudApplicationsExportCSV, *http.Response, error) {
 	// Validate the entity parameter
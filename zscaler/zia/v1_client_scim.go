@@ -38,8 +38,19 @@ var scimConfigOnce sync.Once
 
 type ScimZiaClient struct {
 	ScimConfig *ZIAScimConfig
+	// Transport, when set, sends the requests instead of ScimConfig.HTTPClient.
+	Transport ScimTransport
 }
 
+// ScimTransport sends a SCIM request to an absolute URL and returns the
+// response body. A OneAPI client implements it, giving SCIM calls the same
+// rate limiting, retries, logging and structured errors as other requests.
+type ScimTransport interface {
+	ExecuteRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) ([]byte, *http.Response, *http.Request, error)
+}
+
+const scimContentType = "application/scim+json"
+
 type ZIAScimConfig struct {
 	BaseURL     *url.URL
 	HTTPClient  *http.Client
@@ -191,13 +202,12 @@ func WithScimUserAgent(userAgent string) ScimConfigSetter {
 	}
 }
 
-// DoRequest performs an HTTP request specifically for SCIM endpoints with enhanced logging
-
-func (c *ScimZiaClient) DoRequest(ctx context.Context, method, endpoint string, payload interface{}, target interface{}) (*http.Response, error) {
+// URL returns the absolute URL of a SCIM endpoint.
+func (c *ScimZiaClient) URL(endpoint string) (string, error) {
 	// Create a copy of the base URL to avoid modifying the original
 	requestURL, err := url.Parse(c.ScimConfig.BaseURL.String())
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
+		return "", fmt.Errorf("invalid base URL: %w", err)
 	}
 
 	// Ensure the endpoint path is properly joined
@@ -205,8 +215,19 @@ func (c *ScimZiaClient) DoRequest(ctx context.Context, method, endpoint string,
 	if endpoint != "" {
 		requestURL.Path = path.Join(requestURL.Path, endpoint)
 	}
+	return requestURL.String(), nil
+}
 
-	fullURL := requestURL.String()
+// DoRequest performs an HTTP request specifically for SCIM endpoints with enhanced logging
+
+func (c *ScimZiaClient) DoRequest(ctx context.Context, method, endpoint string, payload interface{}, target interface{}) (*http.Response, error) {
+	fullURL, err := c.URL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if c.Transport != nil {
+		return c.doTransportRequest(ctx, method, fullURL, payload, target)
+	}
 	c.ScimConfig.Logger.Printf("[DEBUG] Making request to: %s", fullURL)
 
 	reqID := uuid.NewString()
@@ -228,7 +249,7 @@ func (c *ScimZiaClient) DoRequest(ctx context.Context, method, endpoint string,
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/scim+json")
+	req.Header.Add("Content-Type", scimContentType)
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.ScimConfig.AuthToken))
 	if c.ScimConfig.UserAgent != "" {
 		req.Header.Add("User-Agent", c.ScimConfig.UserAgent)
@@ -272,6 +293,30 @@ func (c *ScimZiaClient) DoRequest(ctx context.Context, method, endpoint string,
 	return resp, nil
 }
 
+// doTransportRequest sends a SCIM request through c.Transport. Empty responses,
+// such as a 204 to a PATCH or DELETE, leave target untouched.
+func (c *ScimZiaClient) doTransportRequest(ctx context.Context, method, fullURL string, payload interface{}, target interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		jsonPayload, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SCIM payload: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonPayload)
+	}
+	respData, resp, _, err := c.Transport.ExecuteRequest(ctx, method, fullURL, reqBody, nil, scimContentType)
+	if err != nil {
+		return resp, err
+	}
+	if target != nil && len(bytes.TrimSpace(respData)) > 0 {
+		if err := decodeJSON(respData, target); err != nil {
+			return resp, fmt.Errorf("failed to decode SCIM response: %w", err)
+		}
+		unescapeHTML(target)
+	}
+	return resp, nil
+}
+
 func decodeJSON(respData []byte, v interface{}) error {
 	return json.NewDecoder(bytes.NewBuffer(respData)).Decode(&v)
 }
@@ -750,11 +750,11 @@ func (client *Client) WithFreshCache() {
 
 func (c *Client) Create(ctx context.Context, endpoint string, o interface{}) (interface{}, error) {
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to create with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+t.Kind().String()+" not a Struct")
 	}
 	data, err := json.Marshal(o)
 	if err != nil {
@@ -840,12 +840,12 @@ func (c *Client) Create(ctx context.Context, endpoint string, o interface{}) (in
 
 func (c *Client) CreateWithSlicePayload(ctx context.Context, endpoint string, slice interface{}) ([]byte, error) {
 	if slice == nil {
-		return nil, errors.New("tried to create with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to create with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -867,12 +867,12 @@ func (c *Client) CreateWithSlicePayload(ctx context.Context, endpoint string, sl
 
 func (c *Client) UpdateWithSlicePayload(ctx context.Context, endpoint string, slice interface{}) ([]byte, error) {
 	if slice == nil {
-		return nil, errors.New("tried to update with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to update with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -891,7 +891,7 @@ func (c *Client) UpdateWithSlicePayload(ctx context.Context, endpoint string, sl
 // CreateWithRawPayload sends an HTTP POST request with a raw string payload.
 func (c *Client) CreateWithRawPayload(ctx context.Context, endpoint string, payload string) ([]byte, error) {
 	if payload == "" {
-		return nil, errors.New("tried to create with an empty string payload")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with an empty string payload")
 	}
 
 	// Convert the string payload to []byte
@@ -949,11 +949,11 @@ func (c *Client) Update(ctx context.Context, endpoint string, o interface{}) (in
 
 func (c *Client) updateGeneric(ctx context.Context, endpoint string, o interface{}, method, contentType string) (interface{}, error) {
 	if o == nil {
-		return nil, errors.New("tried to update with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to update with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+t.Kind().String()+" not a Struct")
 	}
 
 	data, err := json.Marshal(o)
@@ -1010,7 +1010,7 @@ func (c *Client) Delete(ctx context.Context, endpoint string) error {
 // BulkDelete sends an HTTP POST request for bulk deletion and expects a 204 No Content response.
 func (c *Client) BulkDelete(ctx context.Context, endpoint string, payload interface{}) (*http.Response, error) {
 	if payload == nil {
-		return nil, errors.New("tried to delete with a nil payload, expected a struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to delete with a nil payload, expected a struct")
 	}
 
 	// Marshal the payload into JSON
@@ -1039,12 +1039,12 @@ func (c *Client) BulkDelete(ctx context.Context, endpoint string, payload interf
 func (c *Client) CreateWithNoContent(ctx context.Context, endpoint string, o interface{}) (interface{}, error) {
 	// Validate the payload
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload, expected a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload, expected a Struct")
 	}
 
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("tried to create with a %s, expected a Struct", t.Kind().String())
+		return nil, errorx.Errorf(errorx.ErrValidation, "tried to create with a %s, expected a Struct", t.Kind().String())
 	}
 
 	// Marshal the payload
@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"reflect"
 	"strings"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
 )
 
 var errLegacyClientNotSet = fmt.Errorf("legacy client is not set")
@@ -24,11 +25,11 @@ func (c *Client) Create(ctx context.Context, endpoint string, o interface{}) (in
 	}
 
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to create with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+t.Kind().String()+" not a Struct")
 	}
 	data, err := json.Marshal(o)
 	if err != nil {
@@ -104,11 +105,11 @@ func (c *Client) Update(ctx context.Context, endpoint string, o interface{}) (in
 // General method to update an object using the specified HTTP method.
 func (c *Client) updateGeneric(ctx context.Context, endpoint string, o interface{}, method, contentType string) (interface{}, error) {
 	if o == nil {
-		return nil, errors.New("tried to update with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to update with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+t.Kind().String()+" not a Struct")
 	}
 	data, err := json.Marshal(o)
 	if err != nil {
@@ -160,7 +161,7 @@ func (c *Client) BulkDelete(ctx context.Context, endpoint string, payload interf
 	}
 
 	if payload == nil {
-		return nil, errors.New("tried to delete with a nil payload, expected a struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to delete with a nil payload, expected a struct")
 	}
 
 	data, err := json.Marshal(payload)
@@ -190,12 +191,12 @@ func (c *Client) CreateWithSlicePayload(ctx context.Context, endpoint string, sl
 	}
 
 	if slice == nil {
-		return nil, errors.New("tried to create with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to create with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -226,12 +227,12 @@ func (c *Client) UpdateWithSlicePayload(ctx context.Context, endpoint string, sl
 	}
 
 	if slice == nil {
-		return nil, errors.New("tried to update with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to update with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -258,7 +259,7 @@ func (c *Client) CreateWithRawPayload(ctx context.Context, endpoint string, payl
 	}
 
 	if payload == "" {
-		return nil, errors.New("tried to create with an empty string payload")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with an empty string payload")
 	}
 
 	// Convert the string payload to []byte
@@ -303,12 +304,12 @@ func (c *Client) CreateWithNoContent(ctx context.Context, endpoint string, o int
 
 	// Validate the payload
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload, expected a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload, expected a Struct")
 	}
 
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("tried to create with a %s, expected a Struct", t.Kind().String())
+		return nil, errorx.Errorf(errorx.ErrValidation, "tried to create with a %s, expected a Struct", t.Kind().String())
 	}
 
 	// Marshal the payload
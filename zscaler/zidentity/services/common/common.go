@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/prefetch"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
 )
 
@@ -147,6 +150,24 @@ func (p *PaginationQueryParams) ToURLValues() url.Values {
 // ReadAllPagesWithPagination reads all pages using the standard zidentity pagination response format
 func ReadAllPagesWithPagination[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams) ([]T, error) {
 	var allRecords []T
+	err := readPagesWithPagination(ctx, client, endpoint, queryParams, func(records []T) bool {
+		allRecords = append(allRecords, records...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allRecords, nil
+}
+
+// IterAllPagesWithPagination is the lazy form of ReadAllPagesWithPagination.
+func IterAllPagesWithPagination[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPagesWithPagination(ctx, client, endpoint, queryParams, yield)
+	})
+}
+
+func readPagesWithPagination[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams, yield func([]T) bool) error {
 	var currentOffset int
 
 	if queryParams == nil {
@@ -156,6 +177,10 @@ func ReadAllPagesWithPagination[T any](ctx context.Context, client *zscaler.Clie
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Set current offset
 		queryParams.Offset = currentOffset
 
@@ -170,22 +195,43 @@ func ReadAllPagesWithPagination[T any](ctx context.Context, client *zscaler.Clie
 		var response PaginationResponse[T]
 		err := client.Read(ctx, fullURL, &response)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch page at offset %d: %w", currentOffset, err)
+			return fmt.Errorf("failed to fetch page at offset %d: %w", currentOffset, err)
 		}
 
-		// Add records to results
-		allRecords = append(allRecords, response.Records...)
+		if !yield(response.Records) {
+			return nil
+		}
 
 		// Check if we've reached the end
 		if len(response.Records) < queryParams.Limit || response.NextLink == "" {
-			break
+			return nil
+		}
+
+		// Once the total is known, the remaining pages can be fetched in parallel
+		if concurrency := client.PageConcurrency(); concurrency > 1 && currentOffset == 0 && response.ResultsTotal > 0 && queryParams.Limit > 0 {
+			return prefetchPagesWithPagination(ctx, client, endpoint, *queryParams, response.ResultsTotal, concurrency, yield)
 		}
 
 		// Update offset for next iteration
 		currentOffset += len(response.Records)
 	}
+}
 
-	return allRecords, nil
+// prefetchPagesWithPagination fetches the pages after the first, up to
+// concurrency at a time, and yields them in offset order.
+func prefetchPagesWithPagination[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams PaginationQueryParams, total, concurrency int, yield func([]T) bool) error {
+	lastPage := (total - 1) / queryParams.Limit
+	return prefetch.Ordered(ctx, 1, lastPage, concurrency,
+		func(ctx context.Context, page int) ([]T, error) {
+			params := queryParams
+			params.Offset = page * queryParams.Limit
+			var response PaginationResponse[T]
+			if err := client.Read(ctx, BuildEndpointWithParams(endpoint, &params), &response); err != nil {
+				return nil, fmt.Errorf("failed to fetch page at offset %d: %w", params.Offset, err)
+			}
+			return response.Records, nil
+		},
+		yield)
 }
 
 // ReadPageWithPagination reads a single page using the standard zidentity pagination response format
@@ -216,7 +262,24 @@ func ReadPageWithPagination[T any](ctx context.Context, client *zscaler.Client,
 // ReadAllPagesWithCursor reads all pages using cursor-based pagination (next_link/prev_link)
 func ReadAllPagesWithCursor[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams) ([]T, error) {
 	var allRecords []T
+	err := readPagesWithCursor(ctx, client, endpoint, queryParams, func(records []T) bool {
+		allRecords = append(allRecords, records...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allRecords, nil
+}
+
+// IterAllPagesWithCursor is the lazy form of ReadAllPagesWithCursor.
+func IterAllPagesWithCursor[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPagesWithCursor(ctx, client, endpoint, queryParams, yield)
+	})
+}
 
+func readPagesWithCursor[T any](ctx context.Context, client *zscaler.Client, endpoint string, queryParams *PaginationQueryParams, yield func([]T) bool) error {
 	if queryParams == nil {
 		queryParams = &PaginationQueryParams{
 			Limit: DefaultPaginationOptions.DefaultPageSize,
@@ -231,26 +294,25 @@ func ReadAllPagesWithCursor[T any](ctx context.Context, client *zscaler.Client,
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Fetch current page
 		var response PaginationResponse[T]
 		err := client.Read(ctx, currentURL, &response)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch page: %w", err)
+			return fmt.Errorf("failed to fetch page: %w", err)
 		}
 
-		// Add records to results
-		allRecords = append(allRecords, response.Records...)
-
-		// Check if we've reached the end
-		if response.NextLink == "" {
-			break
+		// Stop when the caller is done or there is no next page
+		if !yield(response.Records) || response.NextLink == "" {
+			return nil
 		}
 
 		// Use next_link for next iteration
 		currentURL = response.NextLink
 	}
-
-	return allRecords, nil
 }
 
 // BuildEndpointWithParams builds an endpoint URL with query parameters
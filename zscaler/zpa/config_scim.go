@@ -43,8 +43,19 @@ var scimConfigOnce sync.Once
 
 type ScimZpaClient struct {
 	ScimConfig *ZPAScimConfig
+	// Transport, when set, sends the requests instead of ScimConfig.HTTPClient.
+	Transport ScimTransport
 }
 
+// ScimTransport sends a SCIM request to an absolute URL and returns the
+// response body. A OneAPI client implements it, giving SCIM calls the same
+// rate limiting, retries, logging and structured errors as other requests.
+type ScimTransport interface {
+	ExecuteRequest(ctx context.Context, method, endpoint string, body io.Reader, urlParams url.Values, contentType string) ([]byte, *http.Response, *http.Request, error)
+}
+
+const scimContentType = "application/scim+json"
+
 type ZPAScimConfig struct {
 	BaseURL     *url.URL
 	HTTPClient  *http.Client
@@ -216,9 +227,17 @@ func WithScimUserAgent(userAgent string) ScimConfigSetter {
 	}
 }
 
+// URL returns the absolute URL of a SCIM endpoint.
+func (c *ScimZpaClient) URL(endpoint string) string {
+	return fmt.Sprintf("%s%s", c.ScimConfig.BaseURL.String(), endpoint)
+}
+
 // DoRequest performs an HTTP request specifically for SCIM endpoints with enhanced logging
 func (c *ScimZpaClient) DoRequest(ctx context.Context, method, endpoint string, payload interface{}, target interface{}) (*http.Response, error) {
-	fullURL := fmt.Sprintf("%s%s", c.ScimConfig.BaseURL.String(), endpoint)
+	fullURL := c.URL(endpoint)
+	if c.Transport != nil {
+		return c.doTransportRequest(ctx, method, fullURL, payload, target)
+	}
 	reqID := uuid.NewString() // Generate a unique request ID
 	start := time.Now()
 
@@ -241,7 +260,7 @@ func (c *ScimZpaClient) DoRequest(ctx context.Context, method, endpoint string,
 	}
 
 	// Add headers, including the Authorization token
-	req.Header.Add("Content-Type", "application/scim+json")
+	req.Header.Add("Content-Type", scimContentType)
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.ScimConfig.AuthToken))
 	if c.ScimConfig.UserAgent != "" {
 		req.Header.Add("User-Agent", c.ScimConfig.UserAgent)
@@ -286,6 +305,30 @@ func (c *ScimZpaClient) DoRequest(ctx context.Context, method, endpoint string,
 	return resp, nil
 }
 
+// doTransportRequest sends a SCIM request through c.Transport. Empty responses,
+// such as a 204 to a PATCH or DELETE, leave target untouched.
+func (c *ScimZpaClient) doTransportRequest(ctx context.Context, method, fullURL string, payload interface{}, target interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		jsonPayload, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SCIM payload: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonPayload)
+	}
+	respData, resp, _, err := c.Transport.ExecuteRequest(ctx, method, fullURL, reqBody, nil, scimContentType)
+	if err != nil {
+		return resp, err
+	}
+	if target != nil && len(bytes.TrimSpace(respData)) > 0 {
+		if err := decodeJSON(respData, target); err != nil {
+			return resp, fmt.Errorf("failed to decode SCIM response: %w", err)
+		}
+		unescapeHTML(target)
+	}
+	return resp, nil
+}
+
 func readScimConfigFromFile(location string, c *ScimConfiguration) (*ScimConfiguration, error) {
 	yamlConfig, err := os.ReadFile(location)
 	if err != nil {
@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/prefetch"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa"
 )
@@ -258,8 +261,89 @@ func getAllPagesGeneric[T any](ctx context.Context, client *zscaler.Client, rela
 	)
 }
 
-// GetAllPagesGeneric fetches all resources instead of just one single page
-func GetAllPagesGeneric[T any](ctx context.Context, client *zscaler.Client, relativeURL, searchQuery string) ([]T, *http.Response, error) {
+// pageFetcher fetches one page and reports the total number of pages.
+type pageFetcher[T any] func(ctx context.Context, page int) (int, []T, *http.Response, error)
+
+// readPages fetches pages in order and passes each to yield until the last
+// page or until yield returns false. It returns the last response. With a
+// concurrency above 1, the pages after the first are prefetched in parallel.
+func readPages[T any](ctx context.Context, fetch pageFetcher[T], concurrency int, yield func([]T) bool) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	totalPages, items, resp, err := fetch(ctx, 1)
+	if err != nil {
+		return resp, err
+	}
+	if !yield(items) {
+		return resp, nil
+	}
+	if concurrency > 1 && totalPages > 2 {
+		return prefetchPages(ctx, fetch, totalPages, concurrency, yield)
+	}
+	for page := 2; page <= totalPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return resp, err
+		}
+		totalPages, items, resp, err = fetch(ctx, page)
+		if err != nil {
+			return resp, err
+		}
+		if !yield(items) {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// prefetchPages fetches pages 2..totalPages with up to concurrency requests in
+// flight and yields them in page order. The first error cancels the others.
+func prefetchPages[T any](ctx context.Context, fetch pageFetcher[T], totalPages, concurrency int, yield func([]T) bool) (*http.Response, error) {
+	type pageResult struct {
+		items []T
+		resp  *http.Response
+	}
+	var resp *http.Response
+	err := prefetch.Ordered(ctx, 2, totalPages, concurrency,
+		func(ctx context.Context, page int) (pageResult, error) {
+			_, items, resp, err := fetch(ctx, page)
+			return pageResult{items: items, resp: resp}, err
+		},
+		func(r pageResult) bool {
+			resp = r.resp
+			return yield(r.items)
+		})
+	return resp, err
+}
+
+// collectPages reads every page into one slice.
+func collectPages[T any](ctx context.Context, fetch pageFetcher[T], concurrency int) ([]T, *http.Response, error) {
+	var result []T
+	resp, err := readPages(ctx, fetch, concurrency, func(items []T) bool {
+		result = append(result, items...)
+		return true
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return result, resp, nil
+}
+
+// iterPages yields the items of every page. The fetcher is built once the
+// iteration starts, so nothing is requested before then, and an error building
+// it is yielded.
+func iterPages[T any](ctx context.Context, client *zscaler.Client, newFetcher func() (pageFetcher[T], error)) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		fetch, err := newFetcher()
+		if err != nil {
+			return err
+		}
+		_, err = readPages(ctx, fetch, client.PageConcurrency(), yield)
+		return err
+	})
+}
+
+func searchFetcher[T any](client *zscaler.Client, relativeURL, searchQuery string) pageFetcher[T] {
 	// Convert search query to filter format for ZPA endpoints (except SCIM endpoints)
 	// Don't pre-encode here - let the query parameter encoding handle it
 	if isZPAEndpoint(relativeURL) && !isSCIMEndpoint(relativeURL) {
@@ -268,21 +352,21 @@ func GetAllPagesGeneric[T any](ctx context.Context, client *zscaler.Client, rela
 		// For non-ZPA endpoints or SCIM endpoints, sanitize the query
 		searchQuery = sanitizeSearchQuery(searchQuery)
 	}
-
-	totalPages, result, resp, err := getAllPagesGeneric[T](ctx, client, relativeURL, 1, DefaultPageSize, Filter{Search: searchQuery})
-	if err != nil {
-		return nil, resp, err
-	}
-	var l []T
-	for page := 2; page <= totalPages; page++ {
-		totalPages, l, resp, err = getAllPagesGeneric[T](ctx, client, relativeURL, page, DefaultPageSize, Filter{Search: searchQuery})
-		if err != nil {
-			return nil, resp, err
-		}
-		result = append(result, l...)
+	return func(ctx context.Context, page int) (int, []T, *http.Response, error) {
+		return getAllPagesGeneric[T](ctx, client, relativeURL, page, DefaultPageSize, Filter{Search: searchQuery})
 	}
+}
 
-	return result, resp, nil
+// GetAllPagesGeneric fetches all resources instead of just one single page
+func GetAllPagesGeneric[T any](ctx context.Context, client *zscaler.Client, relativeURL, searchQuery string) ([]T, *http.Response, error) {
+	return collectPages(ctx, searchFetcher[T](client, relativeURL, searchQuery), client.PageConcurrency())
+}
+
+// IterAllPagesGeneric is the lazy form of GetAllPagesGeneric.
+func IterAllPagesGeneric[T any](ctx context.Context, client *zscaler.Client, relativeURL, searchQuery string) iter.Seq2[T, error] {
+	return iterPages(ctx, client, func() (pageFetcher[T], error) {
+		return searchFetcher[T](client, relativeURL, searchQuery), nil
+	})
 }
 
 type microTenantSample struct {
@@ -306,10 +390,30 @@ func getMicroTenantByName(ctx context.Context, client *zscaler.Client, microTena
 
 // GetAllPagesGenericWithCustomFilters fetches all resources instead of just one single page
 func GetAllPagesGenericWithCustomFilters[T any](ctx context.Context, client *zscaler.Client, relativeURL string, filters Filter) ([]T, *http.Response, error) {
+	fetch, resp, err := customFiltersFetcher[T](ctx, client, relativeURL, filters)
+	if err != nil {
+		return nil, resp, err
+	}
+	return collectPages(ctx, fetch, client.PageConcurrency())
+}
+
+// IterAllPagesGenericWithCustomFilters is the lazy form of
+// GetAllPagesGenericWithCustomFilters.
+func IterAllPagesGenericWithCustomFilters[T any](ctx context.Context, client *zscaler.Client, relativeURL string, filters Filter) iter.Seq2[T, error] {
+	return iterPages(ctx, client, func() (pageFetcher[T], error) {
+		fetch, _, err := customFiltersFetcher[T](ctx, client, relativeURL, filters)
+		return fetch, err
+	})
+}
+
+// customFiltersFetcher resolves the micro-tenant and search filters. When the
+// first page fails for a multi-word search, the fetcher retries it, and reads
+// the remaining pages, with a partial search on the first two words.
+func customFiltersFetcher[T any](ctx context.Context, client *zscaler.Client, relativeURL string, filters Filter) (pageFetcher[T], *http.Response, error) {
 	if (filters.MicroTenantID == nil || *filters.MicroTenantID == "") && filters.MicroTenantName != nil && *filters.MicroTenantName != "" {
 		// get microtenant id by name
 		mt, resp, err := getMicroTenantByName(ctx, client, *filters.MicroTenantName)
-		if err == nil {
+		if err != nil {
 			return nil, resp, err
 		}
 		if mt != nil {
@@ -329,63 +433,51 @@ func GetAllPagesGenericWithCustomFilters[T any](ctx context.Context, client *zsc
 		}
 	}
 
-	// Attempt full search first.
-	totalPages, result, resp, err := getAllPagesGenericWithCustomFilters[T](ctx, client, relativeURL, 1, DefaultPageSize, filters)
-	// If the full search fails and the query contains multiple words, try a partial search.
-	if err != nil && strings.Count(filters.Search, " ") > 0 {
-		// Extract the value part if search is in filter format (e.g., name+EQ+value or name%2BEQ%2Bvalue)
-		searchValue := filters.Search
-		if isZPAEndpoint(relativeURL) {
-			// Check for unencoded filter format (name+EQ+value)
-			if strings.Contains(filters.Search, "+EQ+") {
-				parts := strings.SplitN(filters.Search, "+EQ+", 2)
-				if len(parts) == 2 {
-					searchValue = parts[1]
-				}
-			} else if strings.Contains(filters.Search, "%2BEQ%2B") {
-				// Check for URL-encoded filter format (name%2BEQ%2Bvalue)
-				parts := strings.SplitN(filters.Search, "%2BEQ%2B", 2)
-				if len(parts) == 2 {
-					// URL decode the value part
-					if decoded, err := url.QueryUnescape(parts[1]); err == nil {
-						searchValue = decoded
-					} else {
+	return func(ctx context.Context, page int) (int, []T, *http.Response, error) {
+		totalPages, result, resp, err := getAllPagesGenericWithCustomFilters[T](ctx, client, relativeURL, page, DefaultPageSize, filters)
+		// If the full search fails and the query contains multiple words, try a partial search.
+		if err != nil && page == 1 && strings.Count(filters.Search, " ") > 0 {
+			// Extract the value part if search is in filter format (e.g., name+EQ+value or name%2BEQ%2Bvalue)
+			searchValue := filters.Search
+			if isZPAEndpoint(relativeURL) {
+				// Check for unencoded filter format (name+EQ+value)
+				if strings.Contains(filters.Search, "+EQ+") {
+					parts := strings.SplitN(filters.Search, "+EQ+", 2)
+					if len(parts) == 2 {
 						searchValue = parts[1]
 					}
+				} else if strings.Contains(filters.Search, "%2BEQ%2B") {
+					// Check for URL-encoded filter format (name%2BEQ%2Bvalue)
+					parts := strings.SplitN(filters.Search, "%2BEQ%2B", 2)
+					if len(parts) == 2 {
+						// URL decode the value part
+						if decoded, err := url.QueryUnescape(parts[1]); err == nil {
+							searchValue = decoded
+						} else {
+							searchValue = parts[1]
+						}
+					}
 				}
 			}
-		}
 
-		// Only try partial search if we have multiple words in the value
-		if strings.Count(searchValue, " ") > 0 {
-			tokens := strings.Split(searchValue, " ")
-			if len(tokens) >= 2 {
-				// Use only the first two words for partial search
-				partialValue := strings.Join(tokens[:2], " ")
-				// Reconstruct filter format if needed (but not for SCIM endpoints)
-				if isZPAEndpoint(relativeURL) && !isSCIMEndpoint(relativeURL) && (strings.Contains(filters.Search, "+EQ+") || strings.Contains(filters.Search, "%2BEQ%2B")) {
-					filters.Search = convertZPASearchToFilter(partialValue)
-				} else {
-					filters.Search = partialValue
+			// Only try partial search if we have multiple words in the value
+			if strings.Count(searchValue, " ") > 0 {
+				tokens := strings.Split(searchValue, " ")
+				if len(tokens) >= 2 {
+					// Use only the first two words for partial search
+					partialValue := strings.Join(tokens[:2], " ")
+					// Reconstruct filter format if needed (but not for SCIM endpoints)
+					if isZPAEndpoint(relativeURL) && !isSCIMEndpoint(relativeURL) && (strings.Contains(filters.Search, "+EQ+") || strings.Contains(filters.Search, "%2BEQ%2B")) {
+						filters.Search = convertZPASearchToFilter(partialValue)
+					} else {
+						filters.Search = partialValue
+					}
+					totalPages, result, resp, err = getAllPagesGenericWithCustomFilters[T](ctx, client, relativeURL, 1, DefaultPageSize, filters)
 				}
-				totalPages, result, resp, err = getAllPagesGenericWithCustomFilters[T](ctx, client, relativeURL, 1, DefaultPageSize, filters)
 			}
 		}
-	}
-	if err != nil {
-		return nil, resp, err
-	}
-
-	var l []T
-	for page := 2; page <= totalPages; page++ {
-		totalPages, l, resp, err = getAllPagesGenericWithCustomFilters[T](ctx, client, relativeURL, page, DefaultPageSize, filters)
-		if err != nil {
-			return nil, resp, err
-		}
-		result = append(result, l...)
-	}
-
-	return result, resp, nil
+		return totalPages, result, resp, err
+	}, nil, nil
 }
 
 func GetAllPagesScimGenericWithSearch[T any](
@@ -395,6 +487,39 @@ func GetAllPagesScimGenericWithSearch[T any](
 	itemsPerPage int,
 	searchFunc func(T) bool,
 ) ([]T, *http.Response, error) {
+	var allResources []T
+	resp, err := readScimPages(ctx, client, baseURL, itemsPerPage, func(resources []T) bool {
+		for _, resource := range resources {
+			if searchFunc != nil && searchFunc(resource) {
+				// Stop as soon as the desired item is found
+				allResources = []T{resource}
+				return false
+			}
+		}
+		// Append resources to the result set if not searching
+		if searchFunc == nil {
+			allResources = append(allResources, resources...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+	return allResources, resp, nil
+}
+
+// IterAllPagesScim returns an iterator over every resource of a SCIM list,
+// fetched in pages of itemsPerPage resources (10 by default, at most 100).
+func IterAllPagesScim[T any](ctx context.Context, client *zpa.ScimZpaClient, baseURL string, itemsPerPage int) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		_, err := readScimPages(ctx, client, baseURL, itemsPerPage, yield)
+		return err
+	})
+}
+
+// readScimPages pages through a SCIM list with startIndex and count, passing
+// each page to yield until the last page or until yield returns false.
+func readScimPages[T any](ctx context.Context, client *zpa.ScimZpaClient, baseURL string, itemsPerPage int, yield func([]T) bool) (*http.Response, error) {
 	// Enforce default and maximum limits for itemsPerPage
 	if itemsPerPage <= 0 {
 		itemsPerPage = 10 // Default to 10 if not specified
@@ -402,11 +527,12 @@ func GetAllPagesScimGenericWithSearch[T any](
 		itemsPerPage = 100 // Enforce maximum limit of 100
 	}
 
-	var allResources []T
 	startIndex := 1
 	var lastResp *http.Response
-
 	for {
+		if err := ctx.Err(); err != nil {
+			return lastResp, err
+		}
 		// Construct the paginated URL
 		paginatedURL := fmt.Sprintf("%s?startIndex=%d&count=%d", baseURL, startIndex, itemsPerPage)
 
@@ -419,34 +545,18 @@ func GetAllPagesScimGenericWithSearch[T any](
 		// Perform the HTTP request and parse the response
 		resp, err := client.DoRequest(ctx, http.MethodGet, paginatedURL, nil, &paginatedResponse)
 		if err != nil {
-			return nil, resp, fmt.Errorf("error fetching paginated data: %w", err)
+			return resp, fmt.Errorf("error fetching paginated data: %w", err)
 		}
 		lastResp = resp // Track the last HTTP response
 
-		// Iterate through the resources to search for the specific item
-		for _, resource := range paginatedResponse.Resources {
-			if searchFunc != nil && searchFunc(resource) {
-				// Return immediately if the desired item is found
-				return []T{resource}, resp, nil
-			}
-		}
-
-		// Append resources to the result set if not searching
-		if searchFunc == nil {
-			allResources = append(allResources, paginatedResponse.Resources...)
-		}
-
 		// Check if all records have been fetched
-		if startIndex+itemsPerPage > paginatedResponse.TotalResults || len(paginatedResponse.Resources) == 0 {
-			break
+		if !yield(paginatedResponse.Resources) || startIndex+itemsPerPage > paginatedResponse.TotalResults || len(paginatedResponse.Resources) == 0 {
+			return lastResp, nil
 		}
 
 		// Move to the next page
 		startIndex += itemsPerPage
 	}
-
-	// Return all resources if no specific item was found
-	return allResources, lastResp, nil
 }
 
 // isZPAEndpoint checks if the given URL is a ZPA endpoint
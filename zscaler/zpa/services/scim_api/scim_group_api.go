@@ -3,10 +3,12 @@ package scim_api
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"strings"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/scim"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/common"
 )
 
@@ -97,6 +99,25 @@ func PatchGroup(ctx context.Context, service *zscaler.ScimZPAService, groupID st
 	return resp, nil
 }
 
+// PatchGroupOperations applies the RFC 7644 operations of patch to a group,
+// e.g. scim.NewPatch().AddMembers(userID). The group is returned when the API
+// answers with it and is nil after a 204.
+func PatchGroupOperations(ctx context.Context, service *zscaler.ScimZPAService, groupID string, patch *scim.PatchRequest) (*ScimGroup, *http.Response, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, nil, err
+	}
+	v := new(ScimGroup)
+	relativeURL := service.Client.ScimConfig.IDPId + groupScimConfigEndpoint + "/" + groupID
+	resp, err := service.Client.DoRequest(ctx, http.MethodPatch, relativeURL, patch, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp != nil && resp.StatusCode == http.StatusNoContent {
+		return nil, resp, nil
+	}
+	return v, resp, nil
+}
+
 func DeleteGroup(ctx context.Context, service *zscaler.ScimZPAService, groupID string) (*http.Response, error) {
 	relativeURL := fmt.Sprintf("%s%s/%s", service.Client.ScimConfig.IDPId, groupScimConfigEndpoint, groupID)
 	resp, err := service.Client.DoRequest(ctx, http.MethodDelete, relativeURL, nil, nil)
@@ -120,3 +141,13 @@ func GetAllGroups(ctx context.Context, service *zscaler.ScimZPAService, count ..
 	// Call the pagination function with nil as the searchFunc
 	return common.GetAllPagesScimGenericWithSearch[ScimGroup](ctx, service.Client, relativeURL, itemsPerPage, nil)
 }
+
+// IterAllGroups returns an iterator over every SCIM group, fetching count
+// groups (10 by default) per page as the iteration reaches them.
+func IterAllGroups(ctx context.Context, service *zscaler.ScimZPAService, count ...int) iter.Seq2[ScimGroup, error] {
+	itemsPerPage := 0
+	if len(count) > 0 && count[0] > 0 {
+		itemsPerPage = count[0]
+	}
+	return common.IterAllPagesScim[ScimGroup](ctx, service.Client, service.Client.ScimConfig.IDPId+groupScimConfigEndpoint, itemsPerPage)
+}
@@ -3,10 +3,12 @@ package scim_api
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"strings"
 
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/scim"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zpa/services/common"
 )
 
@@ -119,6 +121,24 @@ func PatchUser(ctx context.Context, service *zscaler.ScimZPAService, userID stri
 	return resp, nil
 }
 
+// PatchUserOperations applies the RFC 7644 operations of patch to a user. The
+// user is returned when the API answers with it and is nil after a 204.
+func PatchUserOperations(ctx context.Context, service *zscaler.ScimZPAService, userID string, patch *scim.PatchRequest) (*ScimUser, *http.Response, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, nil, err
+	}
+	v := new(ScimUser)
+	relativeURL := service.Client.ScimConfig.IDPId + userScimConfigEndpoint + "/" + userID
+	resp, err := service.Client.DoRequest(ctx, http.MethodPatch, relativeURL, patch, v)
+	if err != nil {
+		return nil, resp, err
+	}
+	if resp != nil && resp.StatusCode == http.StatusNoContent {
+		return nil, resp, nil
+	}
+	return v, resp, nil
+}
+
 func DeleteUser(ctx context.Context, service *zscaler.ScimZPAService, userID string) (*http.Response, error) {
 	relativeURL := fmt.Sprintf("%s%s/%s", service.Client.ScimConfig.IDPId, userScimConfigEndpoint, userID)
 	resp, err := service.Client.DoRequest(ctx, http.MethodDelete, relativeURL, nil, nil)
@@ -142,3 +162,13 @@ func GetAllUsers(ctx context.Context, service *zscaler.ScimZPAService, count ...
 	// Call the pagination function with nil as the searchFunc
 	return common.GetAllPagesScimGenericWithSearch[ScimUser](ctx, service.Client, relativeURL, itemsPerPage, nil)
 }
+
+// IterAllUsers returns an iterator over every SCIM user, fetching count users
+// (10 by default) per page as the iteration reaches them.
+func IterAllUsers(ctx context.Context, service *zscaler.ScimZPAService, count ...int) iter.Seq2[ScimUser, error] {
+	itemsPerPage := 0
+	if len(count) > 0 && count[0] > 0 {
+		itemsPerPage = count[0]
+	}
+	return common.IterAllPagesScim[ScimUser](ctx, service.Client, service.Client.ScimConfig.IDPId+userScimConfigEndpoint, itemsPerPage)
+}
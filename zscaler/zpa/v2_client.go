@@ -312,7 +312,7 @@ func Authenticate(ctx context.Context, cfg *Configuration, logger logger.Logger)
 	if resp.StatusCode >= 300 {
 		respBody, _ := io.ReadAll(resp.Body)
 		logger.Printf("[ERROR] Authentication failed with status: %d, response: %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("authentication failed with status: %d, response: %s", resp.StatusCode, string(respBody))
+		return nil, errorx.Errorf(errorx.AuthFailureKind(resp), "authentication failed with status: %d, response: %s", resp.StatusCode, string(respBody))
 	}
 
 	var token AuthToken
@@ -0,0 +1,197 @@
+package zscalertest
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	ziaStatusPath        = "/zia/api/v1/status"
+	ziaActivatePath      = "/zia/api/v1/status/activate"
+	ziaEusaPath          = "/zia/api/v1/eusaStatus"
+	ztwStatusPath        = "/ztw/api/v1/ecAdminActivateStatus"
+	ztwActivatePath      = "/ztw/api/v1/ecAdminActivateStatus/activate"
+	ztwForceActivatePath = "/ztw/api/v1/ecAdminActivateStatus/forcedActivate"
+
+	eusaID = 1
+)
+
+// activation is the activation state of one product. Successful ZIA and ZTW
+// mutations leave changes pending until they are activated.
+type activation struct {
+	pending     bool
+	otherEdits  bool
+	inProgress  int // status reads still reporting an activation in progress
+	activations int
+}
+
+// WithActivationDelay makes the activation status report an activation in
+// progress for the given number of status reads after each activation.
+func WithActivationDelay(reads int) Option {
+	return func(s *Server) {
+		s.activationDelay = reads
+	}
+}
+
+// SetEusaAccepted sets whether the latest ZIA EUSA is accepted. It is
+// accepted by default.
+func (s *Server) SetEusaAccepted(accepted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eusaPending = !accepted
+}
+
+// SetOtherAdminEdits simulates changes another admin made to product ("zia"
+// or "ztw") and did not activate. A regular activation leaves them pending.
+func (s *Server) SetOtherAdminEdits(product string, pending bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activation(product).otherEdits = pending
+}
+
+// StartAdminActivation simulates another admin activating product: the status
+// reports an activation in progress for the given number of reads.
+func (s *Server) StartAdminActivation(product string, reads int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activation(product).inProgress = reads
+}
+
+// ActivationPending reports whether product has changes made through the API
+// that were not activated.
+func (s *Server) ActivationPending(product string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activation(product).pending
+}
+
+// Activations returns the number of activations requested for product.
+func (s *Server) Activations(product string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activation(product).activations
+}
+
+// activation returns the state of product. The caller must hold s.mu.
+func (s *Server) activation(product string) *activation {
+	a, ok := s.activations[product]
+	if !ok {
+		a = &activation{}
+		s.activations[product] = a
+	}
+	return a
+}
+
+// handleActivation serves the ZIA and ZTW activation and EUSA endpoints and
+// reports whether path was one of them.
+func (s *Server) handleActivation(w http.ResponseWriter, r *http.Request, path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case path == ziaStatusPath && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": s.ziaStatus(s.activation("zia"))})
+	case path == ziaActivatePath && r.Method == http.MethodPost:
+		if s.eusaPending {
+			writeError(w, familyZIA, http.StatusForbidden, "EUSA_NOT_ACCEPTED", "the latest EUSA was not accepted")
+			return true
+		}
+		a := s.activate("zia", false)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": s.ziaStatus(a)})
+	case path == ziaEusaPath+"/latest" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.eusaStatus())
+	case strings.HasPrefix(path, ziaEusaPath+"/") && r.Method == http.MethodPut:
+		s.eusaPending = false
+		writeJSON(w, http.StatusOK, s.eusaStatus())
+	case path == ztwStatusPath && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.ztwStatus(s.activation("ztw")))
+	case (path == ztwActivatePath || path == ztwForceActivatePath) && r.Method == http.MethodPut:
+		a := s.activate("ztw", path == ztwForceActivatePath)
+		writeJSON(w, http.StatusOK, s.ztwStatus(a))
+	default:
+		return false
+	}
+	return true
+}
+
+// activate activates the pending changes of product. A forced activation also
+// activates the changes of other admins. The caller must hold s.mu.
+func (s *Server) activate(product string, force bool) *activation {
+	a := s.activation(product)
+	a.activations++
+	a.pending = false
+	if force {
+		a.otherEdits = false
+	}
+	a.inProgress = s.activationDelay
+	return a
+}
+
+// ziaStatus returns the ZIA status and consumes one in-progress read. The
+// caller must hold s.mu.
+func (s *Server) ziaStatus(a *activation) string {
+	switch {
+	case a.inProgress > 0:
+		a.inProgress--
+		return "INPROGRESS"
+	case a.pending || a.otherEdits:
+		return "PENDING"
+	}
+	return "ACTIVE"
+}
+
+// ztwStatus returns the ZTW admin activation status and consumes one
+// in-progress read. The caller must hold s.mu.
+func (s *Server) ztwStatus(a *activation) map[string]interface{} {
+	admin := "ADM_LOGGED_IN"
+	switch {
+	case a.inProgress > 0:
+		a.inProgress--
+		admin = "ADM_ACTIVATING"
+	case a.activations > 0:
+		admin = "ADM_ACTV_DONE"
+	}
+	edits := "EDITS_CLEARED"
+	if a.pending || a.otherEdits {
+		edits = "EDITS_PRESENT"
+	}
+	return map[string]interface{}{
+		"orgEditStatus":         edits,
+		"orgLastActivateStatus": "ORG_ACTV_DONE",
+		"adminActivateStatus":   admin,
+		"adminStatusMap":        map[string]interface{}{},
+	}
+}
+
+// eusaStatus returns the latest EUSA status. The caller must hold s.mu.
+func (s *Server) eusaStatus() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             eusaID,
+		"version":        map[string]interface{}{"id": 1, "name": "EUSA"},
+		"acceptedStatus": !s.eusaPending,
+	}
+}
+
+// trackMutation marks the product of path pending after a successful
+// mutation. The caller must hold s.mu.
+func (s *Server) trackMutation(method, path string, status int) {
+	if method == http.MethodGet || status < 200 || status > 299 {
+		return
+	}
+	for _, product := range []string{"zia", "ztw"} {
+		if strings.HasPrefix(path, "/"+product+"/") {
+			s.activation(product).pending = true
+		}
+	}
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,115 @@
+package zscalertest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault is a canned error response returned instead of the normal one.
+type Fault struct {
+	// Method restricts the fault to one HTTP method. Empty matches every method.
+	Method string
+	// PathPrefix restricts the fault to paths starting with it. Empty matches
+	// every API path; the token endpoint only matches an explicit prefix.
+	PathPrefix string
+	// Times is the number of matching requests that fail. Zero or less fails every one.
+	Times int
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Header is added to the response.
+	Header http.Header
+	// Body is written as the JSON response body.
+	Body string
+}
+
+// RateLimitFault returns a 429 fault with the given Retry-After delay.
+// Delays under a second are sent as a Go duration string, which the SDK accepts.
+func RateLimitFault(pathPrefix string, times int, retryAfter time.Duration) Fault {
+	value := strconv.Itoa(int(retryAfter.Seconds()))
+	if retryAfter < time.Second {
+		value = retryAfter.String()
+	}
+	return Fault{
+		PathPrefix: pathPrefix,
+		Times:      times,
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{value}},
+		Body:       `{"message": "Rate Limit (1/SECOND) exceeded"}`,
+	}
+}
+
+// SessionInvalidFault returns a 401 SESSION_NOT_VALID fault.
+func SessionInvalidFault(pathPrefix string, times int) Fault {
+	return Fault{
+		PathPrefix: pathPrefix,
+		Times:      times,
+		StatusCode: http.StatusUnauthorized,
+		Body:       `{"code": "SESSION_NOT_VALID", "message": "Session is not valid"}`,
+	}
+}
+
+// EditLockFault returns a 409 EDIT_LOCK_NOT_AVAILABLE fault.
+func EditLockFault(pathPrefix string, times int) Fault {
+	return Fault{
+		PathPrefix: pathPrefix,
+		Times:      times,
+		StatusCode: http.StatusConflict,
+		Body:       `{"code": "EDIT_LOCK_NOT_AVAILABLE", "message": "Resource Access Blocked"}`,
+	}
+}
+
+type faultState struct {
+	Fault
+	remaining int
+}
+
+// InjectFault registers a fault. Faults are matched in registration order.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &faultState{Fault: f, remaining: f.Times})
+}
+
+// ClearFaults removes every registered fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns the first fault matching the request and consumes one use of it.
+// The caller must hold s.mu.
+func (s *Server) takeFault(method, path string) *faultState {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, method) {
+			continue
+		}
+		if f.PathPrefix == "" && path == tokenPath {
+			continue
+		}
+		if !strings.HasPrefix(path, f.PathPrefix) {
+			continue
+		}
+		if f.Times > 0 {
+			f.remaining--
+			if f.remaining <= 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (f *faultState) write(w http.ResponseWriter) {
+	for k, vals := range f.Header {
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.StatusCode)
+	_, _ = w.Write([]byte(f.Body))
+}
@@ -0,0 +1,272 @@
+// Package zscalertest provides an in-process fake of the Zscaler OneAPI for
+// tests. The fake issues OAuth2 tokens and keeps ZIA, ZPA and ZIdentity
+// resources in memory, so the SDK service functions can be exercised end to
+// end without network access or a real tenant. GET responses carry an ETag and
+// honor If-None-Match. ZIA and ZTW mutations stay pending until they are
+// activated through the emulated activation endpoints.
+//
+//	srv := zscalertest.NewServer()
+//	defer srv.Close()
+//	service, err := srv.NewService()
+//	label, _, err := rule_labels.Create(ctx, service, &rule_labels.RuleLabels{Name: "x"})
+package zscalertest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+)
+
+const (
+	// DefaultClientID is the client ID accepted by the token endpoint unless overridden.
+	DefaultClientID = "zscalertest-client"
+	// DefaultClientSecret is the client secret accepted by the token endpoint unless overridden.
+	DefaultClientSecret = "zscalertest-secret"
+	// CustomerID is the ZPA customer ID configured by ConfigSetters.
+	CustomerID = "1000000000000000"
+	// VanityDomain is the vanity domain configured by ConfigSetters.
+	VanityDomain = "zscalertest"
+
+	tokenPath = "/oauth2/v1/token"
+)
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCredentials sets the client ID and secret accepted by the token endpoint.
+func WithCredentials(clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.clientID = clientID
+		s.clientSecret = clientSecret
+	}
+}
+
+// WithTokenLifetime sets the expires_in value of issued access tokens.
+func WithTokenLifetime(d time.Duration) Option {
+	return func(s *Server) {
+		s.tokenLifetime = d
+	}
+}
+
+// Server is a fake OneAPI endpoint backed by an httptest.Server.
+type Server struct {
+	// URL is the base URL of the server, suitable for zscaler.WithBaseURL.
+	URL string
+
+	srv           *httptest.Server
+	clientID      string
+	clientSecret  string
+	tokenLifetime time.Duration
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	nextID      int64
+	tokens      map[string]bool
+	faults      []*faultState
+	requests    []Request
+
+	activations     map[string]*activation
+	activationDelay int
+	eusaPending     bool
+}
+
+// NewServer starts a fake OneAPI server. Callers should Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		clientID:      DefaultClientID,
+		clientSecret:  DefaultClientSecret,
+		tokenLifetime: time.Hour,
+		collections:   make(map[string]*collection),
+		nextID:        1000,
+		tokens:        make(map[string]bool),
+		activations:   make(map[string]*activation),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// ConfigSetters returns the settings that point an SDK configuration at the server.
+// Response caching is disabled so reads always observe the current state.
+func (s *Server) ConfigSetters() []zscaler.ConfigSetter {
+	return []zscaler.ConfigSetter{
+		zscaler.WithBaseURL(s.URL),
+		zscaler.WithClientID(s.clientID),
+		zscaler.WithClientSecret(s.clientSecret),
+		zscaler.WithVanityDomain(VanityDomain),
+		zscaler.WithZPACustomerID(CustomerID),
+		zscaler.WithCache(false),
+	}
+}
+
+// NewService returns an authenticated OneAPI service talking to the server.
+// Additional setters are applied after the server defaults.
+func (s *Server) NewService(setters ...zscaler.ConfigSetter) (*zscaler.Service, error) {
+	cfg, err := zscaler.NewConfiguration(append(s.ConfigSetters(), setters...)...)
+	if err != nil {
+		return nil, err
+	}
+	return zscaler.NewOneAPIClient(cfg)
+}
+
+// Requests returns the requests received so far, including token requests.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Request, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
+
+// ExpireTokens invalidates every issued access token. The next API request
+// fails with 401 SESSION_NOT_VALID until the client fetches a new token.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.Query(), Header: r.Header.Clone()})
+	fault := s.takeFault(r.Method, path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		fault.write(w)
+		return
+	}
+	if path == tokenPath {
+		s.handleToken(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+			"code":    "SESSION_NOT_VALID",
+			"message": "Session is not valid",
+		})
+		return
+	}
+	if s.handleActivation(w, r, path) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.handleConditional(w, r, path)
+		return
+	}
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.handleResource(rec, r, path)
+	s.mu.Lock()
+	s.trackMutation(r.Method, path, rec.status)
+	s.mu.Unlock()
+}
+
+// handleConditional serves a GET with an ETag over the response body and
+// answers 304 Not Modified when the client already holds that version.
+func (s *Server) handleConditional(w http.ResponseWriter, r *http.Request, path string) {
+	rec := httptest.NewRecorder()
+	s.handleResource(rec, r, path)
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	if rec.Code != http.StatusOK {
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+		return
+	}
+	sum := sha256.Sum256(rec.Body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(rec.Body.Bytes())
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "method_not_allowed"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "unsupported_grant_type"})
+		return
+	}
+	if r.PostForm.Get("client_id") != s.clientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+		return
+	}
+	// JWT client assertions are accepted as-is; only the secret is verified.
+	if r.PostForm.Get("client_assertion") == "" && r.PostForm.Get("client_secret") != s.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+		return
+	}
+
+	token := newToken()
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token_type":   "Bearer",
+		"access_token": token,
+		"expires_in":   int(s.tokenLifetime.Seconds()),
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[token]
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "zscalertest-" + hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
@@ -0,0 +1,484 @@
+package zscalertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// family groups the API conventions a path follows.
+type family int
+
+const (
+	familyZIA family = iota
+	familyZPA
+	familyZIdentity
+)
+
+const (
+	defaultZPAPageSize       = 20
+	defaultZIdentityPageSize = 100
+)
+
+// familyOf returns the API family of a path. Paths outside ZPA and ZIdentity
+// (ZIA, ZTW, ZCC, ZDX) follow the ZIA conventions.
+func familyOf(path string) family {
+	switch {
+	case strings.HasPrefix(path, "/zpa/"):
+		return familyZPA
+	case strings.HasPrefix(path, "/admin/api/v1/"), strings.HasPrefix(path, "/ziam/"):
+		return familyZIdentity
+	}
+	return familyZIA
+}
+
+// collection holds the resources stored under one collection path, in creation order.
+type collection struct {
+	ids   []string
+	items map[string]map[string]interface{}
+}
+
+func newCollection() *collection {
+	return &collection{items: make(map[string]map[string]interface{})}
+}
+
+func (c *collection) put(id string, obj map[string]interface{}) {
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = obj
+}
+
+func (c *collection) remove(id string) {
+	delete(c.items, id)
+	for i, v := range c.ids {
+		if v == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+func (c *collection) list() []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(c.ids))
+	for _, id := range c.ids {
+		out = append(out, c.items[id])
+	}
+	return out
+}
+
+// nameTaken reports whether another resource in the collection already uses name.
+func (c *collection) nameTaken(name, exceptID string) bool {
+	if name == "" {
+		return false
+	}
+	for id, obj := range c.items {
+		if id == exceptID {
+			continue
+		}
+		if other, _ := obj["name"].(string); strings.EqualFold(other, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Seed stores objects under collectionPath (for example "/zia/api/v1/ruleLabels")
+// as if they had been created through the API, and returns their IDs. Objects
+// are JSON encoded first; an existing "id" is kept, otherwise one is assigned.
+func (s *Server) Seed(collectionPath string, objects ...interface{}) ([]string, error) {
+	collectionPath = strings.TrimSuffix(collectionPath, "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coll := s.collection(collectionPath)
+	fam := familyOf(collectionPath)
+	ids := make([]string, 0, len(objects))
+	for _, o := range objects {
+		raw, err := json.Marshal(o)
+		if err != nil {
+			return ids, err
+		}
+		obj, err := decodeObject(raw)
+		if err != nil {
+			return ids, err
+		}
+		id := idString(obj["id"])
+		if id == "" || id == "0" {
+			id = s.assignID(fam, obj)
+		} else {
+			obj["id"] = formatID(fam, id)
+		}
+		coll.put(id, obj)
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Objects returns a copy of the resources stored under collectionPath, in creation order.
+func (s *Server) Objects(collectionPath string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	coll, ok := s.collections[strings.TrimSuffix(collectionPath, "/")]
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]interface{}, 0, len(coll.ids))
+	for _, obj := range coll.list() {
+		out = append(out, cloneObject(obj))
+	}
+	return out
+}
+
+// Reset removes every stored resource.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections = make(map[string]*collection)
+}
+
+// collection returns the collection stored at path, creating it if needed.
+// The caller must hold s.mu.
+func (s *Server) collection(path string) *collection {
+	coll, ok := s.collections[path]
+	if !ok {
+		coll = newCollection()
+		s.collections[path] = coll
+	}
+	return coll
+}
+
+// assignID allocates the next ID and stores it on obj. The caller must hold s.mu.
+func (s *Server) assignID(fam family, obj map[string]interface{}) string {
+	s.nextID++
+	id := strconv.FormatInt(s.nextID, 10)
+	obj["id"] = formatID(fam, id)
+	return id
+}
+
+// resolve splits a path into a collection path and an item ID. The last
+// segment is an item ID when it is numeric or when its parent is a known collection.
+// The caller must hold s.mu.
+func (s *Server) resolve(path string) (collPath, id string) {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return path, ""
+	}
+	parent, last := path[:i], path[i+1:]
+	if _, known := s.collections[parent]; known || isNumeric(last) {
+		return parent, last
+	}
+	return path, ""
+}
+
+func (s *Server) handleResource(w http.ResponseWriter, r *http.Request, path string) {
+	var body map[string]interface{}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch {
+		raw := new(bytes.Buffer)
+		if _, err := raw.ReadFrom(r.Body); err != nil {
+			writeError(w, familyOf(path), http.StatusBadRequest, "INVALID_INPUT_ARGUMENT", err.Error())
+			return
+		}
+		obj, err := decodeObject(raw.Bytes())
+		if err != nil {
+			writeError(w, familyOf(path), http.StatusBadRequest, "INVALID_INPUT_ARGUMENT", err.Error())
+			return
+		}
+		body = obj
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fam := familyOf(path)
+	collPath, id := s.resolve(path)
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		s.list(w, r, fam, collPath)
+	case id == "" && r.Method == http.MethodPost:
+		s.create(w, fam, collPath, body)
+	case id != "" && r.Method == http.MethodGet:
+		s.get(w, fam, collPath, id)
+	case id != "" && (r.Method == http.MethodPut || r.Method == http.MethodPatch):
+		s.update(w, fam, collPath, id, body, r.Method == http.MethodPatch)
+	case id != "" && r.Method == http.MethodDelete:
+		s.delete(w, fam, collPath, id)
+	default:
+		writeError(w, fam, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("%s is not supported on %s", r.Method, path))
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, fam family, collPath string, body map[string]interface{}) {
+	coll := s.collection(collPath)
+	name, _ := body["name"].(string)
+	if coll.nameTaken(name, "") {
+		writeError(w, fam, http.StatusConflict, "DUPLICATE_ITEM", fmt.Sprintf("a resource named %q already exists", name))
+		return
+	}
+	id := s.assignID(fam, body)
+	coll.put(id, body)
+
+	status := http.StatusCreated
+	if fam == familyZIA {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, body)
+}
+
+func (s *Server) get(w http.ResponseWriter, fam family, collPath, id string) {
+	obj, ok := s.lookup(collPath, id)
+	if !ok {
+		writeNotFound(w, fam, collPath, id)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func (s *Server) update(w http.ResponseWriter, fam family, collPath, id string, body map[string]interface{}, merge bool) {
+	existing, ok := s.lookup(collPath, id)
+	if !ok {
+		writeNotFound(w, fam, collPath, id)
+		return
+	}
+	obj := body
+	if merge {
+		obj = cloneObject(existing)
+		for k, v := range body {
+			obj[k] = v
+		}
+	}
+	obj["id"] = existing["id"]
+
+	coll := s.collections[collPath]
+	name, _ := obj["name"].(string)
+	if coll.nameTaken(name, id) {
+		writeError(w, fam, http.StatusConflict, "DUPLICATE_ITEM", fmt.Sprintf("a resource named %q already exists", name))
+		return
+	}
+	coll.put(id, obj)
+
+	if fam == familyZPA {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+func (s *Server) delete(w http.ResponseWriter, fam family, collPath, id string) {
+	if _, ok := s.lookup(collPath, id); !ok {
+		writeNotFound(w, fam, collPath, id)
+		return
+	}
+	s.collections[collPath].remove(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) lookup(collPath, id string) (map[string]interface{}, bool) {
+	coll, ok := s.collections[collPath]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := coll.items[id]
+	return obj, ok
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, fam family, collPath string) {
+	var items []map[string]interface{}
+	if coll, ok := s.collections[collPath]; ok {
+		items = coll.list()
+	}
+	q := r.URL.Query()
+
+	switch fam {
+	case familyZPA:
+		items = filterByName(items, zpaNameMatcher(q.Get("search")))
+		pageSize := queryInt(q, "pagesize", defaultZPAPageSize)
+		page := queryInt(q, "page", 1)
+		totalPages := (len(items) + pageSize - 1) / pageSize
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"totalPages": strconv.Itoa(totalPages),
+			"totalCount": strconv.Itoa(len(items)),
+			"list":       window(items, (page-1)*pageSize, pageSize),
+		})
+
+	case familyZIdentity:
+		items = filterByName(items, containsMatcher(q.Get("name[like]")))
+		limit := queryInt(q, "limit", defaultZIdentityPageSize)
+		offset := queryInt(q, "offset", 0)
+		resp := map[string]interface{}{
+			"results_total": len(items),
+			"pageOffset":    offset,
+			"pageSize":      limit,
+			"records":       window(items, offset, limit),
+		}
+		if offset+limit < len(items) {
+			next := *r.URL
+			nq := next.Query()
+			nq.Set("offset", strconv.Itoa(offset+limit))
+			next.RawQuery = nq.Encode()
+			resp["next_link"] = s.URL + next.RequestURI()
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	default:
+		items = filterByName(items, containsMatcher(q.Get("search")))
+		if q.Has("pageSize") || q.Has("page") {
+			pageSize := queryInt(q, "pageSize", len(items))
+			page := queryInt(q, "page", 1)
+			items = window(items, (page-1)*pageSize, pageSize)
+		}
+		if items == nil {
+			items = []map[string]interface{}{}
+		}
+		writeJSON(w, http.StatusOK, items)
+	}
+}
+
+// zpaSearch matches ZPA search filters such as "name+EQ+value".
+var zpaSearch = regexp.MustCompile(`^(?i)name[+ ](EQ|NE|CONTAINS|STARTSWITH|ENDSWITH)[+ ](.*)$`)
+
+func zpaNameMatcher(search string) func(string) bool {
+	m := zpaSearch.FindStringSubmatch(strings.TrimSpace(search))
+	if m == nil {
+		return containsMatcher(search)
+	}
+	op, value := strings.ToUpper(m[1]), strings.ToLower(m[2])
+	return func(name string) bool {
+		name = strings.ToLower(name)
+		switch op {
+		case "EQ":
+			return name == value
+		case "NE":
+			return name != value
+		case "STARTSWITH":
+			return strings.HasPrefix(name, value)
+		case "ENDSWITH":
+			return strings.HasSuffix(name, value)
+		}
+		return strings.Contains(name, value)
+	}
+}
+
+func containsMatcher(search string) func(string) bool {
+	if search == "" {
+		return nil
+	}
+	search = strings.ToLower(search)
+	return func(name string) bool {
+		return strings.Contains(strings.ToLower(name), search)
+	}
+}
+
+func filterByName(items []map[string]interface{}, match func(string) bool) []map[string]interface{} {
+	if match == nil {
+		return items
+	}
+	var out []map[string]interface{}
+	for _, obj := range items {
+		name, _ := obj["name"].(string)
+		if match(name) {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// window returns at most size items starting at offset, never nil.
+func window(items []map[string]interface{}, offset, size int) []map[string]interface{} {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) || size <= 0 {
+		return []map[string]interface{}{}
+	}
+	end := offset + size
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+func queryInt(q url.Values, key string, def int) int {
+	v, err := strconv.Atoi(q.Get(key))
+	if err != nil || (v <= 0 && key != "offset") {
+		return def
+	}
+	return v
+}
+
+func writeNotFound(w http.ResponseWriter, fam family, collPath, id string) {
+	writeError(w, fam, http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("resource %s/%s not found", collPath, id))
+}
+
+// writeError writes an error body shaped like the one returned by the API family.
+func writeError(w http.ResponseWriter, fam family, status int, code, message string) {
+	if fam == familyZPA {
+		writeJSON(w, status, map[string]interface{}{
+			"id":     strings.ToLower(strings.ReplaceAll(code, "_", ".")),
+			"reason": message,
+		})
+		return
+	}
+	writeJSON(w, status, map[string]interface{}{"code": code, "message": message})
+}
+
+func decodeObject(raw []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, errors.New("request body must be a JSON object")
+	}
+	if obj == nil {
+		obj = map[string]interface{}{}
+	}
+	return obj, nil
+}
+
+func cloneObject(obj map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	return out
+}
+
+// formatID renders an ID the way the API family does: numbers for ZIA, strings otherwise.
+func formatID(fam family, id string) interface{} {
+	if fam == familyZIA && isNumeric(id) {
+		return json.Number(id)
+	}
+	return id
+}
+
+func idString(v interface{}) string {
+	switch id := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
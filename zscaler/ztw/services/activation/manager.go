@@ -0,0 +1,81 @@
+package activation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
+)
+
+// ZTW admin activation statuses.
+const (
+	StatusActivateQueued = "ADM_ACTV_QUEUED"
+	StatusActivating     = "ADM_ACTIVATING"
+	StatusActivateDone   = "ADM_ACTV_DONE"
+	StatusActivateFail   = "ADM_ACTV_FAIL"
+	StatusEditsPresent   = "EDITS_PRESENT"
+)
+
+// Backend is the zscaler.ActivationBackend of ZTW.
+type Backend struct {
+	Service *zscaler.Service
+	// Force uses the forced activation, which also activates the changes of
+	// other admins.
+	Force bool
+}
+
+// NewManager returns an activation manager for the ZTW changes made through
+// service.
+func NewManager(service *zscaler.Service, opts ...zscaler.ActivationOption) *zscaler.ActivationManager {
+	return zscaler.NewActivationManager(service, &Backend{Service: service}, opts...)
+}
+
+func (b *Backend) Product() string {
+	return "ztw"
+}
+
+// Tracks reports whether endpoint changes the ZTW configuration. Activations
+// do not.
+func (b *Backend) Tracks(endpoint string) bool {
+	path, _, _ := strings.Cut(endpoint, "?")
+	return !strings.HasPrefix(path, ecAdminActivateStatusEndpoint)
+}
+
+// Preflight does nothing; ZTW has no EUSA.
+func (b *Backend) Preflight(ctx context.Context) error {
+	return nil
+}
+
+func (b *Backend) Status(ctx context.Context) (zscaler.ActivationStatus, error) {
+	activation, err := GetActivationStatus(zscaler.WithoutCache(ctx), b.Service)
+	if err != nil {
+		return zscaler.ActivationStatus{}, err
+	}
+	return activationStatus(activation), nil
+}
+
+func (b *Backend) Activate(ctx context.Context) (zscaler.ActivationStatus, error) {
+	activate := UpdateActivationStatus
+	if b.Force {
+		activate = ForceActivationStatus
+	}
+	activation, err := activate(ctx, b.Service, ECAdminActivation{})
+	if err != nil {
+		return zscaler.ActivationStatus{}, err
+	}
+	return activationStatus(activation), nil
+}
+
+// activationStatus maps the admin activation status. Edits left on the
+// organization after an activation belong to other admins.
+func activationStatus(activation *ECAdminActivation) zscaler.ActivationStatus {
+	status := zscaler.ActivationStatus{Status: activation.AdminActivateStatus}
+	switch strings.ToUpper(activation.AdminActivateStatus) {
+	case StatusActivateQueued, StatusActivating:
+		status.InProgress = true
+	case StatusActivateFail:
+		status.Failed = true
+	}
+	status.OthersPending = strings.EqualFold(activation.OrgEditStatus, StatusEditsPresent)
+	return status
+}
@@ -3,9 +3,11 @@ package common
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strings"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler"
 )
 
@@ -186,24 +188,41 @@ func ReadAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint s
 	if list == nil {
 		return nil
 	}
+	return readPages(ctx, client, endpoint, func(items []T) bool {
+		*list = append(*list, items...)
+		return true
+	})
+}
+
+// IterAllPages is the lazy form of ReadAllPages.
+func IterAllPages[T any](ctx context.Context, client *zscaler.Client, endpoint string) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPages(ctx, client, endpoint, yield)
+	})
+}
+
+// readPages fetches pages in order and passes each to yield until the last
+// page or until yield returns false.
+func readPages[T any](ctx context.Context, client *zscaler.Client, endpoint string, yield func([]T) bool) error {
 	page := 1
 	if !strings.Contains(endpoint, "?") {
 		endpoint += "?"
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		pageItems := []T{}
 		err := client.ReadResource(ctx, fmt.Sprintf("%s&pageSize=%d&page=%d", endpoint, pageSize, page), &pageItems)
 		if err != nil {
 			return err
 		}
-		*list = append(*list, pageItems...)
-		if len(pageItems) < pageSize {
-			break
+		if !yield(pageItems) || len(pageItems) < pageSize {
+			return nil
 		}
 		page++
 	}
-	return nil
 }
 
 func ReadPage[T any](ctx context.Context, client *zscaler.Client, endpoint string, page int, list *[]T) error {
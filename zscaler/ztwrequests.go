@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"reflect"
 	"strings"
+
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/errorx"
 )
 
 // Create sends a POST request to create an object.
@@ -22,11 +23,11 @@ func (c *Client) CreateResource(ctx context.Context, endpoint string, o interfac
 	}
 
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to create with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+t.Kind().String()+" not a Struct")
 	}
 	data, err := json.Marshal(o)
 	if err != nil {
@@ -130,11 +131,11 @@ func (c *Client) UpdateResource(ctx context.Context, endpoint string, o interfac
 // General method to update an object using the specified HTTP method.
 func (c *Client) updateGenericResource(ctx context.Context, endpoint string, o interface{}, method, contentType string) (interface{}, error) {
 	if o == nil {
-		return nil, errors.New("tried to update with a nil payload not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Struct")
 	}
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, errors.New("tried to update with a " + t.Kind().String() + " not a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+t.Kind().String()+" not a Struct")
 	}
 	data, err := json.Marshal(o)
 	if err != nil {
@@ -186,7 +187,7 @@ func (c *Client) BulkDeleteResource(ctx context.Context, endpoint string, payloa
 	}
 
 	if payload == nil {
-		return nil, errors.New("tried to delete with a nil payload, expected a struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to delete with a nil payload, expected a struct")
 	}
 
 	data, err := json.Marshal(payload)
@@ -216,12 +217,12 @@ func (c *Client) CreateWithSlicePayloadResource(ctx context.Context, endpoint st
 	}
 
 	if slice == nil {
-		return nil, errors.New("tried to create with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to create with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -252,12 +253,12 @@ func (c *Client) UpdateWithSlicePayloadResource(ctx context.Context, endpoint st
 	}
 
 	if slice == nil {
-		return nil, errors.New("tried to update with a nil payload not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a nil payload not a Slice")
 	}
 
 	v := reflect.ValueOf(slice)
 	if v.Kind() != reflect.Slice {
-		return nil, errors.New("tried to update with a " + v.Kind().String() + " not a Slice")
+		return nil, errorx.New(errorx.ErrValidation, "tried to update with a "+v.Kind().String()+" not a Slice")
 	}
 
 	data, err := json.Marshal(slice)
@@ -284,7 +285,7 @@ func (c *Client) CreateWithRawPayloadResource(ctx context.Context, endpoint stri
 	}
 
 	if payload == "" {
-		return nil, errors.New("tried to create with an empty string payload")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with an empty string payload")
 	}
 
 	// Convert the string payload to []byte
@@ -329,12 +330,12 @@ func (c *Client) CreateWithNoContentResource(ctx context.Context, endpoint strin
 
 	// Validate the payload
 	if o == nil {
-		return nil, errors.New("tried to create with a nil payload, expected a Struct")
+		return nil, errorx.New(errorx.ErrValidation, "tried to create with a nil payload, expected a Struct")
 	}
 
 	t := reflect.TypeOf(o)
 	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("tried to create with a %s, expected a Struct", t.Kind().String())
+		return nil, errorx.Errorf(errorx.ErrValidation, "tried to create with a %s, expected a Struct", t.Kind().String())
 	}
 
 	// Marshal the payload
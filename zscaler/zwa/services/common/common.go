@@ -3,9 +3,11 @@ package common
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 
+	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/internal/pageiter"
 	"github.com/SecurityGeekIO/zscaler-sdk-go/v3/zscaler/zwa"
 )
 
@@ -188,9 +190,32 @@ func GetPageSize() int {
 
 func ReadAllPages[T any](ctx context.Context, client *zwa.Client, method, endpoint string, params *PaginationParams, requestBody interface{}) ([]T, *Cursor, error) {
 	var allResults []T
+	var cursor Cursor
+	err := readPages(ctx, client, method, endpoint, params, requestBody, func(items []T, pageCursor Cursor) bool {
+		allResults = append(allResults, items...)
+		cursor = pageCursor
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return allResults, &cursor, nil
+}
+
+// IterAllPages is the lazy form of ReadAllPages.
+func IterAllPages[T any](ctx context.Context, client *zwa.Client, method, endpoint string, params *PaginationParams, requestBody interface{}) iter.Seq2[T, error] {
+	return pageiter.Items(func(yield func([]T) bool) error {
+		return readPages(ctx, client, method, endpoint, params, requestBody, func(items []T, _ Cursor) bool {
+			return yield(items)
+		})
+	})
+}
+
+// readPages fetches pages in order and passes each, with its cursor, to yield
+// until the last page or until yield returns false.
+func readPages[T any](ctx context.Context, client *zwa.Client, method, endpoint string, params *PaginationParams, requestBody interface{}, yield func([]T, Cursor) bool) error {
 	page := 1
 	pageSize := 1000 // Default page size
-	var cursor Cursor
 
 	// Override default params if provided
 	if params != nil {
@@ -203,6 +228,10 @@ func ReadAllPages[T any](ctx context.Context, client *zwa.Client, method, endpoi
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Add pagination parameters dynamically
 		queryParams := url.Values{}
 		queryParams.Set("page", fmt.Sprintf("%d", page))
@@ -214,7 +243,7 @@ func ReadAllPages[T any](ctx context.Context, client *zwa.Client, method, endpoi
 		// Parse the endpoint into a URL and append query parameters
 		baseURL, err := url.Parse(endpoint)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid endpoint URL: %w", err)
+			return fmt.Errorf("invalid endpoint URL: %w", err)
 		}
 		baseURL.RawQuery = queryParams.Encode()
 
@@ -231,26 +260,25 @@ func ReadAllPages[T any](ctx context.Context, client *zwa.Client, method, endpoi
 		} else if method == http.MethodPost {
 			resp, err = client.NewRequestDo(ctx, method, baseURL.String(), nil, requestBody, &pageResults)
 		} else {
-			return nil, nil, fmt.Errorf("unsupported HTTP method: %s", method)
+			return fmt.Errorf("unsupported HTTP method: %s", method)
 		}
 
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch page %d: %w", page, err)
+			return fmt.Errorf("failed to fetch page %d: %w", page, err)
 		}
-		defer resp.Body.Close()
+		resp.Body.Close()
 
-		// Append items and update the cursor
-		allResults = append(allResults, pageResults.Items...)
-		cursor = pageResults.Cursor
+		cursor := pageResults.Cursor
+		if !yield(pageResults.Items, cursor) {
+			return nil
+		}
 
 		// Break if no more pages
 		if cursor.CurrentPageSize < pageSize || page >= cursor.TotalPages-1 {
-			break
+			return nil
 		}
 		page++
 	}
-
-	return allResults, &cursor, nil
 }
 
 func ReadPage[T any](ctx context.Context, client *zwa.Client, endpoint string, params PaginationParams) ([]T, *Cursor, error) {
@@ -270,7 +270,7 @@ func Authenticate(ctx context.Context, cfg *Configuration, logger logger.Logger)
 	// Check for valid status codes (200 or 201)
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		logger.Printf("[ERROR] Authentication failed: HTTP %d, response: %s", resp.StatusCode, string(respBody))
-		return nil, fmt.Errorf("authentication failed: HTTP %d, response: %s", resp.StatusCode, string(respBody))
+		return nil, errorx.Errorf(errorx.AuthFailureKind(resp), "authentication failed: HTTP %d, response: %s", resp.StatusCode, string(respBody))
 	}
 
 	// Parse the response